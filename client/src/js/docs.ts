@@ -0,0 +1,11 @@
+import "redoc/bundles/redoc.standalone.js";
+
+declare global {
+  interface Window {
+    Redoc: {
+      init: (specOrSpecUrl: string, options: object, element: HTMLElement | null) => void;
+    };
+  }
+}
+
+window.Redoc.init("/api/v1/openapi.json", {}, document.getElementById("redoc"));
@@ -3,6 +3,9 @@ package boot
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -13,8 +16,43 @@ type Config struct {
 	GoEnv          string
 	DSN            string
 	RapidApiSecret string
+	// DisabledSources lists the scraping providers to skip, comma separated (e.g. "marketbeat,dividendhistory")
+	DisabledSources []string
+	// ScenarioTTL is how long a shared calculation scenario is kept, 0 to keep it forever (SCENARIO_TTL_DAYS, 90 by default)
+	ScenarioTTL time.Duration
+	// ScrapeMaxAttempts is how many times a seed is scraped before it is moved to the dead letters (SCRAPE_MAX_ATTEMPTS, 5 by default)
+	ScrapeMaxAttempts int
+	// RefreshMaxAge is how old a stored security may get before a refresh run has to pick it (REFRESH_MAX_AGE_DAYS, 7 by default)
+	RefreshMaxAge time.Duration
+	// RefreshPageBudget is how many pages a refresh run may load (REFRESH_PAGE_BUDGET, 1500 by default)
+	RefreshPageBudget int
+	// RefreshTimeBudget is how long a refresh run may last (REFRESH_TIME_BUDGET_MINUTES, 120 by default)
+	RefreshTimeBudget time.Duration
+	// ScrapeRequestsPerMinute is how many pages of a single host are loaded per minute (SCRAPE_REQUESTS_PER_MINUTE, 30 by default)
+	ScrapeRequestsPerMinute float64
+	// ScrapeHostRequestsPerMinute overrides it by host, comma separated (e.g. "finance.yahoo.com=20,marketbeat.com=10")
+	ScrapeHostRequestsPerMinute map[string]float64
+	// ScrapeJitter is the random delay added before every page, up to this long (SCRAPE_JITTER_MS, 2000 by default)
+	ScrapeJitter time.Duration
+	// ScrapeCoolDown is how long a host that blocked the scraper is paused (SCRAPE_COOLDOWN_MINUTES, 10 by default)
+	ScrapeCoolDown time.Duration
+	// SelectorsFile is the YAML or JSON selector file the scraper loads and reloads when it changes
+	// (SELECTORS_FILE, selectors.yaml by default), the built-in selectors being used when there is none
+	SelectorsFile string
 }
 
+const (
+	defaultScenarioTTLDays          = 90
+	defaultScrapeMaxAttempts        = 5
+	defaultRefreshMaxAgeDays        = 7
+	defaultRefreshPageBudget        = 1500
+	defaultRefreshTimeBudgetMinutes = 120
+	defaultScrapeRequestsPerMinute  = 30
+	defaultScrapeJitterMs           = 2000
+	defaultScrapeCoolDownMinutes    = 10
+	defaultSelectorsFile            = "selectors.yaml"
+)
+
 var Environment *Config
 
 func LoadEnvVariables() error {
@@ -24,16 +62,83 @@ func LoadEnvVariables() error {
 	}
 
 	Environment = &Config{
-		Port:           os.Getenv("PORT"),
-		Host:           os.Getenv("HOST"),
-		GoEnv:          os.Getenv("GO_ENV"),
-		DSN:            os.Getenv("DSN"),
-		RapidApiSecret: os.Getenv("RAPID_API_SECRET"),
+		Port:                        os.Getenv("PORT"),
+		Host:                        os.Getenv("HOST"),
+		GoEnv:                       os.Getenv("GO_ENV"),
+		DSN:                         os.Getenv("DSN"),
+		RapidApiSecret:              os.Getenv("RAPID_API_SECRET"),
+		DisabledSources:             splitList(os.Getenv("DISABLED_SOURCES")),
+		ScenarioTTL:                 days(os.Getenv("SCENARIO_TTL_DAYS"), defaultScenarioTTLDays),
+		ScrapeMaxAttempts:           positive(os.Getenv("SCRAPE_MAX_ATTEMPTS"), defaultScrapeMaxAttempts),
+		RefreshMaxAge:               days(os.Getenv("REFRESH_MAX_AGE_DAYS"), defaultRefreshMaxAgeDays),
+		RefreshPageBudget:           positive(os.Getenv("REFRESH_PAGE_BUDGET"), defaultRefreshPageBudget),
+		RefreshTimeBudget:           time.Duration(positive(os.Getenv("REFRESH_TIME_BUDGET_MINUTES"), defaultRefreshTimeBudgetMinutes)) * time.Minute,
+		ScrapeRequestsPerMinute:     float64(positive(os.Getenv("SCRAPE_REQUESTS_PER_MINUTE"), defaultScrapeRequestsPerMinute)),
+		ScrapeHostRequestsPerMinute: rates(os.Getenv("SCRAPE_HOST_REQUESTS_PER_MINUTE")),
+		ScrapeJitter:                time.Duration(positive(os.Getenv("SCRAPE_JITTER_MS"), defaultScrapeJitterMs)) * time.Millisecond,
+		ScrapeCoolDown:              time.Duration(positive(os.Getenv("SCRAPE_COOLDOWN_MINUTES"), defaultScrapeCoolDownMinutes)) * time.Minute,
+		SelectorsFile:               fallback(os.Getenv("SELECTORS_FILE"), defaultSelectorsFile),
 	}
 
 	return err
 }
 
+// days parses a number of days, the fallback being used when the value is empty or invalid
+func days(value string, fallback int) time.Duration {
+	parsed, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || parsed < 0 {
+		parsed = fallback
+	}
+	return time.Duration(parsed) * 24 * time.Hour
+}
+
+// positive parses a number greater than 0, the fallback being used when the value is empty or invalid
+func positive(value string, fallback int) int {
+	parsed, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || parsed <= 0 {
+		return fallback
+	}
+	return parsed
+}
+
+// rates parses a comma separated list of name=rate pairs, skipping the invalid ones
+func rates(value string) map[string]float64 {
+	parsed := make(map[string]float64)
+	for _, item := range splitList(value) {
+		name, rate, found := strings.Cut(item, "=")
+		if !found {
+			continue
+		}
+
+		perMinute, err := strconv.ParseFloat(strings.TrimSpace(rate), 64)
+		if err != nil || perMinute <= 0 {
+			continue
+		}
+		parsed[strings.ToLower(strings.TrimSpace(name))] = perMinute
+	}
+	return parsed
+}
+
+// fallback returns the trimmed value, or the fallback when it is empty
+func fallback(value string, fallback string) string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+func splitList(value string) []string {
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
 type PlanLimits struct {
 	AllowedParams []string
 	MaxParams     int
@@ -2,27 +2,71 @@ package boot
 
 import (
 	"fmt"
+	"slices"
 
+	"github.com/Francesco99975/finexo/internal/database"
 	"github.com/Francesco99975/finexo/internal/models"
 	"github.com/Francesco99975/finexo/internal/tools"
 	"github.com/labstack/gommon/log"
 )
 
 func SetupCronJobs(exchanges []models.Exchange) {
+	suffixes := []string{}
 	for _, exchange := range exchanges {
 		suffix := "."
 		if exchange.Suffix.Valid {
 			suffix = exchange.Suffix.String
 		}
+		suffixes = append(suffixes, suffix)
 		err := tools.AddJob(suffix, fmt.Sprintf("%d %d * * *", exchange.CloseTime.Time.Minute(), exchange.CloseTime.Time.Hour()), func() {
 
-			err := SeedDatabase(500, suffix)
+			err := RefreshDatabase(suffix)
 			if err != nil {
-				log.Errorf("Error while seeding database: %v", err)
+				log.Errorf("Error while refreshing database: %v", err)
 			}
 		})
 		if err != nil {
 			log.Errorf("Error while creating job: %v", err)
 		}
 	}
+
+	// Views of the securities are written in bulk, for the refresh planner to rank them on popularity
+	err := tools.AddJob("hits", "*/5 * * * *", func() {
+		err := models.FlushSecurityHits(database.DB)
+		if err != nil {
+			log.Errorf("Error while flushing security hits: %v", err)
+		}
+	})
+	if err != nil {
+		log.Errorf("Error while creating job: %v", err)
+	}
+
+	// The age of the stored securities is published between refresh runs too
+	err = tools.AddJob("data-age", "0 * * * *", func() {
+		for _, suffix := range slices.Compact(slices.Sorted(slices.Values(suffixes))) {
+			RecordDataAge(suffix)
+		}
+	})
+	if err != nil {
+		log.Errorf("Error while creating job: %v", err)
+	}
+
+	// A selector file edited while running is picked up within a minute
+	err = tools.AddJob("selectors", "* * * * *", tools.ReloadSelectors)
+	if err != nil {
+		log.Errorf("Error while creating job: %v", err)
+	}
+
+	// Shared scenarios past their expiry are purged daily
+	err = tools.AddJob("scenarios", "0 4 * * *", func() {
+		deleted, err := models.DeleteExpiredScenarios(database.DB)
+		if err != nil {
+			log.Errorf("Error while deleting expired scenarios: %v", err)
+			return
+		}
+		log.Infof("Deleted %d expired scenarios", deleted)
+	})
+	if err != nil {
+		log.Errorf("Error while creating job: %v", err)
+	}
 }
@@ -0,0 +1,65 @@
+package boot
+
+import (
+	"time"
+
+	"github.com/Francesco99975/finexo/internal/database"
+	"github.com/Francesco99975/finexo/internal/helpers"
+	"github.com/Francesco99975/finexo/internal/models"
+	"github.com/Francesco99975/finexo/internal/tools"
+	"github.com/labstack/gommon/log"
+)
+
+// Refresh runs of a suffix are scheduled daily, at the close of its exchange
+const refreshInterval = 24 * time.Hour
+
+// RefreshDatabase refreshes the seeds of the suffix picked by the refresh planner, within the page and time
+// budgets of a run, then publishes how old the stored securities are
+func RefreshDatabase(suffix string) error {
+	budget := scrapeBudget{Time: Environment.RefreshTimeBudget, Pages: Environment.RefreshPageBudget}
+
+	err := scrapeSeeds(suffix, func() ([]string, error) {
+		return planRefresh(suffix, budget.Pages)
+	}, budget)
+
+	RecordDataAge(suffix)
+
+	return err
+}
+
+// planRefresh picks the seeds of a refresh run, as many as the page budget should fit
+func planRefresh(suffix string, pages int) ([]string, error) {
+	candidates, err := models.GetRefreshCandidates(database.DB, suffix)
+	if err != nil {
+		return nil, err
+	}
+
+	// Seeds not stored yet are only discovered when the seed files can be read
+	seeds, err := selectSeeds(0, suffix)
+	if err != nil {
+		log.Warnf("Could not read seeds to discover: %v", err)
+	}
+
+	// Every seed loads a page per source, holdings and related securities coming on top
+	size := pages / max(len(tools.EnabledSources()), 1)
+
+	plan := tools.PlanRefresh(candidates, seeds, size, Environment.RefreshMaxAge, refreshInterval)
+	if plan.Due > plan.Known {
+		log.Warnf("%d securities are due for a refresh but the budget only fits %d, raise REFRESH_PAGE_BUDGET", plan.Due, plan.Known)
+	}
+
+	log.Infof("Planned refresh of %d seeds: %d stored (%d due), %d new", len(plan.Seeds), plan.Known, min(plan.Due, plan.Known), plan.Discovery)
+
+	return plan.Seeds, nil
+}
+
+// RecordDataAge publishes the age of the oldest stored security of the suffix and how many are past the maximum age
+func RecordDataAge(suffix string) {
+	age, err := models.GetDataAge(database.DB, suffix, Environment.RefreshMaxAge)
+	if err != nil {
+		log.Errorf("Could not get data age: %v", err)
+		return
+	}
+
+	helpers.RecordDataAge(suffix, age.Oldest, age.Stale)
+}
@@ -1,56 +1,106 @@
 package boot
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/Francesco99975/finexo/internal/database"
 	"github.com/Francesco99975/finexo/internal/helpers"
 	"github.com/Francesco99975/finexo/internal/models"
 	"github.com/Francesco99975/finexo/internal/tools"
 	"github.com/labstack/gommon/log"
-	"golang.org/x/sync/semaphore"
 )
 
 const maxWorkers = 7
 
+// A seed is leased to a worker for this long, it is taken over by another worker once the lease expires (crashed process)
+const scrapeJobLease = 15 * time.Minute
+
+// Longest wait of an idle worker before it looks for a seed due again
+const queuePollInterval = 10 * time.Second
+
+// Suffixes this process is seeding, a run already going on not being started twice
+var seeding sync.Map
+
+// scrapeBudget bounds a run, the seeds not leased yet once it is spent being skipped. Zero values do not bound it.
+type scrapeBudget struct {
+	Time  time.Duration
+	Pages int
+}
+
+// seeder holds what the workers of a run share
+type seeder struct {
+	run        *models.ScrapeRun
+	manager    *models.BrowserManager
+	discoverer *tools.Discoverer
+	reporter   *helpers.Reporter
+	budget     scrapeBudget
+	started    time.Time // Start of the work of this process on the run, which the time budget counts from
+	progress   atomic.Uint32
+	spent      atomic.Bool
+	heldUntil  atomic.Int64 // Unix nanoseconds until which no seed is leased, a host being paused
+}
+
+// SeedDatabase scrapes a random load of seeds for the suffix
 func SeedDatabase(load int, suffix string) error {
-	seeds, err := tools.ReadAllSeeds()
+	return scrapeSeeds(suffix, func() ([]string, error) {
+		return selectSeeds(load, suffix)
+	}, scrapeBudget{})
+}
+
+// scrapeSeeds scrapes the seeds planned for the suffix through the scrape_jobs queue, within the budget.
+// A run interrupted before all its seeds were scraped is resumed instead of planning new seeds.
+func scrapeSeeds(suffix string, plan func() ([]string, error), budget scrapeBudget) error {
+	if _, running := seeding.LoadOrStore(suffix, true); running {
+		log.Warnf("Seeding of suffix (%s) is already running", suffix)
+		return nil
+	}
+	defer seeding.Delete(suffix)
+
+	started, err := models.GetUnfinishedScrapeRun(database.DB, suffix)
 	if err != nil {
 		return err
 	}
 
-	helpers.Shuffle(seeds)
+	run := models.NewScrapeRun(suffix, 0)
+	if started != nil {
+		run.Started = *started
+		log.Infof("Resuming scrape run started at %s", run.Started.Format(time.RFC3339))
+	} else {
+		seeds, err := plan()
+		if err != nil {
+			return err
+		}
 
-	if len(suffix) > 0 {
-		if suffix == "." {
-			seeds = helpers.FilteredSlice(seeds, func(s string) bool {
-				return !strings.Contains(s, ".")
-			})
-		} else {
-			seeds = helpers.FilteredSlice(seeds, func(s string) bool {
-				return strings.Contains(s, "."+suffix)
-			})
+		err = models.EnqueueScrapeJobs(database.DB, suffix, seeds, run.Started)
+		if err != nil {
+			return err
 		}
 	}
 
-	if load > 0 && load < len(seeds) {
-		seeds = seeds[:load]
+	counts, err := models.CountScrapeJobs(database.DB, suffix)
+	if err != nil {
+		return err
 	}
+	run.Seeds = counts.Total()
 
-	reportFilename := fmt.Sprintf("ScrapingReport-%d.log", time.Now().Unix())
+	reportFilename := fmt.Sprintf("ScrapingReport-%d.log", run.Started.Unix())
 	ScraperReporter, err := helpers.NewReporter(reportFilename)
 	if err != nil {
 		log.Errorf("failed to create reporter: %v", err)
+	} else {
+		defer func() {
+			err := ScraperReporter.Close()
+			if err != nil {
+				log.Errorf("failed to close reporter: %v", err)
+			}
+		}()
 	}
-	defer func() {
-		err := ScraperReporter.Close()
-		if err != nil {
-			log.Errorf("failed to close reporter: %v", err)
-		}
-	}()
 
 	var d *tools.Discoverer
 	if Environment.GoEnv == "development" {
@@ -71,56 +121,287 @@ func SeedDatabase(load int, suffix string) error {
 	go manager.MonitorMemory()
 	go manager.MonitorBrowserHealth()
 
-	var wg sync.WaitGroup
-	sem := semaphore.NewWeighted(maxWorkers) // Control concurrency
-	var progress atomic.Uint32
-	var failed atomic.Uint32
+	s := &seeder{run: run, manager: manager, discoverer: d, reporter: ScraperReporter, budget: budget, started: time.Now()}
+	s.progress.Store(uint32(counts.Done + counts.Dead))
 
-	for _, seed := range seeds {
+	var wg sync.WaitGroup
+	for range maxWorkers {
 		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.work()
+		}()
+	}
+
+	wg.Wait()
+
+	if s.spent.Load() {
+		skipped, err := models.SkipScrapeJobs(database.DB, suffix)
+		if err != nil {
+			return err
+		}
+		log.Warnf("Scrape run went past its budget, skipped %d seeds", skipped)
+	}
 
-		go func(seed string) {
+	counts, err = models.CountScrapeJobs(database.DB, suffix)
+	if err != nil {
+		return err
+	}
+	if counts.Pending+counts.Running > 0 {
+		// The queue could not be reached, the run is resumed the next time
+		return fmt.Errorf("scrape run stopped with %d seeds left", counts.Pending+counts.Running)
+	}
+
+	if counts.Dead > 0 {
+		log.Errorf("Failed to scrape %d seeds (%.2f%%)", counts.Dead, (float64(counts.Dead)/float64(counts.Total()))*100)
+	}
 
-			defer func() {
-				if r := recover(); r != nil {
-					helpers.RecordBusinessEvent("scrape_panic_occurred")
-					failed.Add(1)
-					log.Errorf("Panic occurred while scraping seed (%s): %v", seed, r)
-					err := ScraperReporter.Report(helpers.SeverityLevels.PANIC, fmt.Sprintf("was scraping seed (%s) -> %v", seed, r))
-					if err != nil {
-						log.Errorf("failed to report panic: %v", err)
-					}
-				}
+	log.Infof("All seeds have been scraped. Successfully scraped %d seeds", counts.Done)
 
-				currentProgress := progress.Add(1)
-				// Log Progress
-				log.Infof("<<< Processed seed %d of %d: [%s] >>>", currentProgress, len(seeds), seed)
+	run.Failed = counts.Dead
+	run.Succeeded = counts.Done
 
-			}()
+	summarizeScrapeRun(run, ScraperReporter)
 
-			err := tools.Scrape(seed, nil, manager, sem, &wg, d)
+	analyzeDividends()
+
+	return nil
+}
+
+// ResumeSeeding resumes the scrape runs interrupted by a restart, one after the other
+func ResumeSeeding() error {
+	suffixes, err := models.GetUnfinishedScrapeSuffixes(database.DB)
+	if err != nil {
+		return err
+	}
+
+	for _, suffix := range suffixes {
+		err := SeedDatabase(0, suffix)
+		if err != nil {
+			log.Errorf("Error while resuming seeding of suffix (%s): %v", suffix, err)
+		}
+	}
+
+	return nil
+}
+
+// selectSeeds picks a random load of the seeds matching the suffix, "." matching the seeds without one
+func selectSeeds(load int, suffix string) ([]string, error) {
+	seeds, err := tools.ReadAllSeeds()
+	if err != nil {
+		return nil, err
+	}
+
+	helpers.Shuffle(seeds)
+
+	if len(suffix) > 0 {
+		if suffix == "." {
+			seeds = helpers.FilteredSlice(seeds, func(s string) bool {
+				return !strings.Contains(s, ".")
+			})
+		} else {
+			seeds = helpers.FilteredSlice(seeds, func(s string) bool {
+				return strings.Contains(s, "."+suffix)
+			})
+		}
+	}
+
+	if load > 0 && load < len(seeds) {
+		seeds = seeds[:load]
+	}
+
+	return seeds, nil
+}
+
+// work leases the seeds of the run one at a time until none is left to scrape
+func (s *seeder) work() {
+	for {
+		if s.overBudget() {
+			return
+		}
+
+		// No seed is leased while a host the seeds need is paused, they would all fail at once
+		if wait := s.held(); wait > 0 {
+			time.Sleep(min(wait, queuePollInterval))
+			continue
+		}
+
+		job, err := models.LeaseScrapeJob(database.DB, s.run.Suffix, scrapeJobLease)
+		if err != nil {
+			log.Errorf("Could not lease scrape job: %v", err)
+			return
+		}
+
+		if job == nil {
+			// The seeds left are waiting on a backoff or leased by other workers
+			wait, err := models.NextScrapeJobIn(database.DB, s.run.Suffix)
 			if err != nil {
-				helpers.RecordBusinessEvent("scrape_failed")
-				failed.Add(1)
-				log.Errorf("Could not Scrape <- %v", err)
-				err := ScraperReporter.Report(helpers.SeverityLevels.ERROR, fmt.Sprintf("was scraping seed (%s) -> %v", seed, err))
-				if err != nil {
-					log.Errorf("failed to report error: %v", err)
-				}
+				log.Errorf("Could not get next scrape job: %v", err)
+				return
 			}
-			helpers.RecordBusinessEvent("scrape_successful")
+			if wait == nil {
+				return
+			}
+
+			time.Sleep(min(max(*wait, time.Second), queuePollInterval))
+			continue
+		}
 
-		}(seed)
+		s.scrape(job)
 	}
+}
 
-	wg.Wait()
+// scrape scrapes the seed of a leased job, scheduling it again after a failure
+func (s *seeder) scrape(job *models.ScrapeJob) {
+	err := func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				helpers.RecordBusinessEvent("scrape_panic_occurred")
+				log.Errorf("Panic occurred while scraping seed (%s): %v", job.Seed, r)
+				s.report(helpers.SeverityLevels.PANIC, fmt.Sprintf("was scraping seed (%s) -> %v", job.Seed, r))
+				err = fmt.Errorf("panic: %v", r)
+			}
+		}()
+
+		return tools.Scrape(job.Seed, nil, s.manager, s.discoverer, s.run)
+	}()
 
-	failedProgress := failed.Load()
-	if failedProgress > 0 {
-		log.Errorf("Failed to scrape %d seeds (%.2f%%)", failedProgress, (float64(failedProgress)/float64(len(seeds)))*100)
+	if err == nil {
+		err := models.CompleteScrapeJob(database.DB, job)
+		if err != nil {
+			log.Errorf("Could not complete scrape job: %v", err)
+		}
+		helpers.RecordBusinessEvent("scrape_successful")
+		s.processed(job)
+		return
 	}
 
-	log.Infof("All seeds have been scraped. Successfully scraped %d seeds", uint32(len(seeds))-failedProgress)
+	if until, paused := tools.HostPausedUntil(err); paused {
+		// The seed is not to blame, it waits for the host without losing an attempt
+		log.Warnf("Postponing seed (%s) until %s <- %v", job.Seed, until.Format(time.RFC3339), err)
+		s.hold(until)
 
-	return nil
+		perr := models.PostponeScrapeJob(database.DB, job, until, err)
+		if perr != nil {
+			log.Errorf("Could not postpone scrape job: %v", perr)
+		}
+		helpers.RecordBusinessEvent("scrape_postponed")
+		return
+	}
+
+	log.Errorf("Could not Scrape <- %v", err)
+
+	status, ferr := models.FailScrapeJob(database.DB, job, err, Environment.ScrapeMaxAttempts)
+	if ferr != nil {
+		log.Errorf("Could not fail scrape job: %v", ferr)
+	}
+
+	if status == models.ScrapeJobDead {
+		helpers.RecordBusinessEvent("scrape_failed")
+		s.report(helpers.SeverityLevels.ERROR, fmt.Sprintf("was scraping seed (%s), dead after %d attempts -> %v", job.Seed, job.Attempts, err))
+		s.processed(job)
+		return
+	}
+
+	helpers.RecordBusinessEvent("scrape_retried")
+	s.report(helpers.SeverityLevels.WARN, fmt.Sprintf("was scraping seed (%s), retrying in %s -> %v", job.Seed, models.ScrapeJobBackoff(job.Attempts), err))
+}
+
+// overBudget tells whether the run spent its time or page budget, no more seeds being leased once it did
+func (s *seeder) overBudget() bool {
+	if s.spent.Load() {
+		return true
+	}
+
+	spent := (s.budget.Time > 0 && time.Since(s.started) >= s.budget.Time) ||
+		(s.budget.Pages > 0 && s.run.PageCount() >= s.budget.Pages)
+	if spent {
+		s.spent.Store(true)
+	}
+
+	return spent
+}
+
+// hold stops the workers from leasing seeds until the given time
+func (s *seeder) hold(until time.Time) {
+	for {
+		current := s.heldUntil.Load()
+		if current >= until.UnixNano() || s.heldUntil.CompareAndSwap(current, until.UnixNano()) {
+			return
+		}
+	}
+}
+
+// held returns how long the workers still have to wait before leasing seeds again
+func (s *seeder) held() time.Duration {
+	return time.Until(time.Unix(0, s.heldUntil.Load()))
+}
+
+func (s *seeder) processed(job *models.ScrapeJob) {
+	currentProgress := s.progress.Add(1)
+	// Log Progress
+	log.Infof("<<< Processed seed %d of %d: [%s] >>>", currentProgress, s.run.Seeds, job.Seed)
+}
+
+func (s *seeder) report(level helpers.SeverityType, message string) {
+	if s.reporter == nil {
+		return
+	}
+
+	err := s.reporter.Report(level, message)
+	if err != nil {
+		log.Errorf("failed to report %s: %v", strings.ToLower(string(level)), err)
+	}
+}
+
+// analyzeDividends refreshes growth rates and streaks from the dividend history stored so far
+func analyzeDividends() {
+	start := time.Now()
+	updated, err := models.UpdateDividendAnalytics(database.DB, start)
+	if err != nil {
+		log.Errorf("failed to update dividend analytics: %v", err)
+		return
+	}
+	helpers.RecordDBQueryLatency("update_dividend_analytics", start)
+
+	log.Infof("Dividend analytics updated for %d securities", updated)
+}
+
+// summarizeScrapeRun compares the selector hits of the run with the previous one, then persists it
+// and writes its JSON summary next to the scraping report
+func summarizeScrapeRun(run *models.ScrapeRun, reporter *helpers.Reporter) {
+	previous, err := models.GetLatestScrapeRun(database.DB, run.Suffix)
+	if err != nil {
+		log.Errorf("failed to get previous scrape run: %v", err)
+	}
+
+	tools.FinishScrapeRun(run, previous)
+
+	for _, alert := range run.Alerts {
+		log.Warnf("Selector drift detected: %s", alert.Message)
+		if reporter != nil {
+			err := reporter.Report(helpers.SeverityLevels.WARN, fmt.Sprintf("selector drift -> %s", alert.Message))
+			if err != nil {
+				log.Errorf("failed to report selector drift: %v", err)
+			}
+		}
+	}
+
+	start := time.Now()
+	err = models.CreateScrapeRun(database.DB, run)
+	if err != nil {
+		log.Errorf("failed to persist scrape run: %v", err)
+	}
+	helpers.RecordDBQueryLatency("create_scrape_run", start)
+
+	summary, err := json.MarshalIndent(run, "", "  ")
+	if err != nil {
+		log.Errorf("failed to encode scrape run summary: %v", err)
+		return
+	}
+
+	err = os.WriteFile(fmt.Sprintf("ScrapingRun-%d.json", run.Started.Unix()), summary, 0644)
+	if err != nil {
+		log.Errorf("failed to write scrape run summary: %v", err)
+	}
 }
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Francesco99975/finexo/cmd/boot"
+	"github.com/Francesco99975/finexo/internal/database"
+	"github.com/Francesco99975/finexo/internal/models"
+	"github.com/Francesco99975/finexo/internal/tools"
+)
+
+const usage = `Usage:
+  fixtures record [-dir fixtures] SEED...   scrape the live sites and save pages, targets and golden files
+  fixtures verify [-dir fixtures] [-update] replay every fixture and compare the parsed values with its golden file
+  fixtures selectors [-dir fixtures] [-activate PATH] FILE
+                                            verify every fixture with the selectors of FILE instead of the built-in ones,
+                                            then copy FILE to PATH (the SELECTORS_FILE of the server) if they all pass
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Print(usage)
+		os.Exit(2)
+	}
+
+	command := flag.NewFlagSet(os.Args[1], flag.ExitOnError)
+	dir := command.String("dir", "fixtures", "fixtures directory")
+	update := command.Bool("update", false, "overwrite the golden files with the replayed values")
+	activate := command.String("activate", "", "selector file to replace once the selectors pass")
+	err := command.Parse(os.Args[2:])
+	if err != nil {
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "record":
+		err = record(*dir, command.Args())
+	case "verify":
+		err = verify(*dir, *update)
+	case "selectors":
+		err = selectors(*dir, command.Args(), *activate)
+	default:
+		fmt.Print(usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// record needs the database to resolve the exchange of each seed, nothing gets persisted
+func record(dir string, seeds []string) error {
+	if len(seeds) == 0 {
+		return fmt.Errorf("no seeds to record")
+	}
+
+	err := boot.LoadEnvVariables()
+	if err != nil {
+		return err
+	}
+
+	database.Setup(boot.Environment.DSN)
+
+	err = tools.EnableFixtureRecording(dir)
+	if err != nil {
+		return err
+	}
+
+	manager := models.NewBrowserManager(100)
+	defer manager.Close()
+
+	var failed int
+	for _, seed := range seeds {
+		merged, err := tools.Collect(seed, nil, manager.GetBrowser(), nil, nil)
+		manager.ReleaseBrowser()
+		if err != nil {
+			fmt.Printf("FAIL %s: %v\n", seed, err)
+			failed++
+			continue
+		}
+
+		err = tools.WriteGolden(dir, merged)
+		if err != nil {
+			fmt.Printf("FAIL %s: %v\n", seed, err)
+			failed++
+			continue
+		}
+
+		fmt.Printf("ok   %s -> %s\n", seed, tools.FixtureName(merged.Security.Ticker, merged.Security.Exchange))
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d seeds could not be recorded", failed, len(seeds))
+	}
+
+	return nil
+}
+
+func verify(dir string, update bool) error {
+	names, err := tools.ListFixtures(dir)
+	if err != nil {
+		return err
+	}
+
+	if len(names) == 0 {
+		return fmt.Errorf("no fixtures found in %s", dir)
+	}
+
+	_, err = tools.EnableFixtureReplay(dir)
+	if err != nil {
+		return err
+	}
+	defer tools.DisableFixtures()
+
+	manager := models.NewBrowserManager(100)
+	defer manager.Close()
+	browser := manager.GetBrowser()
+	defer manager.ReleaseBrowser()
+
+	var failed int
+	for _, name := range names {
+		merged, err := tools.Replay(browser, name)
+		if err != nil {
+			fmt.Printf("FAIL %s: %v\n", name, err)
+			failed++
+			continue
+		}
+
+		if update {
+			err = tools.WriteGolden(dir, merged)
+			if err != nil {
+				fmt.Printf("FAIL %s: %v\n", name, err)
+				failed++
+				continue
+			}
+			fmt.Printf("upd  %s\n", name)
+			continue
+		}
+
+		diffs, err := tools.VerifyGolden(dir, name, merged)
+		if err != nil {
+			fmt.Printf("FAIL %s: %v\n", name, err)
+			failed++
+			continue
+		}
+
+		if len(diffs) > 0 {
+			fmt.Printf("FAIL %s\n", name)
+			for _, diff := range diffs {
+				fmt.Printf("     %s\n", diff)
+			}
+			failed++
+			continue
+		}
+
+		fmt.Printf("ok   %s\n", name)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d fixtures failed", failed, len(names))
+	}
+
+	return nil
+}
+
+// selectors checks a selector file against the fixtures before it replaces the active one,
+// the server reloading the replaced file on its own
+func selectors(dir string, files []string, activate string) error {
+	if len(files) != 1 {
+		return fmt.Errorf("expected a single selector file")
+	}
+
+	if activate != "" && !strings.EqualFold(filepath.Ext(activate), filepath.Ext(files[0])) {
+		return fmt.Errorf("%s and %s are not in the same format", files[0], activate)
+	}
+
+	set, err := tools.LoadSelectorSet(files[0])
+	if err != nil {
+		return err
+	}
+
+	tools.ActivateSelectors(set)
+	fmt.Printf("Verifying selectors %s from %s\n", set.Version, files[0])
+
+	err = verify(dir, false)
+	if err != nil {
+		return err
+	}
+
+	if activate == "" {
+		return nil
+	}
+
+	payload, err := os.ReadFile(files[0])
+	if err != nil {
+		return err
+	}
+
+	// Renamed into place so that the server never reads a half written file
+	err = os.WriteFile(activate+".tmp", payload, 0644)
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(activate+".tmp", activate)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Activated selectors %s at %s\n", set.Version, activate)
+
+	return nil
+}
@@ -2,7 +2,9 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"os/signal"
 	"time"
@@ -10,6 +12,7 @@ import (
 	"github.com/Francesco99975/finexo/cmd/boot"
 	"github.com/Francesco99975/finexo/internal/database"
 	"github.com/Francesco99975/finexo/internal/models"
+	"github.com/Francesco99975/finexo/internal/tools"
 )
 
 func main() {
@@ -36,6 +39,29 @@ func main() {
 		panic(err)
 	}
 
+	for _, source := range boot.Environment.DisabledSources {
+		err = tools.DisableSource(source)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	err = tools.WatchSelectorFile(boot.Environment.SelectorsFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		fmt.Printf("No selector file at %s, using the built-in selectors\n", boot.Environment.SelectorsFile)
+	} else if err != nil {
+		panic(err)
+	}
+
+	tools.SetPoliteness(tools.Politeness{
+		Default: tools.HostPolicy{
+			RequestsPerMinute: boot.Environment.ScrapeRequestsPerMinute,
+			Jitter:            boot.Environment.ScrapeJitter,
+			CoolDown:          boot.Environment.ScrapeCoolDown,
+		},
+		Hosts: boot.Environment.ScrapeHostRequestsPerMinute,
+	})
+
 	boot.SetupCronJobs(exchanges)
 
 	e := createRouter(ctx)
@@ -50,14 +76,20 @@ func main() {
 		panic(err)
 	}
 
-	if isDBEmpty {
-		go func() {
-			err = boot.SeedDatabase(500, "")
+	go func() {
+		if isDBEmpty {
+			err := boot.SeedDatabase(500, "")
 			if err != nil {
 				e.Logger.Fatal(err)
 			}
-		}()
-	}
+		}
+
+		// Runs interrupted by the last shutdown carry on where they stopped
+		err := boot.ResumeSeeding()
+		if err != nil {
+			e.Logger.Errorf("Error while resuming seeding: %v", err)
+		}
+	}()
 
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt)
@@ -77,8 +77,11 @@ func createRouter(ctx context.Context) *echo.Echo {
 	web.GET("/search", controllers.SearchHtmlSecurities())
 	web.GET("/select/:tp/:id", controllers.Select())
 	web.POST("/calculate", controllers.CalculateCompound())
-	web.GET("/pdf/:results", controllers.DownloadPDF())
-	web.GET("/csv/:results", controllers.DownloadCSV())
+	web.GET("/portfolio", controllers.Portfolio())
+	web.POST("/calculate/portfolio", controllers.CalculatePortfolio())
+	web.GET("/s/:id", controllers.Scenario())
+	web.GET("/pdf/:id", controllers.DownloadPDF())
+	web.GET("/csv/:id", controllers.DownloadCSV())
 
 	apigrp := e.Group("/api")
 
@@ -86,17 +89,42 @@ func createRouter(ctx context.Context) *echo.Echo {
 	apiv1.GET("/search", api.SearchSecurities())
 	apiv1.GET("/stocks", api.GetStocks())
 	apiv1.GET("/stock/:id", api.GetStock())
+	apiv1.GET("/stock/:id/history", api.GetSecurityHistory("STOCK"))
 
 	apiv1.GET("/etfs", api.GetETFs())
 	apiv1.GET("/etf/:id", api.GetETF())
+	apiv1.GET("/etf/:id/history", api.GetSecurityHistory("ETF"))
 
 	apiv1.GET("/reits", api.GetREITs())
 	apiv1.GET("/reit/:id", api.GetREIT())
+	apiv1.GET("/reit/:id/history", api.GetSecurityHistory("REIT"))
+
+	apiv1.GET("/dividends/:id/history", api.GetDividendHistory())
+	apiv1.GET("/dividends/:id/growth", api.GetDividendGrowth())
+
+	// The calculator reads a JSON body, the plans only limit the query params
+	calculate := apiv1.Group("/calculate")
+	calculate.POST("", api.CalculateInvestment())
+	calculate.POST("/hisa", api.CalculateHISA())
+	calculate.POST("/solve", api.SolveCalculation())
+	calculate.POST("/backtest", api.BacktestCalculation())
+
+	apiv1.GET("/scraper/runs", api.GetScrapeRuns())
+	apiv1.GET("/scraper/runs/latest", api.GetLatestScrapeRun())
+	apiv1.GET("/scraper/jobs/dead", api.GetDeadScrapeJobs())
 
 	apiv1.GET("/test/:seed", api.Test())
 	apiv1.GET("/test/seeds", api.TestSeeds())
 	apiv1.GET("/test/scrape/:load", api.TestScrape())
 
+	// Built last so that it covers every route above, the api tests keep it complete
+	spec, err := api.NewOpenAPI(e.Routes())
+	if err != nil {
+		log.Errorf("Incomplete OpenAPI spec: %v", err)
+	}
+	apigrp.GET("/v1/openapi.json", api.GetOpenAPI(spec))
+	apigrp.GET("/v1/docs", api.GetAPIDocs())
+
 	e.HTTPErrorHandler = serverErrorHandler
 
 	return e
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Francesco99975/finexo/internal/database"
+	"github.com/Francesco99975/finexo/internal/helpers"
+	"github.com/Francesco99975/finexo/internal/models"
+	"github.com/labstack/echo/v4"
+)
+
+// CalculateInvestment projects an investment in the security named by the sid of the input
+func CalculateInvestment() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var input models.CalcInput
+		if err := c.Bind(&input); err != nil {
+			return c.JSON(http.StatusBadRequest, models.JSONErrorResponse{Code: http.StatusBadRequest, Message: "Invalid calculation input", Error: err.Error()})
+		}
+
+		if input.SID == "" || input.SID == "default" {
+			return c.JSON(http.StatusBadRequest, models.JSONErrorResponse{Code: http.StatusBadRequest, Message: "Validation Error", Error: "sid must be a security as TICKER:EXCHANGE"})
+		}
+
+		return calculate(c, input)
+	}
+}
+
+// CalculateHISA projects a high interest savings account, the sid of the input is ignored
+func CalculateHISA() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var input models.CalcInput
+		if err := c.Bind(&input); err != nil {
+			return c.JSON(http.StatusBadRequest, models.JSONErrorResponse{Code: http.StatusBadRequest, Message: "Invalid calculation input", Error: err.Error()})
+		}
+
+		if input.Currency == "" {
+			return c.JSON(http.StatusBadRequest, models.JSONErrorResponse{Code: http.StatusBadRequest, Message: "Validation Error", Error: "currency is required"})
+		}
+		input.SID = "default"
+
+		return calculate(c, input)
+	}
+}
+
+// SolveCalculation finds the contribution, principal or years that reach the goal of the input and serves its projection
+func SolveCalculation() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var input models.CalcInput
+		if err := c.Bind(&input); err != nil {
+			return c.JSON(http.StatusBadRequest, models.JSONErrorResponse{Code: http.StatusBadRequest, Message: "Invalid calculation input", Error: err.Error()})
+		}
+
+		if input.Solve == "" {
+			return c.JSON(http.StatusBadRequest, models.JSONErrorResponse{Code: http.StatusBadRequest, Message: "Validation Error", Error: "solve must be one of contribution, principal, years"})
+		}
+
+		return calculate(c, input)
+	}
+}
+
+// BacktestCalculation replays the input on the stored history of its security since the since month and serves it next to its projection
+func BacktestCalculation() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var input models.CalcInput
+		if err := c.Bind(&input); err != nil {
+			return c.JSON(http.StatusBadRequest, models.JSONErrorResponse{Code: http.StatusBadRequest, Message: "Invalid calculation input", Error: err.Error()})
+		}
+
+		if input.SID == "" || input.SID == "default" {
+			return c.JSON(http.StatusBadRequest, models.JSONErrorResponse{Code: http.StatusBadRequest, Message: "Validation Error", Error: "sid must be a security as TICKER:EXCHANGE"})
+		}
+		if input.Since == "" {
+			return c.JSON(http.StatusBadRequest, models.JSONErrorResponse{Code: http.StatusBadRequest, Message: "Validation Error", Error: "since must be a month as YYYY-MM"})
+		}
+		input.Backtest = true
+
+		comparison, err := models.CalculateBacktest(database.DB, input)
+		if err != nil {
+			var calcErr *models.CalculationError
+			if errors.As(err, &calcErr) {
+				return c.JSON(http.StatusBadRequest, models.JSONErrorResponse{Code: http.StatusBadRequest, Message: calcErr.Message, Error: err.Error()})
+			}
+			return c.JSON(http.StatusInternalServerError, models.JSONErrorResponse{Code: http.StatusInternalServerError, Message: "Failed to backtest", Error: err.Error()})
+		}
+		for _, event := range input.Events() {
+			helpers.RecordBusinessEvent(event)
+		}
+
+		return c.JSON(http.StatusOK, comparison)
+	}
+}
+
+// calculate serves the numeric results of the input
+func calculate(c echo.Context, input models.CalcInput) error {
+	results, err := models.Calculate(database.DB, input)
+	if err != nil {
+		var calcErr *models.CalculationError
+		if errors.As(err, &calcErr) {
+			return c.JSON(http.StatusBadRequest, models.JSONErrorResponse{Code: http.StatusBadRequest, Message: calcErr.Message, Error: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, models.JSONErrorResponse{Code: http.StatusInternalServerError, Message: "Failed to calculate", Error: err.Error()})
+	}
+	for _, event := range input.Events() {
+		helpers.RecordBusinessEvent(event)
+	}
+
+	return c.JSON(http.StatusOK, results)
+}
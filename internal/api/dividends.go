@@ -0,0 +1,47 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Francesco99975/finexo/internal/database"
+	"github.com/Francesco99975/finexo/internal/helpers"
+	"github.com/Francesco99975/finexo/internal/models"
+	"github.com/labstack/echo/v4"
+)
+
+func GetDividendHistory() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		start := time.Now()
+		events, err := models.GetDividendEvents(database.DB, c.Param("id"))
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, models.JSONErrorResponse{Code: http.StatusInternalServerError, Message: "Failed to retrieve dividend history", Error: err.Error()})
+		}
+		helpers.RecordDBQueryLatency("get_dividend_history", start)
+		helpers.RecordBusinessEvent("get_dividend_history")
+
+		if len(events) == 0 {
+			return c.JSON(http.StatusNotFound, models.JSONErrorResponse{Code: http.StatusNotFound, Message: "No dividend history found", Error: "No dividend history found"})
+		}
+
+		return c.JSON(http.StatusOK, events)
+	}
+}
+
+func GetDividendGrowth() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		start := time.Now()
+		analytics, err := models.GetDividendAnalytics(database.DB, c.Param("id"))
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, models.JSONErrorResponse{Code: http.StatusInternalServerError, Message: "Failed to retrieve dividend growth", Error: err.Error()})
+		}
+		helpers.RecordDBQueryLatency("get_dividend_growth", start)
+		helpers.RecordBusinessEvent("get_dividend_growth")
+
+		if analytics == nil {
+			return c.JSON(http.StatusNotFound, models.JSONErrorResponse{Code: http.StatusNotFound, Message: "No dividend growth found", Error: "No dividend growth found"})
+		}
+
+		return c.JSON(http.StatusOK, analytics)
+	}
+}
@@ -23,15 +23,29 @@ func GetETFs() echo.HandlerFunc {
 			return c.JSON(http.StatusBadRequest, models.JSONErrorResponse{Code: http.StatusBadRequest, Message: "Validation Error", Error: err.Error()})
 		}
 
+		err = params.ValidateTypology("ETF")
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, models.JSONErrorResponse{Code: http.StatusBadRequest, Message: "Validation Error", Error: err.Error()})
+		}
+
+		shape, err := parseShape(c, "ETF")
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, models.JSONErrorResponse{Code: http.StatusBadRequest, Message: "Validation Error", Error: err.Error()})
+		}
+
+		if shape != nil {
+			return shapedSecurities(c, "ETF", "etfs", params, shape)
+		}
+
 		start := time.Now()
-		etfs, err := models.GetETFs(database.DB, params.Exchange, params.Country, params.MinPrice, params.MaxPrice, params.Order, params.Asc, params.Limit, params.Dividend)
+		etfs, err := models.GetETFs(database.DB, params)
 		if err != nil {
 			return c.JSON(http.StatusInternalServerError, models.JSONErrorResponse{Code: http.StatusInternalServerError, Message: "Failed to retrieve etfs", Error: err.Error()})
 		}
 		helpers.RecordDBQueryLatency("get_etfs", start)
 		helpers.RecordBusinessEvent("get_etfs")
 
-		if len(etfs) == 0 {
+		if etfs.Total == 0 {
 			return c.JSON(http.StatusNotFound, models.JSONErrorResponse{Code: http.StatusNotFound, Message: "No matching etfs found", Error: "No matching etfs found"})
 		}
 
@@ -41,6 +55,15 @@ func GetETFs() echo.HandlerFunc {
 
 func GetETF() echo.HandlerFunc {
 	return func(c echo.Context) error {
+		shape, err := parseShape(c, "ETF")
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, models.JSONErrorResponse{Code: http.StatusBadRequest, Message: "Validation Error", Error: err.Error()})
+		}
+
+		if shape != nil {
+			return shapedSecurity(c, "ETF", shape)
+		}
+
 		start := time.Now()
 		etf, err := models.GetETF(database.DB, c.Param("id"))
 		if err != nil {
@@ -49,6 +72,8 @@ func GetETF() echo.HandlerFunc {
 		helpers.RecordDBQueryLatency("get_etf", start)
 		helpers.RecordBusinessEvent("get_etf")
 
+		models.RecordSecurityHit(c.Param("id"))
+
 		return c.JSON(http.StatusOK, etf)
 	}
 }
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Francesco99975/finexo/internal/database"
+	"github.com/Francesco99975/finexo/internal/helpers"
+	"github.com/Francesco99975/finexo/internal/models"
+	"github.com/labstack/echo/v4"
+)
+
+const historyDateLayout = "2006-01-02"
+
+// GetSecurityHistory serves the OHLCV series of a security of the given typology (STOCK, ETF, REIT)
+func GetSecurityHistory(typology string) echo.HandlerFunc {
+	event := fmt.Sprintf("get_%s_history", strings.ToLower(typology))
+
+	return func(c echo.Context) error {
+		to := time.Now()
+		if toParam := c.QueryParam("to"); toParam != "" {
+			parsed, err := time.Parse(historyDateLayout, toParam)
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, models.JSONErrorResponse{Code: http.StatusBadRequest, Message: "Validation Error", Error: "to must be a date in the YYYY-MM-DD format"})
+			}
+			to = parsed
+		}
+
+		from := to.AddDate(-1, 0, 0)
+		if fromParam := c.QueryParam("from"); fromParam != "" {
+			parsed, err := time.Parse(historyDateLayout, fromParam)
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, models.JSONErrorResponse{Code: http.StatusBadRequest, Message: "Validation Error", Error: "from must be a date in the YYYY-MM-DD format"})
+			}
+			from = parsed
+		}
+
+		if from.After(to) {
+			return c.JSON(http.StatusBadRequest, models.JSONErrorResponse{Code: http.StatusBadRequest, Message: "Validation Error", Error: "from must not be after to"})
+		}
+
+		interval := c.QueryParam("interval")
+		if interval == "" {
+			interval = "day"
+		}
+		if _, ok := models.PriceIntervals[interval]; !ok {
+			return c.JSON(http.StatusBadRequest, models.JSONErrorResponse{Code: http.StatusBadRequest, Message: "Validation Error", Error: "interval must be one of day, week, month"})
+		}
+
+		start := time.Now()
+		prices, err := models.GetPriceHistory(database.DB, typology, c.Param("id"), from, to, interval)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, models.JSONErrorResponse{Code: http.StatusInternalServerError, Message: "Failed to retrieve price history", Error: err.Error()})
+		}
+		helpers.RecordDBQueryLatency(event, start)
+		helpers.RecordBusinessEvent(event)
+
+		if len(prices) == 0 {
+			return c.JSON(http.StatusNotFound, models.JSONErrorResponse{Code: http.StatusNotFound, Message: "No price history found", Error: "No price history found"})
+		}
+
+		return c.JSON(http.StatusOK, prices)
+	}
+}
@@ -0,0 +1,304 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Francesco99975/finexo/internal/helpers"
+	"github.com/Francesco99975/finexo/internal/models"
+	"github.com/labstack/echo/v4"
+)
+
+// OperationDoc describes what the spec cannot reflect from a route: its query params, request and response body
+type OperationDoc struct {
+	Summary  string
+	Tag      string
+	Query    any               // Struct whose query tags are the query params
+	Params   map[string]string // Extra query params and their description
+	Body     any               // Value of the type accepted as JSON body, nil without a body
+	Response any               // Value of the type served with 200
+}
+
+var shapeParams = map[string]string{
+	"fields":  "Comma separated fields to serve, dividend fields as dividend.<name>, e.g. ticker,price,dividend.yield. ETFs and REITs serve them under security like the full objects",
+	"include": "Comma separated nested data to serve: dividend, and related for ETFs",
+}
+
+// OperationDocs documents every route of the API, keyed by method and path as registered in the router
+var OperationDocs = map[string]OperationDoc{
+	"GET /api/v1/search": {Summary: "Search securities by ticker or name", Tag: "securities", Params: map[string]string{"q": "Text matched against ticker and full name"}, Response: []models.SecuritySearchView{}},
+
+	"GET /api/v1/stocks":                {Summary: "List stocks", Tag: "stocks", Query: models.SecParamsPointers{}, Params: shapeParams, Response: models.Page[models.Security]{}},
+	"GET /api/v1/stock/:id":             {Summary: "Get a stock by TICKER:EXCHANGE", Tag: "stocks", Params: shapeParams, Response: models.Security{}},
+	"GET /api/v1/stock/:id/history":     {Summary: "Price history of a stock", Tag: "stocks", Params: historyParams, Response: []models.Price{}},
+	"GET /api/v1/etfs":                  {Summary: "List ETFs", Tag: "etfs", Query: models.SecParamsPointers{}, Params: shapeParams, Response: models.Page[models.ETF]{}},
+	"GET /api/v1/etf/:id":               {Summary: "Get an ETF by TICKER:EXCHANGE", Tag: "etfs", Params: shapeParams, Response: models.ETF{}},
+	"GET /api/v1/etf/:id/history":       {Summary: "Price history of an ETF", Tag: "etfs", Params: historyParams, Response: []models.Price{}},
+	"GET /api/v1/reits":                 {Summary: "List REITs", Tag: "reits", Query: models.SecParamsPointers{}, Params: shapeParams, Response: models.Page[models.REIT]{}},
+	"GET /api/v1/reit/:id":              {Summary: "Get a REIT by TICKER:EXCHANGE", Tag: "reits", Params: shapeParams, Response: models.REIT{}},
+	"GET /api/v1/reit/:id/history":      {Summary: "Price history of a REIT", Tag: "reits", Params: historyParams, Response: []models.Price{}},
+	"GET /api/v1/dividends/:id/history": {Summary: "Dividend payments of a security, newest first", Tag: "dividends", Response: []models.DividendEvent{}},
+	"GET /api/v1/dividends/:id/growth":  {Summary: "Dividend growth, streak and cuts of a security", Tag: "dividends", Response: models.DividendAnalytics{}},
+
+	"POST /api/v1/calculate":          {Summary: "Project an investment in a security", Tag: "calculator", Body: models.CalcInput{}, Response: helpers.CalculationResults{}},
+	"POST /api/v1/calculate/hisa":     {Summary: "Project a high interest savings account", Tag: "calculator", Body: models.CalcInput{}, Response: helpers.CalculationResults{}},
+	"POST /api/v1/calculate/solve":    {Summary: "Solve the contribution, principal or years that reach a balance or income goal", Tag: "calculator", Body: models.CalcInput{}, Response: helpers.CalculationResults{}},
+	"POST /api/v1/calculate/backtest": {Summary: "Backtest an investment in a security on its price and dividend history next to its projection", Tag: "calculator", Body: models.CalcInput{}, Response: models.BacktestComparison{}},
+
+	"GET /api/v1/scraper/runs":        {Summary: "Latest scrape runs", Tag: "scraper", Params: map[string]string{"limit": "Number of runs to serve"}, Response: []models.ScrapeRun{}},
+	"GET /api/v1/scraper/runs/latest": {Summary: "Latest scrape run", Tag: "scraper", Params: map[string]string{"suffix": "Seed suffix the run was filtered on"}, Response: models.ScrapeRun{}},
+	"GET /api/v1/scraper/jobs/dead":   {Summary: "Seeds that failed too many times", Tag: "scraper", Params: map[string]string{"limit": "Number of jobs to serve"}, Response: []models.ScrapeJob{}},
+
+	"GET /api/v1/test/:seed":        {Summary: "Scrape a single seed", Tag: "test", Response: ""},
+	"GET /api/v1/test/seeds":        {Summary: "List the seeds", Tag: "test", Response: []string{}},
+	"GET /api/v1/test/scrape/:load": {Summary: "Scrape a load of seeds", Tag: "test", Response: ""},
+}
+
+var historyParams = map[string]string{
+	"from":     "First day, YYYY-MM-DD, a year before to by default",
+	"to":       "Last day, YYYY-MM-DD, today by default",
+	"interval": "Bar size: day, week or month",
+}
+
+var pathParam = regexp.MustCompile(`:(\w+)`)
+
+// NewOpenAPI builds the OpenAPI 3.1 document of the /api/v1 routes.
+// Routes missing from OperationDocs are left out of the spec and reported in the error, next to the spec of the others.
+func NewOpenAPI(routes []*echo.Route) (map[string]any, error) {
+	schemas := newSchemaRegistry()
+	paths := map[string]map[string]any{}
+	missing := []string{}
+
+	for _, route := range routes {
+		if !strings.HasPrefix(route.Path, "/api/v1/") {
+			continue
+		}
+
+		key := route.Method + " " + route.Path
+		doc, ok := OperationDocs[key]
+		if !ok {
+			missing = append(missing, key)
+			continue
+		}
+
+		parameters := []map[string]any{}
+		for _, name := range pathParam.FindAllStringSubmatch(route.Path, -1) {
+			parameters = append(parameters, map[string]any{"name": name[1], "in": "path", "required": true, "schema": map[string]any{"type": "string"}})
+		}
+		if doc.Query != nil {
+			parameters = append(parameters, queryParameters(doc.Query)...)
+		}
+		for _, name := range sortedKeys(doc.Params) {
+			parameters = append(parameters, map[string]any{"name": name, "in": "query", "description": doc.Params[name], "schema": map[string]any{"type": "string"}})
+		}
+
+		operation := map[string]any{
+			"summary":    doc.Summary,
+			"tags":       []string{doc.Tag},
+			"parameters": parameters,
+			"responses": map[string]any{
+				"200": jsonResponse("OK", schemas.schema(reflect.TypeOf(doc.Response))),
+				"400": jsonResponse("Invalid request", schemas.schema(reflect.TypeOf(models.JSONErrorResponse{}))),
+				"404": jsonResponse("Not found", schemas.schema(reflect.TypeOf(models.JSONErrorResponse{}))),
+				"500": jsonResponse("Internal error", schemas.schema(reflect.TypeOf(models.JSONErrorResponse{}))),
+			},
+		}
+
+		if doc.Body != nil {
+			operation["requestBody"] = map[string]any{"required": true, "content": map[string]any{"application/json": map[string]any{"schema": schemas.schema(reflect.TypeOf(doc.Body))}}}
+		}
+
+		path := pathParam.ReplaceAllString(route.Path, "{$1}")
+		if paths[path] == nil {
+			paths[path] = map[string]any{}
+		}
+		paths[path][strings.ToLower(route.Method)] = operation
+	}
+
+	spec := map[string]any{
+		"openapi": "3.1.0",
+		"info": map[string]any{
+			"title":       "Finexo API",
+			"version":     "1.0.0",
+			"description": "Prices are in cents and percentages are multiplied by 100 (5.50% is 550).",
+		},
+		"paths":      paths,
+		"components": map[string]any{"schemas": schemas.schemas},
+	}
+
+	if len(missing) > 0 {
+		slices.Sort(missing)
+		return spec, fmt.Errorf("routes missing from the OpenAPI spec: %s", strings.Join(missing, ", "))
+	}
+
+	return spec, nil
+}
+
+func jsonResponse(description string, schema map[string]any) map[string]any {
+	return map[string]any{"description": description, "content": map[string]any{"application/json": map[string]any{"schema": schema}}}
+}
+
+// queryParameters reflects the query tags of a params struct, every param being optional
+func queryParameters(query any) []map[string]any {
+	parameters := []map[string]any{}
+
+	t := reflect.TypeOf(query)
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name := field.Tag.Get("query")
+		if name == "" {
+			continue
+		}
+
+		kind := field.Type
+		if kind.Kind() == reflect.Pointer {
+			kind = kind.Elem()
+		}
+
+		parameter := map[string]any{"name": name, "in": "query", "schema": primitiveSchema(kind)}
+		if strings.HasPrefix(name, "min") || strings.HasPrefix(name, "max") {
+			bound := "Lower"
+			if strings.HasPrefix(name, "max") {
+				bound = "Upper"
+			}
+			parameter["description"] = fmt.Sprintf("%s bound of %s", bound, strings.ToLower(name[3:4])+name[4:])
+		}
+		parameters = append(parameters, parameter)
+	}
+
+	return parameters
+}
+
+func primitiveSchema(t reflect.Type) map[string]any {
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	default:
+		return map[string]any{"type": "string"}
+	}
+}
+
+// schemaRegistry reflects the json tags of the response types into components/schemas
+type schemaRegistry struct {
+	schemas map[string]any
+}
+
+func newSchemaRegistry() *schemaRegistry {
+	return &schemaRegistry{schemas: map[string]any{}}
+}
+
+var genericName = regexp.MustCompile(`\[(?:.*\.)?([^.\]]+)\]`)
+
+// schema returns the schema of a type, structs being referenced by name
+func (r *schemaRegistry) schema(t reflect.Type) map[string]any {
+	switch t {
+	case reflect.TypeOf(models.NullableInt{}):
+		return map[string]any{"type": []string{"integer", "null"}}
+	case reflect.TypeOf(models.NullableString{}):
+		return map[string]any{"type": []string{"string", "null"}}
+	case reflect.TypeOf(models.NullableTime{}):
+		return map[string]any{"type": []string{"string", "null"}, "format": "date-time"}
+	case reflect.TypeOf(time.Time{}):
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return r.schema(t.Elem())
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": r.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": r.schema(t.Elem())}
+	case reflect.Interface:
+		return map[string]any{}
+	case reflect.Struct:
+	default:
+		return primitiveSchema(t)
+	}
+
+	// Page[models.Security] becomes PageSecurity
+	name := genericName.ReplaceAllString(t.Name(), "$1")
+	ref := map[string]any{"$ref": "#/components/schemas/" + name}
+	if _, ok := r.schemas[name]; ok {
+		return ref
+	}
+
+	// Registered before the fields so that recursive types terminate
+	r.schemas[name] = map[string]any{}
+
+	properties := map[string]any{}
+	required := []string{}
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, options, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+
+		properties[name] = r.schema(field.Type)
+		if !strings.Contains(options, "omitempty") && field.Type.Kind() != reflect.Pointer {
+			required = append(required, name)
+		}
+	}
+
+	r.schemas[name] = map[string]any{"type": "object", "properties": properties, "required": required}
+
+	return ref
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// GetOpenAPI serves the spec built by NewOpenAPI
+func GetOpenAPI(spec map[string]any) echo.HandlerFunc {
+	raw, err := json.Marshal(spec)
+	return func(c echo.Context) error {
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, models.JSONErrorResponse{Code: http.StatusInternalServerError, Message: "Failed to encode the OpenAPI spec", Error: err.Error()})
+		}
+		return c.JSONBlob(http.StatusOK, raw)
+	}
+}
+
+// GetAPIDocs serves the Redoc UI bundled by the client build, it loads the spec from /api/v1/openapi.json
+func GetAPIDocs() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		return c.HTML(http.StatusOK, `<!DOCTYPE html>
+<html>
+  <head>
+    <title>Finexo API</title>
+    <meta charset="utf-8"/>
+    <meta name="viewport" content="width=device-width, initial-scale=1">
+  </head>
+  <body>
+    <div id="redoc"></div>
+    <script type="module" src="/assets/dist/docs.js"></script>
+  </body>
+</html>`)
+	}
+}
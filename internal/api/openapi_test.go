@@ -0,0 +1,146 @@
+package api
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+const routerFile = "../../cmd/server/router.go"
+
+var routeMethods = map[string]string{
+	"GET":    http.MethodGet,
+	"POST":   http.MethodPost,
+	"PUT":    http.MethodPut,
+	"PATCH":  http.MethodPatch,
+	"DELETE": http.MethodDelete,
+}
+
+// routerRoutes reads the routes registered in the router before it builds the spec, following the prefixes of its groups.
+// The router lives in package main, so its source is read rather than built.
+func routerRoutes(t *testing.T) []*echo.Route {
+	t.Helper()
+
+	file, err := parser.ParseFile(token.NewFileSet(), routerFile, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prefixes := map[string]string{"e": ""}
+	routes := []*echo.Route{}
+	built := token.NoPos
+
+	// The first argument of a group or route call, when it is a literal path on a known receiver
+	path := func(call *ast.CallExpr) (string, string, bool) {
+		selector, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || len(call.Args) == 0 {
+			return "", "", false
+		}
+		receiver, ok := selector.X.(*ast.Ident)
+		if !ok {
+			return "", "", false
+		}
+		prefix, ok := prefixes[receiver.Name]
+		if !ok {
+			return "", "", false
+		}
+		literal, ok := call.Args[0].(*ast.BasicLit)
+		if !ok || literal.Kind != token.STRING {
+			return "", "", false
+		}
+		value, err := strconv.Unquote(literal.Value)
+		if err != nil {
+			return "", "", false
+		}
+
+		return selector.Sel.Name, prefix + value, true
+	}
+
+	ast.Inspect(file, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.AssignStmt:
+			if len(node.Lhs) != 1 || len(node.Rhs) != 1 {
+				return true
+			}
+			name, ok := node.Lhs[0].(*ast.Ident)
+			call, isCall := node.Rhs[0].(*ast.CallExpr)
+			if !ok || !isCall {
+				return true
+			}
+			if method, prefix, ok := path(call); ok && method == "Group" {
+				prefixes[name.Name] = prefix
+			}
+		case *ast.CallExpr:
+			if selector, ok := node.Fun.(*ast.SelectorExpr); ok && selector.Sel.Name == "NewOpenAPI" {
+				built = node.Pos()
+			}
+			if built != token.NoPos {
+				return false
+			}
+			if method, route, ok := path(node); ok && routeMethods[method] != "" {
+				routes = append(routes, &echo.Route{Method: routeMethods[method], Path: route})
+			}
+		}
+		return true
+	})
+
+	if built == token.NoPos {
+		t.Fatalf("the spec is not built in %s", routerFile)
+	}
+
+	return routes
+}
+
+func TestOpenAPICoversRouter(t *testing.T) {
+	routes := routerRoutes(t)
+
+	registered := map[string]bool{}
+	for _, route := range routes {
+		if strings.HasPrefix(route.Path, "/api/v1/") {
+			registered[route.Method+" "+route.Path] = true
+		}
+	}
+	if len(registered) == 0 {
+		t.Fatalf("no /api/v1 routes found in %s", routerFile)
+	}
+
+	_, err := NewOpenAPI(routes)
+	if err != nil {
+		t.Error(err)
+	}
+
+	for key := range OperationDocs {
+		if !registered[key] {
+			t.Errorf("%s is documented but not registered in the router", key)
+		}
+	}
+}
+
+func TestOpenAPIServesDocumentedRoutesWhenIncomplete(t *testing.T) {
+	routes := []*echo.Route{
+		{Method: http.MethodGet, Path: "/api/v1/stocks"},
+		{Method: http.MethodGet, Path: "/api/v1/undocumented"},
+	}
+
+	spec, err := NewOpenAPI(routes)
+	if err == nil || !strings.Contains(err.Error(), "GET /api/v1/undocumented") {
+		t.Errorf("expected the undocumented route to be reported, got %v", err)
+	}
+	if spec == nil {
+		t.Fatal("expected the spec of the documented routes")
+	}
+
+	paths := spec["paths"].(map[string]map[string]any)
+	if _, ok := paths["/api/v1/stocks"]["get"]; !ok {
+		t.Error("expected GET /api/v1/stocks in the spec")
+	}
+	if _, ok := paths["/api/v1/undocumented"]; ok {
+		t.Error("expected the undocumented route to be left out of the spec")
+	}
+}
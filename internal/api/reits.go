@@ -23,9 +23,23 @@ func GetREITs() echo.HandlerFunc {
 			return c.JSON(http.StatusBadRequest, models.JSONErrorResponse{Code: http.StatusBadRequest, Message: err.Error()})
 		}
 
+		err = params.ValidateTypology("REIT")
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, models.JSONErrorResponse{Code: http.StatusBadRequest, Message: err.Error()})
+		}
+
+		shape, err := parseShape(c, "REIT")
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, models.JSONErrorResponse{Code: http.StatusBadRequest, Message: err.Error()})
+		}
+
+		if shape != nil {
+			return shapedSecurities(c, "REIT", "reits", params, shape)
+		}
+
 		start := time.Now()
 
-		reits, err := models.GetREITs(database.DB, params.Exchange, params.Country, params.MinPrice, params.MaxPrice, params.Order, params.Asc, params.Limit, params.Dividend)
+		reits, err := models.GetREITs(database.DB, params)
 		if err != nil {
 			return c.JSON(http.StatusInternalServerError, models.JSONErrorResponse{Code: http.StatusInternalServerError, Message: "Failed to retrieve reits", Error: err.Error()})
 		}
@@ -33,7 +47,7 @@ func GetREITs() echo.HandlerFunc {
 		helpers.RecordDBQueryLatency("get_reits", start)
 		helpers.RecordBusinessEvent("get_reits")
 
-		if len(reits) == 0 {
+		if reits.Total == 0 {
 			return c.JSON(http.StatusNotFound, models.JSONErrorResponse{Code: http.StatusNotFound, Message: "No matching reits found", Error: "No matching reits found"})
 		}
 
@@ -43,6 +57,15 @@ func GetREITs() echo.HandlerFunc {
 
 func GetREIT() echo.HandlerFunc {
 	return func(c echo.Context) error {
+		shape, err := parseShape(c, "REIT")
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, models.JSONErrorResponse{Code: http.StatusBadRequest, Message: "Validation Error", Error: err.Error()})
+		}
+
+		if shape != nil {
+			return shapedSecurity(c, "REIT", shape)
+		}
+
 		start := time.Now()
 		reit, err := models.GetREIT(database.DB, c.Param("id"))
 		if err != nil {
@@ -51,6 +74,8 @@ func GetREIT() echo.HandlerFunc {
 		helpers.RecordDBQueryLatency("get_reit", start)
 		helpers.RecordBusinessEvent("get_reit")
 
+		models.RecordSecurityHit(c.Param("id"))
+
 		return c.JSON(http.StatusOK, reit)
 	}
 }
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Francesco99975/finexo/internal/database"
+	"github.com/Francesco99975/finexo/internal/helpers"
+	"github.com/Francesco99975/finexo/internal/models"
+	"github.com/labstack/echo/v4"
+)
+
+func GetScrapeRuns() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		limit := 20
+		if limitParam := c.QueryParam("limit"); limitParam != "" {
+			parsed, err := strconv.Atoi(limitParam)
+			if err != nil || parsed <= 0 || parsed > 100 {
+				return c.JSON(http.StatusBadRequest, models.JSONErrorResponse{Code: http.StatusBadRequest, Message: "Validation Error", Error: "limit must be between 1 and 100"})
+			}
+			limit = parsed
+		}
+
+		start := time.Now()
+		runs, err := models.GetScrapeRuns(database.DB, limit)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, models.JSONErrorResponse{Code: http.StatusInternalServerError, Message: "Failed to retrieve scrape runs", Error: err.Error()})
+		}
+		helpers.RecordDBQueryLatency("get_scrape_runs", start)
+		helpers.RecordBusinessEvent("get_scrape_runs")
+
+		return c.JSON(http.StatusOK, runs)
+	}
+}
+
+func GetLatestScrapeRun() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		start := time.Now()
+		run, err := models.GetLatestScrapeRun(database.DB, c.QueryParam("suffix"))
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, models.JSONErrorResponse{Code: http.StatusInternalServerError, Message: "Failed to retrieve scrape run", Error: err.Error()})
+		}
+		helpers.RecordDBQueryLatency("get_latest_scrape_run", start)
+		helpers.RecordBusinessEvent("get_latest_scrape_run")
+
+		if run == nil {
+			return c.JSON(http.StatusNotFound, models.JSONErrorResponse{Code: http.StatusNotFound, Message: "No scrape run found", Error: "No scrape run found"})
+		}
+
+		return c.JSON(http.StatusOK, run)
+	}
+}
+
+// GetDeadScrapeJobs serves the seeds moved to the dead letters after failing too many times
+func GetDeadScrapeJobs() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		limit := 100
+		if limitParam := c.QueryParam("limit"); limitParam != "" {
+			parsed, err := strconv.Atoi(limitParam)
+			if err != nil || parsed <= 0 || parsed > 500 {
+				return c.JSON(http.StatusBadRequest, models.JSONErrorResponse{Code: http.StatusBadRequest, Message: "Validation Error", Error: "limit must be between 1 and 500"})
+			}
+			limit = parsed
+		}
+
+		start := time.Now()
+		jobs, err := models.GetDeadScrapeJobs(database.DB, limit)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, models.JSONErrorResponse{Code: http.StatusInternalServerError, Message: "Failed to retrieve dead scrape jobs", Error: err.Error()})
+		}
+		helpers.RecordDBQueryLatency("get_dead_scrape_jobs", start)
+		helpers.RecordBusinessEvent("get_dead_scrape_jobs")
+
+		return c.JSON(http.StatusOK, jobs)
+	}
+}
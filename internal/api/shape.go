@@ -0,0 +1,58 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Francesco99975/finexo/internal/database"
+	"github.com/Francesco99975/finexo/internal/helpers"
+	"github.com/Francesco99975/finexo/internal/models"
+	"github.com/labstack/echo/v4"
+)
+
+// parseShape reads the fields and include query params, nil when the full securities are requested
+func parseShape(c echo.Context, typology string) (*models.Shape, error) {
+	return models.ParseShape(typology, c.QueryParam("fields"), c.QueryParam("include"))
+}
+
+// shapedSecurities serves a list endpoint with only the requested fields, name is the plural used in metrics and messages
+func shapedSecurities(c echo.Context, typology string, name string, params *models.SecParams, shape *models.Shape) error {
+	start := time.Now()
+	page, err := models.GetShapedSecurities(database.DB, typology, params, shape)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.JSONErrorResponse{Code: http.StatusInternalServerError, Message: fmt.Sprintf("Failed to retrieve %s", name), Error: err.Error()})
+	}
+	helpers.RecordDBQueryLatency("get_"+name, start)
+	helpers.RecordBusinessEvent("get_" + name)
+
+	if page.Total == 0 {
+		message := fmt.Sprintf("No matching %s found", name)
+		return c.JSON(http.StatusNotFound, models.JSONErrorResponse{Code: http.StatusNotFound, Message: message, Error: message})
+	}
+
+	return c.JSON(http.StatusOK, page)
+}
+
+// shapedSecurity serves a detail endpoint with only the requested fields
+func shapedSecurity(c echo.Context, typology string, shape *models.Shape) error {
+	name := strings.ToLower(typology)
+
+	start := time.Now()
+	security, err := models.GetShapedSecurity(database.DB, typology, c.Param("id"), shape)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.JSONErrorResponse{Code: http.StatusInternalServerError, Message: fmt.Sprintf("Failed to retrieve %s", name), Error: err.Error()})
+	}
+	helpers.RecordDBQueryLatency("get_"+name, start)
+	helpers.RecordBusinessEvent("get_" + name)
+
+	if security == nil {
+		message := fmt.Sprintf("No %s found", name)
+		return c.JSON(http.StatusNotFound, models.JSONErrorResponse{Code: http.StatusNotFound, Message: message, Error: message})
+	}
+
+	models.RecordSecurityHit(c.Param("id"))
+
+	return c.JSON(http.StatusOK, security)
+}
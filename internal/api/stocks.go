@@ -23,15 +23,29 @@ func GetStocks() echo.HandlerFunc {
 			return c.JSON(http.StatusBadRequest, models.JSONErrorResponse{Code: http.StatusBadRequest, Message: "Validation Error", Error: err.Error()})
 		}
 
+		err = params.ValidateTypology("STOCK")
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, models.JSONErrorResponse{Code: http.StatusBadRequest, Message: "Validation Error", Error: err.Error()})
+		}
+
+		shape, err := parseShape(c, "STOCK")
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, models.JSONErrorResponse{Code: http.StatusBadRequest, Message: "Validation Error", Error: err.Error()})
+		}
+
+		if shape != nil {
+			return shapedSecurities(c, "STOCK", "stocks", params, shape)
+		}
+
 		start := time.Now()
-		stocks, err := models.GetStocks(database.DB, params.Exchange, params.Country, params.MinPrice, params.MaxPrice, params.Order, params.Asc, params.Limit, params.Dividend)
+		stocks, err := models.GetStocks(database.DB, params)
 		if err != nil {
 			return c.JSON(http.StatusInternalServerError, models.JSONErrorResponse{Code: http.StatusInternalServerError, Message: "Failed to retrieve stocks", Error: err.Error()})
 		}
 		helpers.RecordDBQueryLatency("get_stocks", start)
 		helpers.RecordBusinessEvent("get_stocks")
 
-		if len(stocks) == 0 {
+		if stocks.Total == 0 {
 			return c.JSON(http.StatusNotFound, models.JSONErrorResponse{Code: http.StatusNotFound, Message: "No matching stocks found", Error: "No matching stocks found"})
 		}
 
@@ -41,6 +55,15 @@ func GetStocks() echo.HandlerFunc {
 
 func GetStock() echo.HandlerFunc {
 	return func(c echo.Context) error {
+		shape, err := parseShape(c, "STOCK")
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, models.JSONErrorResponse{Code: http.StatusBadRequest, Message: "Validation Error", Error: err.Error()})
+		}
+
+		if shape != nil {
+			return shapedSecurity(c, "STOCK", shape)
+		}
+
 		start := time.Now()
 		stock, err := models.GetStock(database.DB, c.Param("id"))
 		if err != nil {
@@ -49,6 +72,8 @@ func GetStock() echo.HandlerFunc {
 		helpers.RecordDBQueryLatency("get_stock", start)
 		helpers.RecordBusinessEvent("get_stock")
 
+		models.RecordSecurityHit(c.Param("id"))
+
 		return c.JSON(http.StatusOK, stock)
 	}
 }
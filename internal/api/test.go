@@ -27,7 +27,7 @@ func Test() echo.HandlerFunc {
 			log.Errorf("Failed to create discoverer: %v", err)
 		}
 
-		err = tools.Scrape(seed, nil, manager, nil, nil, d)
+		err = tools.Scrape(seed, nil, manager, d, nil)
 		if err != nil {
 			log.Errorf("Failed to scrape: %v", err)
 		}
@@ -1,8 +1,10 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
 
+	"github.com/Francesco99975/finexo/cmd/boot"
 	"github.com/Francesco99975/finexo/internal/database"
 	"github.com/Francesco99975/finexo/internal/helpers"
 	"github.com/Francesco99975/finexo/internal/models"
@@ -15,56 +17,106 @@ func CalculateCompound() echo.HandlerFunc {
 	return func(c echo.Context) error {
 		var input models.CalcInput
 		if err := c.Bind(&input); err != nil {
-			log.Errorf("failed to bind form data: %w", err)
+			log.Errorf("failed to bind form data: %v", err)
 
 			html := helpers.MustRenderHTML(components.ErrorMsg("Invalid form data"))
 
 			return c.Blob(http.StatusBadRequest, "text/html; charset=utf-8", html)
 		}
 
-		var results helpers.CalculationResults
-		if input.SID != "default" {
-			vars, err := models.GetSecurityVars(database.DB, input.SID)
-			if err != nil {
-				log.Errorf("failed to get security vars: %w", err)
+		// An empty inflation field binds to zero, the default of the currency is meant
+		if c.FormValue("inflation") == "" {
+			input.Inflation = nil
+		}
+
+		if input.Backtest {
+			return backtestCompound(c, input)
+		}
 
-				html := helpers.MustRenderHTML(components.ErrorMsg("Could not get identify security to do calculations"))
+		results, err := models.Calculate(database.DB, input)
+		if err != nil {
+			log.Errorf("failed to calculate: %v", err)
 
-				return c.Blob(http.StatusBadRequest, "text/html; charset=utf-8", html)
+			message := "Failed to calculate"
+			var calcErr *models.CalculationError
+			if errors.As(err, &calcErr) {
+				message = calcErr.Message
 			}
 
-			results, err = helpers.CalculateInvestment(input.SID, vars.Price, vars.Yield, vars.ExpenseRatio, input.Principal, input.Contribution, input.ContribFrequency, vars.Frequency, input.PriceMod, input.YieldMod, input.Years, vars.PayoutMonth, vars.Currency)
-			if err != nil {
-				log.Errorf("failed to calculate investment compound: %w", err)
+			html := helpers.MustRenderHTML(components.ErrorMsg(message))
+			return c.Blob(http.StatusBadRequest, "text/html; charset=utf-8", html)
+		}
+		for _, event := range input.Events() {
+			helpers.RecordBusinessEvent(event)
+		}
 
-				html := helpers.MustRenderHTML(components.ErrorMsg("Failed to calculate investment compound"))
-				return c.Blob(http.StatusBadRequest, "text/html; charset=utf-8", html)
-			}
-			helpers.RecordBusinessEvent("calculate_investment")
-		} else {
-			var err error
-			results, err = helpers.CalculateHISAInvestment(input.Principal, input.Contribution, input.ContribFrequency, input.CompundingFrequency, input.Rate, input.Years, input.Currency)
-			if err != nil {
-				log.Errorf("failed to calculate hisa compound: %w", err)
-
-				html := helpers.MustRenderHTML(components.ErrorMsg("Failed to calculate hisa compound"))
-				return c.Blob(http.StatusBadRequest, "text/html; charset=utf-8", html)
-			}
-			helpers.RecordBusinessEvent("calculate_hisa")
+		scenarioID, err := models.SaveScenario(database.DB, models.CompoundScenario, input, boot.Environment.ScenarioTTL)
+		if err != nil {
+			log.Errorf("failed to save scenario: %v", err)
+			html := helpers.MustRenderHTML(components.ErrorMsg("Failed to save scenario"))
+			return c.Blob(http.StatusInternalServerError, "text/html; charset=utf-8", html)
 		}
 
-		encodedResults, err := results.Encoded()
+		formatter, err := helpers.NewFormatter(helpers.Locale(c.Request().Header.Get("Accept-Language")), results.Currency)
 		if err != nil {
-			log.Errorf("failed to encode results: %w", err)
-			html := helpers.MustRenderHTML(components.ErrorMsg("Failed to encode results"))
+			log.Errorf("failed to format results: %v", err)
+			html := helpers.MustRenderHTML(components.ErrorMsg("Failed to format results"))
 			return c.Blob(http.StatusBadRequest, "text/html; charset=utf-8", html)
 		}
 
 		csrfToken := c.Get("csrf").(string)
 
-		html := helpers.MustRenderHTML(components.Calculations(results, encodedResults, csrfToken))
+		html := helpers.MustRenderHTML(components.Calculations(results, formatter, scenarioID, csrfToken))
 
 		return c.Blob(http.StatusOK, "text/html; charset=utf-8", html)
 
 	}
 }
+
+// backtestCompound renders the backtest of the input next to its projection
+func backtestCompound(c echo.Context, input models.CalcInput) error {
+	comparison, err := models.CalculateBacktest(database.DB, input)
+	if err != nil {
+		log.Errorf("failed to backtest: %v", err)
+
+		message := "Failed to backtest"
+		var calcErr *models.CalculationError
+		if errors.As(err, &calcErr) {
+			message = calcErr.Message
+		}
+
+		html := helpers.MustRenderHTML(components.ErrorMsg(message))
+		return c.Blob(http.StatusBadRequest, "text/html; charset=utf-8", html)
+	}
+	for _, event := range input.Events() {
+		helpers.RecordBusinessEvent(event)
+	}
+
+	// Each side is shared on its own
+	backtestID, err := models.SaveScenario(database.DB, models.CompoundScenario, input, boot.Environment.ScenarioTTL)
+	if err != nil {
+		log.Errorf("failed to save scenario: %v", err)
+		html := helpers.MustRenderHTML(components.ErrorMsg("Failed to save scenario"))
+		return c.Blob(http.StatusInternalServerError, "text/html; charset=utf-8", html)
+	}
+
+	projectionID, err := models.SaveScenario(database.DB, models.CompoundScenario, comparison.ProjectionInput, boot.Environment.ScenarioTTL)
+	if err != nil {
+		log.Errorf("failed to save scenario: %v", err)
+		html := helpers.MustRenderHTML(components.ErrorMsg("Failed to save scenario"))
+		return c.Blob(http.StatusInternalServerError, "text/html; charset=utf-8", html)
+	}
+
+	formatter, err := helpers.NewFormatter(helpers.Locale(c.Request().Header.Get("Accept-Language")), comparison.Backtest.Currency)
+	if err != nil {
+		log.Errorf("failed to format results: %v", err)
+		html := helpers.MustRenderHTML(components.ErrorMsg("Failed to format results"))
+		return c.Blob(http.StatusBadRequest, "text/html; charset=utf-8", html)
+	}
+
+	csrfToken := c.Get("csrf").(string)
+
+	html := helpers.MustRenderHTML(components.BacktestComparison(comparison, formatter, backtestID, projectionID, csrfToken))
+
+	return c.Blob(http.StatusOK, "text/html; charset=utf-8", html)
+}
@@ -13,20 +13,25 @@ import (
 
 func DownloadCSV() echo.HandlerFunc {
 	return func(c echo.Context) error {
-		encodedResults := c.Param("results")
-		if encodedResults == "" {
-			return echo.NewHTTPError(http.StatusBadRequest, "No results found")
+		results, err := scenarioResults(c)
+		if err != nil {
+			return err
+		}
+
+		// Reports are in nominal values unless the real ones are asked for
+		if c.QueryParam("values") == "real" {
+			results = results.Real()
 		}
 
-		results, err := helpers.DecodeResults(encodedResults)
+		formatter, err := helpers.NewFormatter(helpers.Locale(c.Request().Header.Get("Accept-Language")), results.Currency)
 		if err != nil {
-			log.Errorf("Could not decode results: %w", err)
-			return echo.NewHTTPError(http.StatusBadRequest, fmt.Errorf("Could not decode results: %w", err))
+			log.Errorf("Could not format results: %v", err)
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Errorf("Could not format results: %w", err))
 		}
 
-		filename, err := tools.GenerateCSV(results)
+		filename, err := tools.GenerateCSV(results, formatter)
 		if err != nil {
-			log.Errorf("Could not generate CSV: %w", err)
+			log.Errorf("Could not generate CSV: %v", err)
 			return echo.NewHTTPError(http.StatusBadRequest, fmt.Errorf("Could not generate PDF: %w", err))
 		}
 
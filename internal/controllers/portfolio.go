@@ -0,0 +1,81 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Francesco99975/finexo/cmd/boot"
+	"github.com/Francesco99975/finexo/internal/database"
+	"github.com/Francesco99975/finexo/internal/helpers"
+	"github.com/Francesco99975/finexo/internal/models"
+	"github.com/Francesco99975/finexo/views"
+	"github.com/Francesco99975/finexo/views/components"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+)
+
+func Portfolio() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		data := models.GetDefaultSite("Portfolio")
+
+		csrfToken := c.Get("csrf").(string)
+		nonce := c.Get("nonce").(string)
+
+		html := helpers.MustRenderHTML(views.Portfolio(data, csrfToken, nonce))
+
+		return c.Blob(200, "text/html; charset=utf-8", html)
+	}
+}
+
+func CalculatePortfolio() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var input models.PortfolioInput
+		if err := c.Bind(&input); err != nil {
+			log.Errorf("failed to bind form data: %v", err)
+
+			html := helpers.MustRenderHTML(components.ErrorMsg("Invalid form data"))
+
+			return c.Blob(http.StatusBadRequest, "text/html; charset=utf-8", html)
+		}
+
+		// An empty inflation field binds to zero, the default of the currency is meant
+		if c.FormValue("inflation") == "" {
+			input.Inflation = nil
+		}
+
+		results, err := models.CalculatePortfolio(database.DB, input)
+		if err != nil {
+			log.Errorf("failed to calculate portfolio: %v", err)
+
+			message := "Failed to calculate portfolio"
+			var calcErr *models.CalculationError
+			if errors.As(err, &calcErr) {
+				message = calcErr.Message
+			}
+
+			html := helpers.MustRenderHTML(components.ErrorMsg(message))
+			return c.Blob(http.StatusBadRequest, "text/html; charset=utf-8", html)
+		}
+		helpers.RecordBusinessEvent("calculate_portfolio")
+
+		scenarioID, err := models.SaveScenario(database.DB, models.PortfolioScenario, input, boot.Environment.ScenarioTTL)
+		if err != nil {
+			log.Errorf("failed to save scenario: %v", err)
+			html := helpers.MustRenderHTML(components.ErrorMsg("Failed to save scenario"))
+			return c.Blob(http.StatusInternalServerError, "text/html; charset=utf-8", html)
+		}
+
+		formatter, err := helpers.NewFormatter(helpers.Locale(c.Request().Header.Get("Accept-Language")), results.Currency)
+		if err != nil {
+			log.Errorf("failed to format results: %v", err)
+			html := helpers.MustRenderHTML(components.ErrorMsg("Failed to format results"))
+			return c.Blob(http.StatusBadRequest, "text/html; charset=utf-8", html)
+		}
+
+		csrfToken := c.Get("csrf").(string)
+
+		html := helpers.MustRenderHTML(components.Calculations(results, formatter, scenarioID, csrfToken))
+
+		return c.Blob(http.StatusOK, "text/html; charset=utf-8", html)
+	}
+}
@@ -70,7 +70,7 @@ func TrySeed() echo.HandlerFunc {
 			log.Warnf("failed to create discoverer: %v", err)
 		}
 
-		err = tools.Scrape(input.Ticker, &input.Exchange, manager, nil, nil, d)
+		err = tools.Scrape(input.Ticker, &input.Exchange, manager, d, nil)
 		if err != nil {
 			html := helpers.MustRenderHTML(components.ErrorMsg("Security could not be scraped"))
 
@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Francesco99975/finexo/internal/database"
+	"github.com/Francesco99975/finexo/internal/helpers"
+	"github.com/Francesco99975/finexo/internal/models"
+	"github.com/Francesco99975/finexo/views"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+)
+
+// Scenario renders the results of a shared scenario, calculated again on the data stored now
+func Scenario() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		results, err := scenarioResults(c)
+		if err != nil {
+			return err
+		}
+
+		formatter, err := helpers.NewFormatter(helpers.Locale(c.Request().Header.Get("Accept-Language")), results.Currency)
+		if err != nil {
+			log.Errorf("Could not format results: %v", err)
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Errorf("Could not format results: %w", err))
+		}
+		helpers.RecordBusinessEvent("view_scenario")
+
+		data := models.GetDefaultSite("Scenario")
+
+		csrfToken := c.Get("csrf").(string)
+		nonce := c.Get("nonce").(string)
+
+		html := helpers.MustRenderHTML(views.Scenario(data, results, formatter, c.Param("id"), csrfToken, nonce))
+
+		return c.Blob(http.StatusOK, "text/html; charset=utf-8", html)
+	}
+}
+
+// scenarioResults calculates the results of the scenario named by the id path parameter
+func scenarioResults(c echo.Context) (helpers.CalculationResults, error) {
+	scenario, err := models.GetScenario(database.DB, c.Param("id"))
+	if err != nil {
+		log.Errorf("Could not get scenario: %v", err)
+		return helpers.CalculationResults{}, echo.NewHTTPError(http.StatusInternalServerError, "Could not get scenario")
+	}
+	if scenario == nil {
+		return helpers.CalculationResults{}, echo.NewHTTPError(http.StatusNotFound, "Scenario not found or expired")
+	}
+
+	results, err := scenario.Results(database.DB)
+	if err != nil {
+		log.Errorf("Could not calculate scenario %s: %v", scenario.ID, err)
+
+		message := "Could not calculate scenario"
+		var calcErr *models.CalculationError
+		if errors.As(err, &calcErr) {
+			message = calcErr.Message
+		}
+		return helpers.CalculationResults{}, echo.NewHTTPError(http.StatusBadRequest, message)
+	}
+
+	return results, nil
+}
@@ -36,6 +36,7 @@ func Select() echo.HandlerFunc {
 		}
 
 		log.Debugf("Selected security: %+v", selectedSecurity)
+		models.RecordSecurityHit(id)
 
 		csrfToken := c.Get("csrf").(string)
 
@@ -0,0 +1,261 @@
+package helpers
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+// PricePoint is the close of a security at the end of a month, Date being the first day of the month
+type PricePoint struct {
+	Date  time.Time
+	Close float64
+}
+
+// DividendPayment is a dividend paid per share of a security
+type DividendPayment struct {
+	ExDivDate time.Time
+	Amount    float64
+}
+
+// BacktestResults summarizes a plan replayed on the history of a security, rates are in percent
+type BacktestResults struct {
+	From           time.Time `json:"from"` // First month of the history
+	To             time.Time `json:"to"`   // Last month of the history
+	DRIP           bool      `json:"drip"`
+	CAGR           float64   `json:"cagr"`        // Time-weighted, the contributions do not count as growth
+	MaxDrawdown    float64   `json:"maxDrawdown"` // Largest time-weighted fall from a peak
+	TotalDividends float64   `json:"totalDividends"`
+	YieldOnCost    float64   `json:"yieldOnCost"` // Dividends of the last twelve months over the contributions
+	Cash           float64   `json:"cash"`        // Dividends held in cash without DRIP, part of the balance
+}
+
+// Backtest replays a plan on the monthly closes of a security and the dividends it actually paid.
+// The principal and every contribution buy shares at the close of their month, a dividend is paid on the
+// shares held before the contribution of its month and, with DRIP, buys shares at the close of the month,
+// without DRIP it is held in cash.
+func Backtest(sid string, closes []PricePoint, dividends []DividendPayment, principal, contribution float64,
+	contributionFreqStr, dividendFreqStr string, drip bool, currency string, tax *TaxProfile, sourceCountry string,
+) (CalculationResults, error) {
+	if err := ValidateCurrency(currency); err != nil {
+		return CalculationResults{}, err
+	}
+
+	if len(closes) == 0 {
+		return CalculationResults{}, fmt.Errorf("no price history to backtest %s", sid)
+	}
+	for _, point := range closes {
+		if point.Close <= 0 {
+			return CalculationResults{}, fmt.Errorf("invalid close of %s on %s", sid, point.Date.Format("2006-01-02"))
+		}
+	}
+
+	closes = slices.Clone(closes)
+	slices.SortFunc(closes, func(a, b PricePoint) int { return a.Date.Compare(b.Date) })
+	dividends = slices.Clone(dividends)
+	slices.SortFunc(dividends, func(a, b DividendPayment) int { return a.ExDivDate.Compare(b.ExDivDate) })
+
+	contributionFrequency := frequencyToMonths(contributionFreqStr)
+
+	// Share of every dividend lost to withholding and income taxes
+	dividendTax := 0.0
+	if tax != nil {
+		dividendTax = tax.DividendTax(sourceCountry) / 100
+	}
+
+	first := closes[0]
+	last := closes[len(closes)-1]
+
+	// Dividends paid per share in the first year, for the starting yield
+	firstYearDividends := 0.0
+	for _, dividend := range dividends {
+		if !dividend.ExDivDate.Before(first.Date) && dividend.ExDivDate.Before(first.Date.AddDate(1, 0, 0)) {
+			firstYearDividends += dividend.Amount
+		}
+	}
+
+	shares := principal / first.Close
+	totalContributions := principal
+	costBasis := principal
+	cash := 0.0
+	cumGain := 0.0
+	totalDividends := 0.0
+	totalTax := 0.0
+	lastYearDividends := 0.0
+
+	// Time-weighted growth of the plan, for the CAGR and the drawdowns
+	index := 1.0
+	peak := 1.0
+	maxDrawdown := 0.0
+
+	yearResults := []YearCalcResults{}
+	monthResults := []MonthCalcResults{}
+	yearGains := 0.0
+	yearTax := 0.0
+	next := 0 // First dividend not paid yet
+
+	closeYear := func(year int) {
+		balance := monthResults[len(monthResults)-1].Balance
+
+		prevBalance := totalContributions
+		if len(yearResults) > 0 {
+			prevBalance = yearResults[len(yearResults)-1].Balance
+		}
+		yoyGrowth := 0.0
+		if prevBalance > 0 {
+			yoyGrowth = (balance - prevBalance) / prevBalance * 100
+		}
+		totalGrowth := 0.0
+		if totalContributions > 0 {
+			totalGrowth = (balance - totalContributions) / totalContributions * 100
+		}
+
+		totalTax += yearTax
+		var taxDrag *float64
+		if tax != nil {
+			taxDrag = Ptr(yearTax)
+		}
+
+		yearResults = append(yearResults, YearCalcResults{
+			YearName:       fmt.Sprintf("Year (%d) - %d", len(yearResults)+1, year),
+			ShareAmount:    Ptr(shares),
+			TotalYearGains: yearGains,
+			CumGain:        cumGain,
+			YoyGrowth:      yoyGrowth,
+			TotalGrowth:    totalGrowth,
+			Balance:        balance,
+			TaxDrag:        taxDrag,
+			MonthsResults:  monthResults,
+		})
+
+		monthResults = []MonthCalcResults{}
+		yearGains = 0
+		yearTax = 0
+	}
+
+	for month, point := range closes {
+		if month > 0 && point.Date.Year() != closes[month-1].Date.Year() {
+			closeYear(closes[month-1].Date.Year())
+		}
+
+		prevClose := point.Close
+		if month > 0 {
+			prevClose = closes[month-1].Close
+		}
+		sharesBefore := shares
+		balanceBeginning := sharesBefore*prevClose + cash
+
+		// Dividends gone ex in the month, none before the first shares are bought at the close of the first month
+		monthEnd := point.Date.AddDate(0, 1, 0)
+		dividendReceived := 0.0
+		for next < len(dividends) && dividends[next].ExDivDate.Before(monthEnd) {
+			if month > 0 && !dividends[next].ExDivDate.Before(point.Date) {
+				dividendGross := sharesBefore * dividends[next].Amount
+				yearTax += dividendGross * dividendTax
+				dividendReceived += dividendGross * (1 - dividendTax)
+			}
+			next++
+		}
+		totalDividends += dividendReceived
+		costBasis += dividendReceived
+		if !point.Date.Before(last.Date.AddDate(-1, 1, 0)) {
+			lastYearDividends += dividendReceived
+		}
+
+		var dripShares *float64
+		if dividendReceived > 0 {
+			if drip {
+				dripShares = Ptr(dividendReceived / point.Close)
+				shares += *dripShares
+			} else {
+				cash += dividendReceived
+			}
+		}
+
+		// Contributions start in the first month, along with the principal
+		contributionThisMonth := 0.0
+		if month%contributionFrequency == 0 {
+			contributionThisMonth = contribution
+			shares += contribution / point.Close
+			totalContributions += contribution
+			costBasis += contribution
+		}
+
+		balanceEnd := shares*point.Close + cash
+		gainFromPrice := sharesBefore * (point.Close - prevClose)
+		monthlyGain := gainFromPrice + dividendReceived
+		cumGain += monthlyGain
+		yearGains += monthlyGain
+
+		returnPercent := 0.0
+		if month > 0 && balanceBeginning > 0 {
+			growth := (balanceEnd - contributionThisMonth) / balanceBeginning
+			returnPercent = (growth - 1) * 100
+
+			index *= growth
+			peak = math.Max(peak, index)
+			maxDrawdown = math.Max(maxDrawdown, (peak-index)/peak*100)
+		}
+
+		monthResults = append(monthResults, MonthCalcResults{
+			MonthName:                  point.Date.Month().String(),
+			ShareAmount:                Ptr(shares),
+			Contributions:              totalContributions,
+			MonthlyGainedFromPriceInc:  gainFromPrice,
+			MonthlyGainedFromDividends: Ptr(dividendReceived),
+			MonthlyGain:                monthlyGain,
+			CumGain:                    cumGain,
+			Balance:                    balanceEnd,
+			Return:                     returnPercent,
+			DRIPShares:                 dripShares,
+		})
+	}
+	closeYear(last.Date.Year())
+
+	cagr := 0.0
+	if months := len(closes) - 1; months > 0 {
+		cagr = (math.Pow(index, 12/float64(months)) - 1) * 100
+	}
+
+	yieldOnCost := 0.0
+	if totalContributions > 0 {
+		yieldOnCost = lastYearDividends / totalContributions * 100
+	}
+
+	finalBalance := shares*last.Close + cash
+
+	results := CalculationResults{
+		SID:                sid,
+		Principal:          principal,
+		Contribution:       contribution,
+		Price:              Ptr(first.Close),
+		Rate:               firstYearDividends / first.Close * 100,
+		RateFreq:           Capitalize(dividendFreqStr),
+		Currency:           currency,
+		Profit:             finalBalance - totalContributions,
+		TotalContributions: totalContributions,
+		ContribFreq:        Capitalize(contributionFreqStr),
+		FinalBalance:       finalBalance,
+		YearResults:        yearResults,
+		Backtest: &BacktestResults{
+			From:           first.Date,
+			To:             last.Date,
+			DRIP:           drip,
+			CAGR:           cagr,
+			MaxDrawdown:    maxDrawdown,
+			TotalDividends: totalDividends,
+			YieldOnCost:    yieldOnCost,
+			Cash:           cash,
+		},
+	}
+
+	if tax != nil {
+		results.AccountType = strings.ToUpper(tax.Account)
+		results.TotalTaxDrag = Ptr(totalTax)
+		results.AfterTaxBalance = Ptr(tax.AfterTax(finalBalance, costBasis))
+	}
+
+	return results, nil
+}
@@ -1,97 +1,97 @@
 package helpers
 
 import (
-	"bytes"
-	"compress/gzip"
-	"encoding/base64"
-	"encoding/json"
 	"fmt"
 	"math"
-	"strconv"
 	"strings"
 	"time"
 
 	"github.com/labstack/gommon/log"
 )
 
-// MonthCalcResults stores monthly calculations
+// MonthCalcResults stores monthly calculations, amounts are in the currency of the results and percentages in percent.
+// Amounts that do not apply to the projection are nil.
 type MonthCalcResults struct {
-	MonthName                  string `json:"monthName"`
-	ShareAmount                string `json:"shareAmount"`
-	Contributions              string `json:"contributions"`
-	MonthlyGainedFromPriceInc  string `json:"monthlyGainedFromPriceIncrease"`
-	MonthlyGainedFromDividends string `json:"monthlyGainedFromDividends"`
-	MonthlyGain                string `json:"monthlyGain"`
-	CumGain                    string `json:"cumGain"`
-	Balance                    string `json:"balance"`
-	Return                     string `json:"return"`
-	DRIP                       string `json:"drip"`
+	MonthName                  string   `json:"monthName"`
+	ShareAmount                *float64 `json:"shareAmount"` // Nil without shares
+	Contributions              float64  `json:"contributions"`
+	MonthlyGainedFromPriceInc  float64  `json:"monthlyGainedFromPriceIncrease"`
+	MonthlyGainedFromDividends *float64 `json:"monthlyGainedFromDividends"` // Nil for a savings account
+	MonthlyGain                float64  `json:"monthlyGain"`
+	CumGain                    float64  `json:"cumGain"`
+	Balance                    float64  `json:"balance"`
+	Return                     float64  `json:"return"`
+	DRIPShares                 *float64 `json:"dripShares"`           // Shares bought by the dividends of the month, nil without dividends
+	Withdrawal                 *float64 `json:"withdrawal,omitempty"` // Amount withdrawn in the month (withdrawal phase)
+
+	// Values in today's money (if adjusted for inflation)
+	RealContributions              *float64 `json:"realContributions,omitempty"`
+	RealMonthlyGainedFromPriceInc  *float64 `json:"realMonthlyGainedFromPriceIncrease,omitempty"`
+	RealMonthlyGainedFromDividends *float64 `json:"realMonthlyGainedFromDividends,omitempty"`
+	RealMonthlyGain                *float64 `json:"realMonthlyGain,omitempty"`
+	RealCumGain                    *float64 `json:"realCumGain,omitempty"`
+	RealBalance                    *float64 `json:"realBalance,omitempty"`
+	RealWithdrawal                 *float64 `json:"realWithdrawal,omitempty"`
 }
 
 // YearCalcResults stores yearly calculations
 type YearCalcResults struct {
 	YearName       string             `json:"yearName"`
-	ShareAmount    string             `json:"shareAmount"`
-	TotalYearGains string             `json:"totalYearGains"`
-	CumGain        string             `json:"cumGain"`
-	YoyGrowth      string             `json:"yoyGrowth"`
-	TotalGrowth    string             `json:"totalGrowth"`
-	Balance        string             `json:"balance"`
+	ShareAmount    *float64           `json:"shareAmount"`
+	TotalYearGains float64            `json:"totalYearGains"`
+	CumGain        float64            `json:"cumGain"`
+	YoyGrowth      float64            `json:"yoyGrowth"`
+	TotalGrowth    float64            `json:"totalGrowth"`
+	Balance        float64            `json:"balance"`
+	TaxDrag        *float64           `json:"taxDrag,omitempty"`   // Taxes paid on the dividends of the year (if taxed)
+	Withdrawn      *float64           `json:"withdrawn,omitempty"` // Amount withdrawn in the year (withdrawal phase)
 	MonthsResults  []MonthCalcResults `json:"monthsResults"`
+
+	// Values in today's money (if adjusted for inflation)
+	RealTotalYearGains *float64 `json:"realTotalYearGains,omitempty"`
+	RealCumGain        *float64 `json:"realCumGain,omitempty"`
+	RealBalance        *float64 `json:"realBalance,omitempty"`
+	RealTaxDrag        *float64 `json:"realTaxDrag,omitempty"`
+	RealWithdrawn      *float64 `json:"realWithdrawn,omitempty"`
 }
 
-// CalculationResults stores the final output
+// CalculationResults stores the final output, it is formatted by the views and reports
 type CalculationResults struct {
 	SID                string            `json:"sid"`
-	Price              string            `json:"price"`
-	Principal          string            `json:"principal"`
-	Rate               string            `json:"rate"`
+	Price              *float64          `json:"price"` // Nil without a security
+	Principal          float64           `json:"principal"`
+	Contribution       float64           `json:"contribution"`
+	Rate               float64           `json:"rate"`
 	RateFreq           string            `json:"rateFreq"`
 	Currency           string            `json:"currency"`
-	Profit             string            `json:"profit"`
-	TotalContributions string            `json:"totalContributions"`
+	Profit             float64           `json:"profit"`
+	TotalContributions float64           `json:"totalContributions"`
 	ContribFreq        string            `json:"contribFreq"`
-	FinalBalance       string            `json:"finalBalance"`
+	FinalBalance       float64           `json:"finalBalance"`
 	YearResults        []YearCalcResults `json:"yearResults"`
-}
-
-func DecodeResults(encoded string) (CalculationResults, error) {
-	decoded, err := base64.StdEncoding.DecodeString(encoded)
-	if err != nil {
-		return CalculationResults{}, err
-	}
-
-	gzipped, err := gzip.NewReader(bytes.NewReader(decoded))
-	if err != nil {
-		return CalculationResults{}, err
-	}
-
-	gzipped.Close()
-
-	var results CalculationResults
-	if err := json.NewDecoder(gzipped).Decode(&results); err != nil {
-		return CalculationResults{}, err
-	}
-
-	return results, nil
-}
-
-func (cr CalculationResults) Encoded() (string, error) {
-	jsonBytes, err := json.Marshal(cr)
-	if err != nil {
-		return "", err
-	}
 
-	var gzipped bytes.Buffer
-	gz := gzip.NewWriter(&gzipped)
-	if _, err := gz.Write(jsonBytes); err != nil {
-		return "", err
-	}
-	gz.Close()
-
-	encoded := base64.StdEncoding.EncodeToString(gzipped.Bytes())
-
-	return encoded, nil
+	Simulation *SimulationResults `json:"simulation,omitempty"` // Monte Carlo spread (if requested)
+	Withdrawal *WithdrawalResults `json:"withdrawal,omitempty"` // Withdrawal phase (if planned), its years follow the accumulation in YearResults
+	Goal       *GoalResults       `json:"goal,omitempty"`       // Goal the inputs were solved for (if any)
+	Backtest   *BacktestResults   `json:"backtest,omitempty"`   // History the plan was replayed on (if backtested)
+
+	// Set when a tax profile is given
+	AccountType     string   `json:"accountType,omitempty"`
+	TotalTaxDrag    *float64 `json:"totalTaxDrag,omitempty"`
+	AfterTaxBalance *float64 `json:"afterTaxBalance,omitempty"`
+
+	// Set when adjusted for inflation, the real values are in today's money
+	Inflation              *float64 `json:"inflation,omitempty"`
+	RealFinalBalance       *float64 `json:"realFinalBalance,omitempty"`
+	RealProfit             *float64 `json:"realProfit,omitempty"`
+	RealTotalContributions *float64 `json:"realTotalContributions,omitempty"`
+	RealTotalTaxDrag       *float64 `json:"realTotalTaxDrag,omitempty"`
+	RealAfterTaxBalance    *float64 `json:"realAfterTaxBalance,omitempty"`
+	RealValues             bool     `json:"-"` // The nominal values were replaced by the real ones for a report
+
+	// Set for a portfolio
+	Holdings  []HoldingResults `json:"holdings,omitempty"`
+	Rebalance string           `json:"rebalance,omitempty"`
 }
 
 func frequencyToMonths(freq string) int {
@@ -126,7 +126,11 @@ func compoundingPeriodsPerYear(freq string) int {
 	}
 }
 
-func CalculateHISAInvestment(principal, contribution float64, contributionFreqStr, compoundingFreqStr string, annualInterestRate float64, compoundingYears int, currency string) (CalculationResults, error) {
+func CalculateHISAInvestment(principal, contribution float64, contributionFreqStr, compoundingFreqStr string, annualInterestRate float64, compoundingYears int, currency string, withdrawal *WithdrawalPlan) (CalculationResults, error) {
+	if err := ValidateCurrency(currency); err != nil {
+		return CalculationResults{}, err
+	}
+
 	// Convert frequency strings to integers
 	contributionFreq := frequencyToMonths(contributionFreqStr)
 	n := float64(compoundingPeriodsPerYear(compoundingFreqStr))
@@ -153,6 +157,8 @@ func CalculateHISAInvestment(principal, contribution float64, contributionFreqSt
 			startMonth = 1
 		}
 
+		totalYearGains := 0.0
+
 		// Loop through each month, starting from `startMonth`
 		for month := startMonth; month <= 12; month++ {
 
@@ -172,68 +178,31 @@ func CalculateHISAInvestment(principal, contribution float64, contributionFreqSt
 			cumGain += interestEarned
 
 			monthlyGain := interestEarned
+			totalYearGains += monthlyGain
 
 			returnPercent := 0.0
 			if balanceBeginning > 0 {
 				returnPercent = (balance - balanceBeginning) / balanceBeginning * 100
 			}
 
-			formattedTotalContributions, err := FormatPrice(totalContributions, currency)
-			if err != nil {
-				return CalculationResults{}, err
-			}
-
-			formattedMonthlyGainedFromPriceIncrease, err := FormatPrice(interestEarned, currency)
-			if err != nil {
-				return CalculationResults{}, err
-			}
-
-			formattedMonthlyGain, err := FormatPrice(monthlyGain, currency)
-			if err != nil {
-				return CalculationResults{}, err
-			}
-			formattedTotalCumGain, err := FormatPrice(cumGain, currency)
-			if err != nil {
-				return CalculationResults{}, err
-			}
-			formattedBalance, err := FormatPrice(balance, currency)
-			if err != nil {
-				return CalculationResults{}, err
-			}
-
 			monthResults = append(monthResults, MonthCalcResults{
-				MonthName:                  monthName,
-				ShareAmount:                "N/A",
-				Contributions:              formattedTotalContributions,
-				MonthlyGainedFromPriceInc:  formattedMonthlyGainedFromPriceIncrease,
-				MonthlyGainedFromDividends: "N/A",
-				MonthlyGain:                formattedMonthlyGain,
-				CumGain:                    formattedTotalCumGain,
-				Balance:                    formattedBalance,
-				Return:                     fmt.Sprintf("%.2f%%", returnPercent),
-				DRIP:                       "N/A",
+				MonthName:                 monthName,
+				Contributions:             totalContributions,
+				MonthlyGainedFromPriceInc: interestEarned,
+				MonthlyGain:               monthlyGain,
+				CumGain:                   cumGain,
+				Balance:                   balance,
+				Return:                    returnPercent,
 			})
 
 		}
 
-		totalYearGains := 0.0
-		for _, monthResult := range monthResults {
-			mg, err := parseFloat(strings.ReplaceAll(monthResult.MonthlyGain[4:], ",", ""))
-			if err != nil {
-				return CalculationResults{}, err
-			}
-			totalYearGains += mg
-		}
-
 		currentYearBalance := balance
 
 		yoyGrowth := 0.0
 
 		if year > 1 {
-			prevBalance, err := parseFloat(strings.ReplaceAll(yearResults[year-2].Balance[4:], ",", ""))
-			if err != nil {
-				return CalculationResults{}, err
-			}
+			prevBalance := yearResults[year-2].Balance
 			yoyGrowth = ((currentYearBalance - prevBalance) / prevBalance) * 100
 		} else {
 			prevBalance := totalContributions
@@ -243,30 +212,14 @@ func CalculateHISAInvestment(principal, contribution float64, contributionFreqSt
 
 		totalGrowth := (currentYearBalance - totalContributions) / totalContributions * 100
 
-		formattedYearGains, err := FormatPrice(totalYearGains, currency)
-		if err != nil {
-			return CalculationResults{}, err
-		}
-
-		formattedCumGain, err := FormatPrice(cumGain, currency)
-		if err != nil {
-			return CalculationResults{}, err
-		}
-
-		formattedBalance, err := FormatPrice(currentYearBalance, currency)
-		if err != nil {
-			return CalculationResults{}, err
-		}
-
 		// Store yearly results
 		yearResults = append(yearResults, YearCalcResults{
 			YearName:       fmt.Sprintf("Year (%d) - %d", year, currentYear+year-1),
-			ShareAmount:    "N/A",
-			TotalYearGains: formattedYearGains,
-			CumGain:        formattedCumGain,
-			YoyGrowth:      fmt.Sprintf("%.2f%%", yoyGrowth),
-			TotalGrowth:    fmt.Sprintf("%.2f%%", totalGrowth),
-			Balance:        formattedBalance,
+			TotalYearGains: totalYearGains,
+			CumGain:        cumGain,
+			YoyGrowth:      yoyGrowth,
+			TotalGrowth:    totalGrowth,
+			Balance:        currentYearBalance,
 			MonthsResults:  monthResults,
 		})
 
@@ -274,45 +227,47 @@ func CalculateHISAInvestment(principal, contribution float64, contributionFreqSt
 
 	finalBalance := balance
 
-	formattedTotalContributions, err := FormatPrice(totalContributions, currency)
-	if err != nil {
-		return CalculationResults{}, err
+	results := CalculationResults{
+		Principal:          principal,
+		Contribution:       contribution,
+		Rate:               annualInterestRate,
+		RateFreq:           Capitalize(compoundingFreqStr),
+		Currency:           currency,
+		Profit:             finalBalance - totalContributions,
+		TotalContributions: totalContributions,
+		ContribFreq:        Capitalize(contributionFreqStr),
+		FinalBalance:       finalBalance,
+		YearResults:        yearResults,
 	}
 
-	formattedFinalBalance, err := FormatPrice(finalBalance, currency)
-	if err != nil {
-		return CalculationResults{}, err
-	}
+	if withdrawal != nil {
+		// The interest is the income of the account
+		model := func() growthModel {
+			return func(balance float64, month int) (float64, float64, float64) {
+				return balance * (monthlyInterestfactor - 1), 1, 0
+			}
+		}
 
-	formattedProfit, err := FormatPrice(finalBalance-totalContributions, currency)
-	if err != nil {
-		return CalculationResults{}, err
-	}
+		withdrawalYears, withdrawalResults := withdrawal.drawdown(accumulation{
+			Balance:       finalBalance,
+			Contributions: totalContributions,
+			CumGain:       cumGain,
+			Years:         compoundingYears,
+		}, model)
 
-	formattedPrincial, err := FormatPrice(principal, currency)
-	if err != nil {
-		return CalculationResults{}, err
+		results.YearResults = append(results.YearResults, withdrawalYears...)
+		results.Withdrawal = withdrawalResults
 	}
 
 	// Return final results
-	return CalculationResults{
-		Principal:          formattedPrincial,
-		Rate:               fmt.Sprintf("%.2f%%", annualInterestRate),
-		RateFreq:           Capitalize(compoundingFreqStr),
-		Currency:           currency,
-		Profit:             formattedProfit,
-		TotalContributions: formattedTotalContributions,
-		ContribFreq:        Capitalize(contributionFreqStr),
-		FinalBalance:       formattedFinalBalance,
-		YearResults:        yearResults,
-	}, nil
+	return results, nil
 }
 
 // Function to calculate investment results
 func CalculateInvestment(sid string,
 	stockPrice, dividendYield, expenseRatio, principal, contribution float64,
 	contributionFreqStr, dividendFreqStr string, annualPriceIncreasePercent, annualDividendIncreasePercent float64,
-	compoundingYears int, payoutMonth int, currency string,
+	compoundingYears int, payoutMonth int, currency string, tax *TaxProfile, sourceCountry string, withdrawal *WithdrawalPlan,
 ) (CalculationResults, error) {
 
 	// Log input parameters
@@ -328,11 +283,13 @@ func CalculateInvestment(sid string,
 	log.Debugf("compoundingYears: %d", compoundingYears)
 	log.Debugf("payoutMonth: %d", payoutMonth)
 	log.Debugf("currency: %s", currency)
+	log.Debugf("tax: %+v", tax)
+	log.Debugf("sourceCountry: %s", sourceCountry)
 
-	formattedPrice, err := FormatPrice(stockPrice, currency)
-	if err != nil {
+	if err := ValidateCurrency(currency); err != nil {
 		return CalculationResults{}, err
 	}
+	initialPrice := stockPrice
 
 	// ✅ Convert string frequency inputs to numeric values
 	contributionFrequency := frequencyToMonths(contributionFreqStr)
@@ -360,6 +317,15 @@ func CalculateInvestment(sid string,
 	log.Debugf("D0: %f", D0)
 	totalMonth := 0
 
+	// Share of every dividend lost to withholding and income taxes
+	dividendTax := 0.0
+	if tax != nil {
+		dividendTax = tax.DividendTax(sourceCountry) / 100
+	}
+	log.Debugf("dividendTax: %f", dividendTax)
+	totalTax := 0.0
+	costBasis := principal
+
 	currentYear := time.Now().Year()
 
 	yearResults := []YearCalcResults{}
@@ -373,6 +339,8 @@ func CalculateInvestment(sid string,
 		log.Debugf("Year %d dividendPerPeriod: %f", year, dividendPerPeriod)
 
 		monthResults := []MonthCalcResults{}
+		yearTax := 0.0
+		totalYearGains := 0.0
 
 		var startMonth int
 		if year == 1 {
@@ -405,12 +373,17 @@ func CalculateInvestment(sid string,
 				sharesBought := contribution / stockPrice
 				shares += sharesBought
 				totalContributions += contributionThisMonth
+				costBasis += contributionThisMonth
 			}
 
 			dividendReceived := 0.0
 			sharesBoughtDividend := 0.0
 			if dividendYield > 0 && (totalMonth-1)%dividendFrequency == 0 {
-				dividendReceived = shares * dividendPerPeriod
+				dividendGross := shares * dividendPerPeriod
+				dividendTaxed := dividendGross * dividendTax
+				yearTax += dividendTaxed
+				dividendReceived = dividendGross - dividendTaxed
+				costBasis += dividendReceived
 				log.Debugf("Year %d Month %s dividendReceived: %f", year, monthName, dividendReceived)
 				sharesBoughtDividend = dividendReceived / stockPriceBegin
 				log.Debugf("Year %d Month %s sharesBoughtDividend: %f", year, monthName, sharesBoughtDividend)
@@ -441,82 +414,34 @@ func CalculateInvestment(sid string,
 				returnPercent = (balanceEnd - balanceBeginning) / balanceBeginning * 100
 			}
 
-			// Calculate DRIP (Dividend Reinvestment)
-
-			DRIPStatus := "N/A"
+			totalYearGains += monthlyGains
 
+			// Shares bought by the dividends (DRIP)
+			var dripShares *float64
 			if dividendYield > 0 {
-				if sharesBoughtDividend >= 1 && sharesBoughtDividend < 2 {
-					DRIPStatus = "DRIP"
-				} else if sharesBoughtDividend >= 2 {
-					DRIPStatus = "DRIPx" + fmt.Sprintf("%.0f", sharesBoughtDividend)
-				} else {
-					DRIPStatus = "NO DRIP"
-				}
-			}
-
-			// Store month results if it's a contribution or dividend month
-
-			formattedTotalContributions, err := FormatPrice(totalContributions, currency)
-			if err != nil {
-				return CalculationResults{}, err
-			}
-
-			formattedMonthlyGainedFromPriceIncrease, err := FormatPrice(monthlyGainsFromPriceIncrease, currency)
-			if err != nil {
-				return CalculationResults{}, err
-			}
-			formattedMonthlyGainedFromDividends, err := FormatPrice(monthlyGainsFromDividends, currency)
-			if err != nil {
-				return CalculationResults{}, err
-			}
-			formattedMonthlyGain, err := FormatPrice(monthlyGains, currency)
-			if err != nil {
-				return CalculationResults{}, err
-			}
-			formattedTotalCumGain, err := FormatPrice(cumGain, currency)
-			if err != nil {
-				return CalculationResults{}, err
-			}
-			formattedBalance, err := FormatPrice(balanceEnd, currency)
-			if err != nil {
-				return CalculationResults{}, err
+				dripShares = Ptr(sharesBoughtDividend)
 			}
 
 			monthResults = append(monthResults, MonthCalcResults{
 				MonthName:                  monthName,
-				ShareAmount:                fmt.Sprintf("%.2f", shares),
-				Contributions:              formattedTotalContributions,
-				MonthlyGainedFromPriceInc:  formattedMonthlyGainedFromPriceIncrease,
-				MonthlyGainedFromDividends: formattedMonthlyGainedFromDividends,
-				MonthlyGain:                formattedMonthlyGain,
-				CumGain:                    formattedTotalCumGain,
-				Balance:                    formattedBalance,
-				Return:                     fmt.Sprintf("%.2f%%", returnPercent),
-				DRIP:                       DRIPStatus,
+				ShareAmount:                Ptr(shares),
+				Contributions:              totalContributions,
+				MonthlyGainedFromPriceInc:  monthlyGainsFromPriceIncrease,
+				MonthlyGainedFromDividends: Ptr(monthlyGainsFromDividends),
+				MonthlyGain:                monthlyGains,
+				CumGain:                    cumGain,
+				Balance:                    balanceEnd,
+				Return:                     returnPercent,
+				DRIPShares:                 dripShares,
 			})
 
 		}
 
-		// Yearly metrics
-		totalYearGains := 0.0
-
-		for _, monthResult := range monthResults {
-			mg, err := parseFloat(strings.ReplaceAll(monthResult.MonthlyGain[4:], ",", ""))
-			if err != nil {
-				return CalculationResults{}, err
-			}
-			totalYearGains += mg
-		}
-
 		currentYearBalance := shares * stockPrice
 
 		yoyGrowth := 0.0
 		if year > 1 {
-			prevBalance, err := parseFloat(strings.ReplaceAll(yearResults[year-2].Balance[4:], ",", ""))
-			if err != nil {
-				return CalculationResults{}, err
-			}
+			prevBalance := yearResults[year-2].Balance
 			yoyGrowth = ((currentYearBalance - prevBalance) / prevBalance) * 100
 		} else {
 			prevBalance := totalContributions
@@ -526,76 +451,76 @@ func CalculateInvestment(sid string,
 
 		totalGrowth := (currentYearBalance - totalContributions) / totalContributions * 100
 
-		formattedYearGains, err := FormatPrice(totalYearGains, currency)
-		if err != nil {
-			return CalculationResults{}, err
-		}
-
-		formattedCumGain, err := FormatPrice(cumGain, currency)
-		if err != nil {
-			return CalculationResults{}, err
-		}
-
-		formattedBalance, err := FormatPrice(currentYearBalance, currency)
-		if err != nil {
-			return CalculationResults{}, err
+		totalTax += yearTax
+		var taxDrag *float64
+		if tax != nil {
+			taxDrag = Ptr(yearTax)
 		}
 
 		// Store yearly results
 		yearResults = append(yearResults, YearCalcResults{
 			YearName:       fmt.Sprintf("Year (%d) - %d", year, currentYear+year-1),
-			ShareAmount:    fmt.Sprintf("%.2f", shares),
-			TotalYearGains: formattedYearGains,
-			CumGain:        formattedCumGain,
-			YoyGrowth:      fmt.Sprintf("%.2f%%", yoyGrowth),
-			TotalGrowth:    fmt.Sprintf("%.2f%%", totalGrowth),
-			Balance:        formattedBalance,
+			ShareAmount:    Ptr(shares),
+			TotalYearGains: totalYearGains,
+			CumGain:        cumGain,
+			YoyGrowth:      yoyGrowth,
+			TotalGrowth:    totalGrowth,
+			Balance:        currentYearBalance,
+			TaxDrag:        taxDrag,
 			MonthsResults:  monthResults,
 		})
 	}
 
-	formattedPrincial, err := FormatPrice(principal, currency)
-	if err != nil {
-		return CalculationResults{}, err
-	}
-
-	formattedTotalContributions, err := FormatPrice(totalContributions, currency)
-	if err != nil {
-		return CalculationResults{}, err
-	}
-
 	finalBalance := shares * stockPrice
 
-	formattedFinalBalance, err := FormatPrice(finalBalance, currency)
-	if err != nil {
-		return CalculationResults{}, err
-	}
-
-	formattedProfit, err := FormatPrice(finalBalance-totalContributions, currency)
-	if err != nil {
-		return CalculationResults{}, err
-	}
-
-	// Return final results
-	return CalculationResults{
+	results := CalculationResults{
 		SID:                sid,
-		Principal:          formattedPrincial,
-		Price:              formattedPrice,
-		Rate:               fmt.Sprintf("%.2f%%", dividendYield-expenseRatio),
+		Principal:          principal,
+		Contribution:       contribution,
+		Price:              Ptr(initialPrice),
+		Rate:               dividendYield - expenseRatio,
 		RateFreq:           Capitalize(dividendFreqStr),
 		Currency:           currency,
-		Profit:             formattedProfit,
-		TotalContributions: formattedTotalContributions,
+		Profit:             finalBalance - totalContributions,
+		TotalContributions: totalContributions,
 		ContribFreq:        Capitalize(contributionFreqStr),
-		FinalBalance:       formattedFinalBalance,
+		FinalBalance:       finalBalance,
 		YearResults:        yearResults,
-	}, nil
-}
+	}
 
-func parseFloat(s string) (float64, error) {
-	f, err := strconv.ParseFloat(s, 64)
-	if err != nil {
-		return 0, err
+	if tax != nil {
+		results.AccountType = strings.ToUpper(tax.Account)
+		results.TotalTaxDrag = Ptr(totalTax)
+		results.AfterTaxBalance = Ptr(tax.AfterTax(finalBalance, costBasis))
 	}
-	return f, nil
+
+	if withdrawal != nil {
+		// Dividends keep growing with the price from where the accumulation left off
+		model := func() growthModel {
+			price := stockPrice
+			return func(balance float64, month int) (float64, float64, float64) {
+				current := price
+				income := 0.0
+				if dividendYield > 0 && (totalMonth+month-1)%dividendFrequency == 0 {
+					year := compoundingYears + (month-1)/12
+					income = balance / price * D0 * math.Pow(1+annualDividendIncrease, float64(year)) / float64(mDiv) * (1 - dividendTax)
+				}
+				price *= monthlyPriceGrowth
+				return income, monthlyPriceGrowth, current
+			}
+		}
+
+		withdrawalYears, withdrawalResults := withdrawal.drawdown(accumulation{
+			Balance:       finalBalance,
+			Contributions: totalContributions,
+			CumGain:       cumGain,
+			Years:         compoundingYears,
+		}, model)
+
+		results.YearResults = append(results.YearResults, withdrawalYears...)
+		results.Withdrawal = withdrawalResults
+	}
+
+	// Return final results
+	return results, nil
 }
@@ -0,0 +1,269 @@
+package helpers
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Withdrawal strategies of the decumulation phase
+const (
+	FixedWithdrawal    = "fixed"     // A monthly amount in today's money
+	PercentWithdrawal  = "percent"   // A share of the balance at the start of the withdrawals, such as the 4% rule
+	DividendWithdrawal = "dividends" // The income of the investment only, nothing is sold
+)
+
+const MaxWithdrawalYears = 60
+
+var WithdrawalStrategies = []string{FixedWithdrawal, PercentWithdrawal, DividendWithdrawal}
+
+// WithdrawalPlan describes the withdrawal phase that follows the years of accumulation, rates are in percent
+type WithdrawalPlan struct {
+	Strategy  string
+	Amount    float64 // Monthly withdrawal in today's money for a fixed strategy
+	Rate      float64 // Yearly share of the starting balance for a percent strategy
+	Inflation float64 // Yearly indexation of the withdrawals
+	Years     int
+}
+
+// WithdrawalResults summarizes the withdrawal phase of a projection
+type WithdrawalResults struct {
+	Strategy          string  `json:"strategy"`
+	Amount            float64 `json:"amount,omitempty"` // Monthly withdrawal in today's money for a fixed strategy
+	Rate              float64 `json:"rate,omitempty"`   // Yearly share of the starting balance for a percent strategy
+	Inflation         float64 `json:"inflation"`
+	Years             int     `json:"years"` // Years of withdrawals in the year results, fewer than planned when the balance runs out
+	StartBalance      float64 `json:"startBalance"`
+	FirstYearIncome   float64 `json:"firstYearIncome"`
+	SustainableIncome float64 `json:"sustainableIncome"` // Monthly, in today's money
+	TotalWithdrawn    float64 `json:"totalWithdrawn"`
+	DepletionYear     int     `json:"depletionYear,omitempty"` // Calendar year the balance runs out, 0 when it lasts
+	EndBalance        float64 `json:"endBalance"`
+}
+
+// NewWithdrawalPlan validates the withdrawal inputs of the calculator, nil when no strategy is given
+func NewWithdrawalPlan(strategy string, amount float64, rate float64, inflation float64, years int) (*WithdrawalPlan, error) {
+	strategy = strings.ToLower(strings.TrimSpace(strategy))
+	if strategy == "" || strategy == "none" {
+		return nil, nil
+	}
+
+	switch strategy {
+	case FixedWithdrawal:
+		if amount <= 0 {
+			return nil, fmt.Errorf("invalid withdrawal amount: %.2f, must be positive", amount)
+		}
+	case PercentWithdrawal:
+		if rate <= 0 || rate > 100 {
+			return nil, fmt.Errorf("invalid withdrawal rate: %.2f, must be between 0 and 100", rate)
+		}
+	case DividendWithdrawal:
+	default:
+		return nil, fmt.Errorf("invalid withdrawal strategy: %s, must be one of %s", strategy, strings.Join(WithdrawalStrategies, ", "))
+	}
+
+	if inflation < 0 || inflation > 100 {
+		return nil, fmt.Errorf("invalid inflation rate: %.2f, must be between 0 and 100", inflation)
+	}
+
+	if years < 1 || years > MaxWithdrawalYears {
+		return nil, fmt.Errorf("invalid withdrawal years: %d, must be between 1 and %d", years, MaxWithdrawalYears)
+	}
+
+	return &WithdrawalPlan{
+		Strategy:  strategy,
+		Amount:    amount,
+		Rate:      rate,
+		Inflation: inflation,
+		Years:     years,
+	}, nil
+}
+
+// growthModel moves an invested balance through a month of the withdrawal phase, month counting from 1.
+// It returns the income the balance paid, the growth factor of its price and the price of a share (0 without shares).
+type growthModel func(balance float64, month int) (income float64, growth float64, price float64)
+
+// accumulation is where the accumulation phase of a projection left off
+type accumulation struct {
+	Balance       float64
+	Contributions float64
+	CumGain       float64
+	Years         int
+}
+
+// indexation is the inflation factor of a month of withdrawals, the withdrawals are indexed once a year
+func (p *WithdrawalPlan) indexation(month int) float64 {
+	return math.Pow(1+p.Inflation/100, float64((month-1)/12))
+}
+
+// firstWithdrawal is the planned withdrawal of the first month, 0 when the income is withdrawn instead
+func (p *WithdrawalPlan) firstWithdrawal(start accumulation) float64 {
+	switch p.Strategy {
+	case FixedWithdrawal:
+		return p.Amount * math.Pow(1+p.Inflation/100, float64(start.Years))
+	case PercentWithdrawal:
+		return start.Balance * p.Rate / 100 / 12
+	default:
+		return 0
+	}
+}
+
+// remaining is the balance left at the end of the plan when first is withdrawn in the first month, negative when it runs out
+func (p *WithdrawalPlan) remaining(balance float64, model func() growthModel, first float64) float64 {
+	step := model()
+	for month := 1; month <= p.Years*12; month++ {
+		balance -= first * p.indexation(month)
+		if balance < 0 {
+			return balance
+		}
+		income, growth, _ := step(balance, month)
+		balance = (balance + income) * growth
+	}
+	return balance
+}
+
+// sustainableWithdrawal is the first monthly withdrawal that, indexed to inflation, lasts exactly the years of the plan
+func (p *WithdrawalPlan) sustainableWithdrawal(balance float64, model func() growthModel) float64 {
+	low, high := 0.0, balance
+	for i := 0; i < 100; i++ {
+		mid := (low + high) / 2
+		if p.remaining(balance, model, mid) > 0 {
+			low = mid
+		} else {
+			high = mid
+		}
+	}
+	return low
+}
+
+// drawdown runs the withdrawal phase month by month from where the accumulation left off.
+// Income not withdrawn is reinvested, the years stop at the depletion of the balance.
+func (p *WithdrawalPlan) drawdown(start accumulation, model func() growthModel) ([]YearCalcResults, *WithdrawalResults) {
+	planned := p.firstWithdrawal(start)
+
+	step := model()
+	balance := start.Balance
+	cumGain := start.CumGain
+	previousBalance := balance
+	totalWithdrawn := 0.0
+	firstYearIncome := 0.0
+	depletionYear := 0
+	month := 0
+
+	firstYear := time.Now().Year() + start.Years
+
+	yearResults := []YearCalcResults{}
+
+	for year := 1; year <= p.Years && depletionYear == 0; year++ {
+		monthResults := []MonthCalcResults{}
+		yearGains := 0.0
+		yearWithdrawn := 0.0
+
+		for calendarMonth := 1; calendarMonth <= 12 && depletionYear == 0; calendarMonth++ {
+			month++
+
+			withdrawal := planned * p.indexation(month)
+			if planned > 0 && withdrawal >= balance {
+				withdrawal = balance
+				depletionYear = firstYear + year - 1
+			}
+			balance -= withdrawal
+			invested := balance
+
+			income, growth, price := step(balance, month)
+			if p.Strategy == DividendWithdrawal {
+				withdrawal = income
+			} else {
+				balance += income
+			}
+
+			gainFromPrice := balance * (growth - 1)
+			balance *= growth
+
+			monthlyGain := income + gainFromPrice
+			cumGain += monthlyGain
+			yearGains += monthlyGain
+			yearWithdrawn += withdrawal
+
+			returnPercent := 0.0
+			if invested > 0 {
+				returnPercent = monthlyGain / invested * 100
+			}
+
+			var shareAmount *float64
+			if price > 0 {
+				shareAmount = Ptr(balance / (price * growth))
+			}
+
+			monthResults = append(monthResults, MonthCalcResults{
+				MonthName:                  time.Month(calendarMonth).String(),
+				ShareAmount:                shareAmount,
+				Contributions:              start.Contributions,
+				MonthlyGainedFromPriceInc:  gainFromPrice,
+				MonthlyGainedFromDividends: Ptr(income),
+				MonthlyGain:                monthlyGain,
+				CumGain:                    cumGain,
+				Balance:                    balance,
+				Return:                     returnPercent,
+				Withdrawal:                 Ptr(withdrawal),
+			})
+		}
+
+		totalWithdrawn += yearWithdrawn
+		if year == 1 {
+			firstYearIncome = yearWithdrawn
+		}
+
+		yoyGrowth := 0.0
+		if previousBalance > 0 {
+			yoyGrowth = (balance - previousBalance) / previousBalance * 100
+		}
+		previousBalance = balance
+
+		totalGrowth := 0.0
+		if start.Contributions > 0 {
+			totalGrowth = (balance - start.Contributions) / start.Contributions * 100
+		}
+
+		var shareAmount *float64
+		if len(monthResults) > 0 {
+			shareAmount = monthResults[len(monthResults)-1].ShareAmount
+		}
+
+		yearResults = append(yearResults, YearCalcResults{
+			YearName:       fmt.Sprintf("Withdrawal (%d) - %d", year, firstYear+year-1),
+			ShareAmount:    shareAmount,
+			TotalYearGains: yearGains,
+			CumGain:        cumGain,
+			YoyGrowth:      yoyGrowth,
+			TotalGrowth:    totalGrowth,
+			Balance:        balance,
+			Withdrawn:      Ptr(yearWithdrawn),
+			MonthsResults:  monthResults,
+		})
+	}
+
+	// The sustainable income is given in today's money to compare with a fixed withdrawal
+	sustainable := p.sustainableWithdrawal(start.Balance, model) / math.Pow(1+p.Inflation/100, float64(start.Years))
+
+	results := &WithdrawalResults{
+		Strategy:          p.Strategy,
+		Inflation:         p.Inflation,
+		Years:             len(yearResults),
+		StartBalance:      start.Balance,
+		FirstYearIncome:   firstYearIncome,
+		SustainableIncome: sustainable,
+		TotalWithdrawn:    totalWithdrawn,
+		DepletionYear:     depletionYear,
+		EndBalance:        balance,
+	}
+	switch p.Strategy {
+	case FixedWithdrawal:
+		results.Amount = p.Amount
+	case PercentWithdrawal:
+		results.Rate = p.Rate
+	}
+
+	return yearResults, results
+}
@@ -0,0 +1,134 @@
+package helpers
+
+import (
+	"testing"
+	"time"
+)
+
+// flat neither grows nor pays anything but the given monthly income
+func flat(income float64) func() growthModel {
+	return func() growthModel {
+		return func(balance float64, month int) (float64, float64, float64) {
+			return income, 1, 0
+		}
+	}
+}
+
+func TestDrawdown(t *testing.T) {
+	thisYear := time.Now().Year()
+
+	tests := []struct {
+		name        string
+		plan        WithdrawalPlan
+		start       accumulation
+		income      float64
+		years       int
+		withdrawn   float64
+		depletion   int
+		end         float64
+		sustainable float64
+	}{
+		{
+			name:        "fixed lasting the plan",
+			plan:        WithdrawalPlan{Strategy: FixedWithdrawal, Amount: 1000, Years: 10},
+			start:       accumulation{Balance: 200000},
+			years:       10,
+			withdrawn:   120000,
+			end:         80000,
+			sustainable: 200000.0 / 120,
+		},
+		{
+			name:        "fixed depleting the balance",
+			plan:        WithdrawalPlan{Strategy: FixedWithdrawal, Amount: 1000, Years: 10},
+			start:       accumulation{Balance: 60000},
+			years:       5,
+			withdrawn:   60000,
+			depletion:   thisYear + 4,
+			end:         0,
+			sustainable: 60000.0 / 120,
+		},
+		{
+			name:        "fixed indexed from today's money",
+			plan:        WithdrawalPlan{Strategy: FixedWithdrawal, Amount: 1000, Inflation: 10, Years: 2},
+			start:       accumulation{Balance: 100000, Years: 1},
+			years:       2,
+			withdrawn:   12*1100 + 12*1210,
+			end:         100000 - 12*1100 - 12*1210,
+			sustainable: 100000 / (12 + 12*1.1) / 1.1,
+		},
+		{
+			name:        "percent of the starting balance",
+			plan:        WithdrawalPlan{Strategy: PercentWithdrawal, Rate: 4, Years: 10},
+			start:       accumulation{Balance: 120000},
+			years:       10,
+			withdrawn:   48000,
+			end:         72000,
+			sustainable: 1000,
+		},
+		{
+			name:        "dividends only",
+			plan:        WithdrawalPlan{Strategy: DividendWithdrawal, Years: 3},
+			start:       accumulation{Balance: 50000},
+			income:      100,
+			years:       3,
+			withdrawn:   3600,
+			end:         50000,
+			sustainable: (50000 + 35*100) / 36.0, // The income of the last month is paid after its withdrawal
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			yearResults, results := test.plan.drawdown(test.start, flat(test.income))
+
+			if len(yearResults) != test.years || results.Years != test.years {
+				t.Fatalf("expected %d years of withdrawals, got %d and %d", test.years, len(yearResults), results.Years)
+			}
+			if results.DepletionYear != test.depletion {
+				t.Errorf("expected the balance to run out in %d, got %d", test.depletion, results.DepletionYear)
+			}
+			expectClose(t, "total withdrawn", results.TotalWithdrawn, test.withdrawn)
+			expectClose(t, "end balance", results.EndBalance, test.end)
+			expectClose(t, "last year balance", yearResults[len(yearResults)-1].Balance, test.end)
+
+			// The bisection stops within a cent of the exact withdrawal
+			if diff := results.SustainableIncome - test.sustainable; diff > 0.01 || diff < -0.01 {
+				t.Errorf("expected a sustainable income of %f, got %f", test.sustainable, results.SustainableIncome)
+			}
+		})
+	}
+}
+
+func TestNewWithdrawalPlan(t *testing.T) {
+	tests := []struct {
+		name      string
+		strategy  string
+		amount    float64
+		rate      float64
+		inflation float64
+		years     int
+		valid     bool
+		none      bool
+	}{
+		{name: "no strategy", strategy: "none", valid: true, none: true},
+		{name: "fixed", strategy: " Fixed ", amount: 2000, inflation: 2, years: 30, valid: true},
+		{name: "fixed without an amount", strategy: FixedWithdrawal, inflation: 2, years: 30},
+		{name: "percent above 100", strategy: PercentWithdrawal, rate: 120, years: 30},
+		{name: "dividends", strategy: DividendWithdrawal, years: 30, valid: true},
+		{name: "unknown strategy", strategy: "annuity", years: 30},
+		{name: "negative inflation", strategy: DividendWithdrawal, inflation: -1, years: 30},
+		{name: "too many years", strategy: DividendWithdrawal, years: MaxWithdrawalYears + 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			plan, err := NewWithdrawalPlan(test.strategy, test.amount, test.rate, test.inflation, test.years)
+			if (err == nil) != test.valid {
+				t.Fatalf("expected valid %v, got %v", test.valid, err)
+			}
+			if test.valid && (plan == nil) != test.none {
+				t.Errorf("expected no plan %v, got %+v", test.none, plan)
+			}
+		})
+	}
+}
@@ -24,6 +24,117 @@ func FormatPrice(price float64, curr string) (string, error) {
 	return p.Sprintf("%v", cur.Amount(price)), nil
 }
 
+// ValidateCurrency checks that curr is an ISO 4217 currency code
+func ValidateCurrency(curr string) error {
+	if _, err := currency.ParseISO(curr); err != nil {
+		return fmt.Errorf("failed to parse currency: %w", err)
+	}
+	return nil
+}
+
+// Locale picks the preferred language of an Accept-Language header, English when none is understood
+func Locale(acceptLanguage string) language.Tag {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return language.English
+	}
+	return tags[0]
+}
+
+// Formatter renders the values of calculation results in a locale
+type Formatter struct {
+	printer  *message.Printer
+	currency currency.Unit
+}
+
+func NewFormatter(locale language.Tag, curr string) (Formatter, error) {
+	cur, err := currency.ParseISO(curr)
+	if err != nil {
+		return Formatter{}, fmt.Errorf("failed to parse currency: %w", err)
+	}
+	return Formatter{printer: message.NewPrinter(locale), currency: cur}, nil
+}
+
+func (f Formatter) Amount(amount float64) string {
+	return f.printer.Sprintf("%v", f.currency.Amount(amount))
+}
+
+// MaybeAmount formats an amount that does not apply to every projection
+func (f Formatter) MaybeAmount(amount *float64) string {
+	if amount == nil {
+		return "N/A"
+	}
+	return f.Amount(*amount)
+}
+
+func (f Formatter) Percent(percent float64) string {
+	return f.printer.Sprintf("%.2f%%", percent)
+}
+
+func (f Formatter) Shares(shares *float64) string {
+	if shares == nil {
+		return "N/A"
+	}
+	return f.printer.Sprintf("%.2f", *shares)
+}
+
+// DRIP tells how many whole shares the dividends of a month bought
+func (f Formatter) DRIP(shares *float64) string {
+	if shares == nil {
+		return "N/A"
+	}
+	if *shares >= 2 {
+		return fmt.Sprintf("DRIPx%.0f", *shares)
+	}
+	if *shares >= 1 {
+		return "DRIP"
+	}
+	return "NO DRIP"
+}
+
+func (f Formatter) WithdrawalStrategy(withdrawal WithdrawalResults) string {
+	switch withdrawal.Strategy {
+	case FixedWithdrawal:
+		return fmt.Sprintf("Fixed %s / month", f.Amount(withdrawal.Amount))
+	case PercentWithdrawal:
+		return fmt.Sprintf("%s of Balance", f.Percent(withdrawal.Rate))
+	default:
+		return "Dividends Only"
+	}
+}
+
+// DepletionYear is the year the withdrawals run out of balance, if ever
+func (f Formatter) DepletionYear(withdrawal WithdrawalResults) string {
+	if withdrawal.DepletionYear == 0 {
+		return "Never"
+	}
+	return fmt.Sprintf("%d", withdrawal.DepletionYear)
+}
+
+func (f Formatter) Goal(goal GoalResults) string {
+	if goal.Goal == IncomeGoal {
+		return "Yearly Income"
+	}
+	return "Final Balance"
+}
+
+func (f Formatter) Solve(goal GoalResults) string {
+	return Capitalize(goal.Solve)
+}
+
+// Solution formats the solved input, an amount or a number of years
+func (f Formatter) Solution(goal GoalResults) string {
+	if goal.Solve == SolveYears {
+		return fmt.Sprintf("%.0f", goal.Solution)
+	}
+	return f.Amount(goal.Solution)
+}
+
+// Period is the span of history a plan was backtested on
+func (f Formatter) Period(backtest BacktestResults) string {
+	return fmt.Sprintf("%s to %s", backtest.From.Format("January 2006"), backtest.To.Format("January 2006"))
+}
+
 func ParseNumberString(input string) (int64, error) {
 	multipliers := map[string]float64{
 		"K": 1_000,
@@ -35,3 +35,8 @@ func SortSlice[T any](arr []T, less func(a, b T) bool) {
 		return less(arr[i], arr[j])
 	})
 }
+
+// Ptr is a pointer to a copy of v, for the optional fields of a struct literal
+func Ptr[T any](v T) *T {
+	return &v
+}
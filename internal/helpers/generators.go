@@ -1,8 +1,10 @@
 package helpers
 
 import (
+	"crypto/rand"
 	"crypto/sha256"
 	"encoding/binary"
+	"math/big"
 
 	"github.com/google/uuid"
 )
@@ -12,3 +14,19 @@ func GenerateUniqueID() uint {
 	hash := sha256.Sum256(u[:])
 	return uint(binary.BigEndian.Uint64(hash[:8]))
 }
+
+const shortIDAlphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// GenerateShortID returns a random URL-safe ID of the given length
+func GenerateShortID(length int) (string, error) {
+	id := make([]byte, length)
+	max := big.NewInt(int64(len(shortIDAlphabet)))
+	for i := range id {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		id[i] = shortIDAlphabet[n.Int64()]
+	}
+	return string(id), nil
+}
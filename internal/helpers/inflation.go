@@ -0,0 +1,163 @@
+package helpers
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// DefaultInflation is the long run inflation assumed for the currency of a projection, in percent,
+// close to the target of the central bank issuing it
+var DefaultInflation = map[string]float64{
+	"CAD": 2,
+	"USD": 2.5,
+	"EUR": 2,
+	"GBP": 2.5,
+	"CHF": 1,
+	"JPY": 1.5,
+	"AUD": 2.5,
+}
+
+const FallbackInflation = 2.0
+
+// InflationFor is the default inflation rate of a currency
+func InflationFor(currency string) float64 {
+	if rate, ok := DefaultInflation[strings.ToUpper(currency)]; ok {
+		return rate
+	}
+	return FallbackInflation
+}
+
+// realValue is a nominal amount and where its value in today's money goes
+type realValue struct {
+	nominal *float64
+	real    **float64
+}
+
+// deflateAll sets the real value of every amount deflated by the same factor, amounts that are not set are skipped
+func deflateAll(factor float64, values []realValue) {
+	for _, value := range values {
+		if value.nominal == nil {
+			continue
+		}
+		*value.real = Ptr(*value.nominal / factor)
+	}
+}
+
+// AdjustForInflation sets the real value, in today's money, of every amount of the results.
+// An amount is deflated from the end of its month, the months counting from the first of the projection.
+// Totals add up the real values of their parts, each contribution is deflated from the month it was made.
+func (cr *CalculationResults) AdjustForInflation(inflation float64) error {
+	if inflation < 0 || inflation > 100 {
+		return fmt.Errorf("invalid inflation rate: %.2f, must be between 0 and 100", inflation)
+	}
+
+	cr.Inflation = Ptr(inflation)
+
+	factorAt := func(months int) float64 {
+		return math.Pow(1+inflation/100, float64(months)/12)
+	}
+
+	months := 0
+	accumulationMonths := 0
+
+	// The principal is invested today, the contributions of every month are cumulative
+	contributed := cr.Principal
+	realContributions := cr.Principal
+	realTotalContributions := cr.Principal
+	realTotalTaxDrag := 0.0
+
+	for i := range cr.YearResults {
+		year := &cr.YearResults[i]
+		// The final balance is the one at the end of the accumulation
+		accumulating := cr.Withdrawal == nil || i < len(cr.YearResults)-cr.Withdrawal.Years
+
+		for j := range year.MonthsResults {
+			month := &year.MonthsResults[j]
+			months++
+
+			realContributions += (month.Contributions - contributed) / factorAt(months)
+			contributed = month.Contributions
+			month.RealContributions = Ptr(realContributions)
+
+			deflateAll(factorAt(months), []realValue{
+				{&month.MonthlyGainedFromPriceInc, &month.RealMonthlyGainedFromPriceInc},
+				{month.MonthlyGainedFromDividends, &month.RealMonthlyGainedFromDividends},
+				{&month.MonthlyGain, &month.RealMonthlyGain},
+				{&month.CumGain, &month.RealCumGain},
+				{&month.Balance, &month.RealBalance},
+				{month.Withdrawal, &month.RealWithdrawal},
+			})
+		}
+
+		deflateAll(factorAt(months), []realValue{
+			{&year.TotalYearGains, &year.RealTotalYearGains},
+			{&year.CumGain, &year.RealCumGain},
+			{&year.Balance, &year.RealBalance},
+			{year.TaxDrag, &year.RealTaxDrag},
+			{year.Withdrawn, &year.RealWithdrawn},
+		})
+
+		if accumulating {
+			accumulationMonths = months
+			realTotalContributions = realContributions
+			if year.RealTaxDrag != nil {
+				realTotalTaxDrag += *year.RealTaxDrag
+			}
+		}
+	}
+
+	deflateAll(factorAt(accumulationMonths), []realValue{
+		{&cr.FinalBalance, &cr.RealFinalBalance},
+		{cr.AfterTaxBalance, &cr.RealAfterTaxBalance},
+	})
+
+	cr.RealTotalContributions = Ptr(realTotalContributions)
+	cr.RealProfit = Ptr(*cr.RealFinalBalance - realTotalContributions)
+	if cr.TotalTaxDrag != nil {
+		cr.RealTotalTaxDrag = Ptr(realTotalTaxDrag)
+	}
+
+	return nil
+}
+
+// Real is a copy of the results with their real values in place of the nominal ones, for the reports
+func (cr CalculationResults) Real() CalculationResults {
+	if cr.Inflation == nil {
+		return cr
+	}
+
+	adjusted := cr
+	adjusted.RealValues = true
+	adjusted.FinalBalance = *cr.RealFinalBalance
+	adjusted.Profit = *cr.RealProfit
+	adjusted.TotalContributions = *cr.RealTotalContributions
+	adjusted.TotalTaxDrag = cr.RealTotalTaxDrag
+	adjusted.AfterTaxBalance = cr.RealAfterTaxBalance
+
+	adjusted.YearResults = make([]YearCalcResults, len(cr.YearResults))
+	for i, year := range cr.YearResults {
+		year.TotalYearGains = *year.RealTotalYearGains
+		year.CumGain = *year.RealCumGain
+		year.Balance = *year.RealBalance
+		year.TaxDrag = year.RealTaxDrag
+		year.Withdrawn = year.RealWithdrawn
+
+		months := make([]MonthCalcResults, len(year.MonthsResults))
+		for j, month := range year.MonthsResults {
+			month.Contributions = *month.RealContributions
+			month.MonthlyGainedFromPriceInc = *month.RealMonthlyGainedFromPriceInc
+			month.MonthlyGainedFromDividends = month.RealMonthlyGainedFromDividends
+			month.MonthlyGain = *month.RealMonthlyGain
+			month.CumGain = *month.RealCumGain
+			month.Balance = *month.RealBalance
+			month.Withdrawal = month.RealWithdrawal
+			months[j] = month
+		}
+		year.MonthsResults = months
+
+		adjusted.YearResults[i] = year
+	}
+
+	return adjusted
+}
@@ -0,0 +1,79 @@
+package helpers
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAdjustForInflationTotals(t *testing.T) {
+	// A year contributing 100 a month on top of a principal of 1000
+	months := make([]MonthCalcResults, 12)
+	for i := range months {
+		months[i] = MonthCalcResults{Contributions: 1000 + 100*float64(i+1), Balance: 1000 + 110*float64(i+1)}
+	}
+
+	results := CalculationResults{
+		Principal:          1000,
+		TotalContributions: 2200,
+		FinalBalance:       2500,
+		Profit:             300,
+		TotalTaxDrag:       Ptr(12.0),
+		YearResults:        []YearCalcResults{{Balance: 2500, TaxDrag: Ptr(12.0), MonthsResults: months}},
+	}
+
+	err := results.AdjustForInflation(12)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	contributions := 1000.0
+	for month := 1; month <= 12; month++ {
+		contributions += 100 / math.Pow(1.12, float64(month)/12)
+	}
+	final := 2500 / 1.12
+
+	expectClose(t, "real total contributions", *results.RealTotalContributions, contributions)
+	expectClose(t, "real final balance", *results.RealFinalBalance, final)
+	expectClose(t, "real profit", *results.RealProfit, final-contributions)
+	expectClose(t, "real total tax drag", *results.RealTotalTaxDrag, 12/1.12)
+	expectClose(t, "real contributions of the last month", *results.YearResults[0].MonthsResults[11].RealContributions, contributions)
+
+	reported := results.Real()
+	expectClose(t, "reported contributions", reported.TotalContributions, contributions)
+	expectClose(t, "reported profit", reported.Profit, reported.FinalBalance-reported.TotalContributions)
+	expectClose(t, "reported tax drag", *reported.TotalTaxDrag, 12/1.12)
+}
+
+func TestAdjustForInflationWithdrawal(t *testing.T) {
+	accumulation := []MonthCalcResults{{Contributions: 1000, Balance: 1100}}
+	decumulation := []MonthCalcResults{{Contributions: 1000, Balance: 900}}
+
+	results := CalculationResults{
+		Principal:          1000,
+		TotalContributions: 1000,
+		FinalBalance:       1100,
+		Profit:             100,
+		Withdrawal:         &WithdrawalResults{Years: 1},
+		YearResults: []YearCalcResults{
+			{Balance: 1100, MonthsResults: accumulation},
+			{Balance: 900, MonthsResults: decumulation},
+		},
+	}
+
+	err := results.AdjustForInflation(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The principal is not deflated, the balance is at the end of the accumulation only
+	expectClose(t, "real total contributions", *results.RealTotalContributions, 1000)
+	expectClose(t, "real profit", *results.RealProfit, 1100/math.Pow(1.1, 1.0/12)-1000)
+}
+
+func expectClose(t *testing.T, label string, actual float64, expected float64) {
+	t.Helper()
+
+	if math.Abs(actual-expected) > 1e-9 {
+		t.Errorf("%s: expected %f, got %f", label, expected, actual)
+	}
+}
@@ -0,0 +1,160 @@
+package helpers
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"time"
+)
+
+const (
+	DefaultSimulationPaths = 1000
+	MaxSimulationPaths     = 10000
+)
+
+// SimulationYearResults stores the spread of the simulated balances at the end of a year
+type SimulationYearResults struct {
+	YearName string  `json:"yearName"`
+	P10      float64 `json:"p10"`
+	P50      float64 `json:"p50"`
+	P90      float64 `json:"p90"`
+}
+
+// SimulationResults stores the outcome of the Monte Carlo mode of the investment calculator
+type SimulationResults struct {
+	Paths             int                     `json:"paths"`
+	Volatility        float64                 `json:"volatility"`
+	Target            *float64                `json:"target,omitempty"` // Nil without a target
+	TargetProbability *float64                `json:"targetProbability,omitempty"`
+	YearResults       []SimulationYearResults `json:"yearResults"`
+}
+
+// percentile of sorted values, interpolating between the closest ranks
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
+
+// SimulateInvestment runs the schedule of CalculateInvestment over many paths where the monthly price change is drawn
+// from a lognormal distribution centered on annualPriceIncreasePercent with the given annual volatility.
+// Dividends keep growing by annualDividendIncreasePercent on every path and are taxed like in CalculateInvestment,
+// a target of 0 skips its probability.
+func SimulateInvestment(
+	stockPrice, dividendYield, expenseRatio, principal, contribution float64,
+	contributionFreqStr, dividendFreqStr string, annualPriceIncreasePercent, annualDividendIncreasePercent, volatilityPercent float64,
+	compoundingYears int, payoutMonth int, paths int, target float64, currency string, tax *TaxProfile, sourceCountry string,
+) (*SimulationResults, error) {
+	if paths <= 0 {
+		paths = DefaultSimulationPaths
+	}
+	if paths > MaxSimulationPaths {
+		return nil, fmt.Errorf("too many simulation paths: %d, at most %d", paths, MaxSimulationPaths)
+	}
+	if err := ValidateCurrency(currency); err != nil {
+		return nil, err
+	}
+	if stockPrice <= 0 {
+		return nil, fmt.Errorf("cannot simulate a security without a price")
+	}
+
+	contributionFrequency := frequencyToMonths(contributionFreqStr)
+	dividendFrequency := frequencyToMonths(dividendFreqStr)
+
+	mDiv := 0
+	if dividendYield > 0 {
+		mDiv = 12 / dividendFrequency
+	}
+
+	// Monthly drift and volatility of the log price, the drift keeps the expected growth at annualPriceIncreasePercent
+	sigma := volatilityPercent / 100 / math.Sqrt(12)
+	drift := math.Log(1+annualPriceIncreasePercent/100)/12 - sigma*sigma/2
+
+	D0 := ((dividendYield - expenseRatio) / 100) * stockPrice
+	if tax != nil {
+		D0 *= 1 - tax.DividendTax(sourceCountry)/100
+	}
+
+	startMonth := payoutMonth
+	if startMonth == 0 {
+		startMonth = int(time.Now().Month()) + 1 // Default to next month like CalculateInvestment
+		if startMonth > 12 {
+			startMonth = 1
+		}
+	}
+
+	random := rand.New(rand.NewPCG(uint64(time.Now().UnixNano()), 0))
+
+	// balances[year][path]
+	balances := make([][]float64, compoundingYears)
+	for year := range balances {
+		balances[year] = make([]float64, paths)
+	}
+
+	for path := range paths {
+		price := stockPrice
+		shares := principal / stockPrice
+		totalMonth := 0
+
+		for year := 1; year <= compoundingYears; year++ {
+			dividendPerPeriod := 0.0
+			if mDiv > 0 {
+				dividendPerPeriod = D0 * math.Pow(1+annualDividendIncreasePercent/100, float64(year-1)) / float64(mDiv)
+			}
+
+			first := 1
+			if year == 1 {
+				first = startMonth
+			}
+
+			for month := first; month <= 12; month++ {
+				totalMonth++
+
+				if (totalMonth-1)%contributionFrequency == 0 {
+					shares += contribution / price
+				}
+
+				if mDiv > 0 && (totalMonth-1)%dividendFrequency == 0 {
+					shares += shares * dividendPerPeriod / price
+				}
+
+				price *= math.Exp(drift + sigma*random.NormFloat64())
+			}
+
+			balances[year-1][path] = shares * price
+		}
+	}
+
+	results := &SimulationResults{
+		Paths:      paths,
+		Volatility: volatilityPercent,
+	}
+
+	currentYear := time.Now().Year()
+	for year, yearBalances := range balances {
+		slices.Sort(yearBalances)
+
+		results.YearResults = append(results.YearResults, SimulationYearResults{
+			YearName: fmt.Sprintf("Year (%d) - %d", year+1, currentYear+year),
+			P10:      percentile(yearBalances, 10),
+			P50:      percentile(yearBalances, 50),
+			P90:      percentile(yearBalances, 90),
+		})
+	}
+
+	if target > 0 && compoundingYears > 0 {
+		final := balances[compoundingYears-1]
+		missed, _ := slices.BinarySearch(final, target)
+		reached := len(final) - missed
+
+		results.Target = Ptr(target)
+		results.TargetProbability = Ptr(float64(reached) / float64(len(final)) * 100)
+	}
+
+	return results, nil
+}
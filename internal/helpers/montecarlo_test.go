@@ -0,0 +1,103 @@
+package helpers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPercentile(t *testing.T) {
+	sorted := []float64{10, 20, 30, 40, 50}
+
+	tests := []struct {
+		name     string
+		sorted   []float64
+		p        float64
+		expected float64
+	}{
+		{name: "empty", sorted: []float64{}, p: 50, expected: 0},
+		{name: "single", sorted: []float64{42}, p: 90, expected: 42},
+		{name: "lowest", sorted: sorted, p: 0, expected: 10},
+		{name: "highest", sorted: sorted, p: 100, expected: 50},
+		{name: "median on a rank", sorted: sorted, p: 50, expected: 30},
+		{name: "interpolated between ranks", sorted: sorted, p: 10, expected: 14},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			expectClose(t, "percentile", percentile(test.sorted, test.p), test.expected)
+		})
+	}
+}
+
+func TestSimulateInvestmentSpread(t *testing.T) {
+	tests := []struct {
+		name       string
+		volatility float64
+		target     float64
+		// Probability of the target, negative when only its bounds are checked
+		probability float64
+	}{
+		{name: "volatile", volatility: 20, target: 10000, probability: -1},
+		{name: "no volatility collapses the spread", volatility: 0},
+		{name: "target below every path", volatility: 20, target: 1, probability: 100},
+		{name: "target above every path", volatility: 20, target: 1e12, probability: 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			results, err := SimulateInvestment(100, 3, 0, 5000, 200, "monthly", "quarterly", 6, 2, test.volatility, 10, 3, 500, test.target, "USD", nil, "US")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(results.YearResults) != 10 {
+				t.Fatalf("expected 10 years, got %d", len(results.YearResults))
+			}
+			for _, year := range results.YearResults {
+				if year.P10 > year.P50 || year.P50 > year.P90 {
+					t.Errorf("%s: expected p10 <= p50 <= p90, got %f, %f, %f", year.YearName, year.P10, year.P50, year.P90)
+				}
+				if test.volatility == 0 {
+					expectClose(t, year.YearName+" spread", year.P10, year.P90)
+				}
+			}
+
+			if test.target == 0 {
+				if results.TargetProbability != nil {
+					t.Errorf("expected no target probability, got %f", *results.TargetProbability)
+				}
+				return
+			}
+			probability := *results.TargetProbability
+			if probability < 0 || probability > 100 {
+				t.Errorf("expected a probability in percent, got %f", probability)
+			}
+			if test.probability >= 0 {
+				expectClose(t, "target probability", probability, test.probability)
+			}
+		})
+	}
+}
+
+func TestSimulateInvestmentValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		price    float64
+		paths    int
+		currency string
+		err      string
+	}{
+		{name: "too many paths", price: 100, paths: MaxSimulationPaths + 1, currency: "USD", err: "too many simulation paths"},
+		{name: "unknown currency", price: 100, paths: 10, currency: "XYZ1", err: "failed to parse currency"},
+		{name: "no price", price: 0, paths: 10, currency: "USD", err: "without a price"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := SimulateInvestment(test.price, 3, 0, 5000, 200, "monthly", "quarterly", 6, 2, 20, 5, 3, test.paths, 0, test.currency, nil, "US")
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("expected an error about %q, got %v", test.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,289 @@
+package helpers
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Holding is a security of a portfolio with the share of every contribution it receives
+type Holding struct {
+	SID          string
+	Weight       float64 // Target weight in percent, the weights of a portfolio add up to 100
+	Price        float64
+	Yield        float64
+	ExpenseRatio float64
+	Frequency    string
+	PayoutMonth  int
+	Country      string
+}
+
+// HoldingResults stores what a holding brought to the portfolio
+type HoldingResults struct {
+	SID          string  `json:"sid"`
+	Weight       float64 `json:"weight"`
+	Income       float64 `json:"income"`
+	IncomeShare  float64 `json:"incomeShare"`
+	FinalBalance float64 `json:"finalBalance"`
+}
+
+// rebalancingMonths is the number of months between two rebalancings, 0 to never rebalance
+func rebalancingMonths(freq string) (int, error) {
+	switch freq {
+	case "", "none":
+		return 0, nil
+	case "monthly", "quarterly", "semi-annual", "annual":
+		return frequencyToMonths(freq), nil
+	default:
+		return 0, fmt.Errorf("invalid rebalancing frequency: %s", freq)
+	}
+}
+
+// CalculatePortfolio projects the holdings side by side with the schedule of CalculateInvestment, splitting the principal
+// and every contribution by target weight. Every rebalancing brings the holdings back to their weights,
+// the taxes a sale would trigger on it are not accounted for.
+func CalculatePortfolio(holdings []Holding,
+	principal, contribution float64, contributionFreqStr string,
+	annualPriceIncreasePercent, annualDividendIncreasePercent float64,
+	compoundingYears int, rebalanceFreqStr string, currency string, tax *TaxProfile,
+) (CalculationResults, error) {
+	if len(holdings) == 0 {
+		return CalculationResults{}, fmt.Errorf("a portfolio needs at least one holding")
+	}
+
+	if err := ValidateCurrency(currency); err != nil {
+		return CalculationResults{}, err
+	}
+
+	rebalanceFrequency, err := rebalancingMonths(rebalanceFreqStr)
+	if err != nil {
+		return CalculationResults{}, err
+	}
+
+	contributionFrequency := frequencyToMonths(contributionFreqStr)
+	monthlyPriceGrowth := math.Pow(1+annualPriceIncreasePercent/100, 1.0/12.0)
+
+	type position struct {
+		Holding
+		price             float64
+		shares            float64
+		dividendFrequency int
+		annualDividend    float64 // Per share in the first year, net of the expense ratio
+		dividendTax       float64 // Share of every dividend lost to taxes
+		income            float64 // Dividends received after taxes
+	}
+
+	positions := make([]*position, len(holdings))
+	rate := 0.0
+	for i, holding := range holdings {
+		if holding.Price <= 0 {
+			return CalculationResults{}, fmt.Errorf("holding %s has no price", holding.SID)
+		}
+
+		p := &position{
+			Holding:           holding,
+			price:             holding.Price,
+			shares:            principal * holding.Weight / 100 / holding.Price,
+			dividendFrequency: frequencyToMonths(holding.Frequency),
+			annualDividend:    (holding.Yield - holding.ExpenseRatio) / 100 * holding.Price,
+		}
+		if tax != nil {
+			p.dividendTax = tax.DividendTax(holding.Country) / 100
+		}
+		positions[i] = p
+
+		rate += (holding.Yield - holding.ExpenseRatio) * holding.Weight / 100
+	}
+
+	value := func() float64 {
+		total := 0.0
+		for _, p := range positions {
+			total += p.shares * p.price
+		}
+		return total
+	}
+
+	// A holding pays in the months of its payout schedule, or every period from the first month when it is unknown
+	pays := func(p *position, month int, totalMonth int) bool {
+		if p.Yield <= 0 {
+			return false
+		}
+		if p.PayoutMonth == 0 {
+			return (totalMonth-1)%p.dividendFrequency == 0
+		}
+		return (month-p.PayoutMonth+12)%p.dividendFrequency == 0
+	}
+
+	totalContributions := principal
+	costBasis := principal
+	totalTax := 0.0
+	cumGain := 0.0
+	totalMonth := 0
+
+	startMonth := int(time.Now().Month()) + 1
+	if startMonth > 12 {
+		startMonth = 1
+	}
+
+	currentYear := time.Now().Year()
+	yearResults := []YearCalcResults{}
+
+	for year := 1; year <= compoundingYears; year++ {
+		growth := math.Pow(1+annualDividendIncreasePercent/100, float64(year-1))
+
+		monthResults := []MonthCalcResults{}
+		yearGains := 0.0
+		yearTax := 0.0
+
+		first := 1
+		if year == 1 {
+			first = startMonth
+		}
+
+		for month := first; month <= 12; month++ {
+			totalMonth++
+			balanceBeginning := value()
+
+			if (totalMonth-1)%contributionFrequency == 0 {
+				for _, p := range positions {
+					p.shares += contribution * p.Weight / 100 / p.price
+				}
+				totalContributions += contribution
+				costBasis += contribution
+			}
+
+			gainFromDividends := 0.0
+			gainFromPrice := 0.0
+			for _, p := range positions {
+				if pays(p, month, totalMonth) {
+					gross := p.shares * p.annualDividend * growth * float64(p.dividendFrequency) / 12
+					yearTax += gross * p.dividendTax
+					dividend := gross * (1 - p.dividendTax)
+					p.shares += dividend / p.price
+					p.income += dividend
+					gainFromDividends += dividend
+				}
+
+				before := p.shares * p.price
+				p.price *= monthlyPriceGrowth
+				gainFromPrice += p.shares*p.price - before
+			}
+			costBasis += gainFromDividends
+
+			if rebalanceFrequency > 0 && totalMonth%rebalanceFrequency == 0 {
+				total := value()
+				for _, p := range positions {
+					p.shares = total * p.Weight / 100 / p.price
+				}
+			}
+
+			balanceEnd := value()
+			monthlyGain := gainFromDividends + gainFromPrice
+			cumGain += monthlyGain
+			yearGains += monthlyGain
+
+			returnPercent := 0.0
+			if balanceBeginning > 0 {
+				returnPercent = (balanceEnd - balanceBeginning) / balanceBeginning * 100
+			}
+
+			monthResults = append(monthResults, MonthCalcResults{
+				MonthName:                  time.Month(month).String(),
+				Contributions:              totalContributions,
+				MonthlyGainedFromPriceInc:  gainFromPrice,
+				MonthlyGainedFromDividends: Ptr(gainFromDividends),
+				MonthlyGain:                monthlyGain,
+				CumGain:                    cumGain,
+				Balance:                    balanceEnd,
+				Return:                     returnPercent,
+			})
+		}
+
+		balance := value()
+
+		// The first year grows from everything put in during it, there may be no principal
+		prevBalance := totalContributions
+		if len(yearResults) > 0 {
+			prevBalance = yearResults[len(yearResults)-1].Balance
+		}
+		yoyGrowth := 0.0
+		if prevBalance > 0 {
+			yoyGrowth = (balance - prevBalance) / prevBalance * 100
+		}
+		totalGrowth := 0.0
+		if totalContributions > 0 {
+			totalGrowth = (balance - totalContributions) / totalContributions * 100
+		}
+
+		totalTax += yearTax
+
+		var taxDrag *float64
+		if tax != nil {
+			taxDrag = Ptr(yearTax)
+		}
+
+		yearResults = append(yearResults, YearCalcResults{
+			YearName:       fmt.Sprintf("Year (%d) - %d", year, currentYear+year-1),
+			TotalYearGains: yearGains,
+			CumGain:        cumGain,
+			YoyGrowth:      yoyGrowth,
+			TotalGrowth:    totalGrowth,
+			Balance:        balance,
+			TaxDrag:        taxDrag,
+			MonthsResults:  monthResults,
+		})
+	}
+
+	finalBalance := value()
+
+	totalIncome := 0.0
+	for _, p := range positions {
+		totalIncome += p.income
+	}
+
+	holdingResults := []HoldingResults{}
+	for _, p := range positions {
+		incomeShare := 0.0
+		if totalIncome > 0 {
+			incomeShare = p.income / totalIncome * 100
+		}
+
+		holdingResults = append(holdingResults, HoldingResults{
+			SID:          p.SID,
+			Weight:       p.Weight,
+			Income:       p.income,
+			IncomeShare:  incomeShare,
+			FinalBalance: p.shares * p.price,
+		})
+	}
+
+	rebalance := "Never"
+	if rebalanceFrequency > 0 {
+		rebalance = Capitalize(rebalanceFreqStr)
+	}
+
+	results := CalculationResults{
+		SID:                "Portfolio",
+		Principal:          principal,
+		Contribution:       contribution,
+		Rate:               rate,
+		RateFreq:           "Mixed",
+		Currency:           currency,
+		Profit:             finalBalance - totalContributions,
+		TotalContributions: totalContributions,
+		ContribFreq:        Capitalize(contributionFreqStr),
+		FinalBalance:       finalBalance,
+		YearResults:        yearResults,
+		Holdings:           holdingResults,
+		Rebalance:          rebalance,
+	}
+
+	if tax != nil {
+		results.AccountType = strings.ToUpper(tax.Account)
+		results.TotalTaxDrag = Ptr(totalTax)
+		results.AfterTaxBalance = Ptr(tax.AfterTax(finalBalance, costBasis))
+	}
+
+	return results, nil
+}
@@ -0,0 +1,34 @@
+package helpers
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCalculatePortfolioGrowthWithoutPrincipal(t *testing.T) {
+	holdings := []Holding{
+		{SID: "AAA:NYSE", Weight: 60, Price: 50, Yield: 2, Frequency: "quarterly", PayoutMonth: 3},
+		{SID: "BBB:TSX", Weight: 40, Price: 20, Yield: 4, Frequency: "monthly", PayoutMonth: 1},
+	}
+
+	results, err := CalculatePortfolio(holdings, 0, 100, "monthly", 5, 2, 3, "annual", "USD", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, year := range results.YearResults {
+		if math.IsInf(year.YoyGrowth, 0) || math.IsNaN(year.YoyGrowth) {
+			t.Errorf("%s: yoy growth is %f", year.YearName, year.YoyGrowth)
+		}
+		if math.IsInf(year.TotalGrowth, 0) || math.IsNaN(year.TotalGrowth) {
+			t.Errorf("%s: total growth is %f", year.YearName, year.TotalGrowth)
+		}
+	}
+
+	first := results.YearResults[0]
+	contributions := first.MonthsResults[len(first.MonthsResults)-1].Contributions
+	expected := (first.Balance - contributions) / contributions * 100
+	if math.Abs(first.YoyGrowth-expected) > 1e-9 {
+		t.Errorf("expected a first year growth of %f over the contributions, got %f", expected, first.YoyGrowth)
+	}
+}
@@ -46,6 +46,91 @@ var (
 		},
 		[]string{"event_type"},
 	)
+
+	// Scraper selector metrics, used to detect site redesigns
+	selectorLookupsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "scraper_selector_lookups_total",
+			Help: "Total number of selector lookups made by the scraper",
+		},
+		[]string{"selector", "result"},
+	)
+
+	selectorHitRatio = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "scraper_selector_hit_ratio",
+			Help: "Share of pages matched by a selector during the last scrape run",
+		},
+		[]string{"selector", "suffix"},
+	)
+
+	selectorDriftAlertsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "scraper_selector_drift_alerts_total",
+			Help: "Total number of selectors that matched far fewer pages than in the previous run",
+		},
+		[]string{"selector", "suffix"},
+	)
+
+	selectorsVersion = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "scraper_selectors_info",
+			Help: "Version of the active selector set, 1 for the active one",
+		},
+		[]string{"version"},
+	)
+
+	// Freshness of the stored securities, to check the refresh planner keeps up
+	oldestDataAge = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "scraper_oldest_data_age_seconds",
+			Help: "Age of the stored security refreshed the longest time ago",
+		},
+		[]string{"suffix"},
+	)
+
+	staleSecurities = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "scraper_stale_securities",
+			Help: "Number of stored securities older than the maximum refresh age",
+		},
+		[]string{"suffix"},
+	)
+
+	// Politeness of the scraper towards every host it loads pages of
+	hostRequestsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "scraper_host_requests_total",
+			Help: "Total number of pages requested by host, by result (ok, error, blocked, cooling)",
+		},
+		[]string{"host", "result"},
+	)
+
+	hostWaitDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "scraper_host_wait_seconds",
+			Help:    "Time a page waited for the rate limit of its host",
+			Buckets: []float64{0.1, 0.5, 1, 2, 5, 10, 30, 60, 120},
+		},
+		[]string{"host"},
+	)
+
+	hostPausedUntil = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "scraper_host_paused_until_seconds",
+			Help: "Unix time until which a host that blocked the scraper is paused",
+		},
+		[]string{"host"},
+	)
+
+	// How the values of a source were read, to notice when a page stops embedding its structured data
+	extractionsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "scraper_extractions_total",
+			Help: "Total number of values scraped by source, field and method (json, dom)",
+		},
+		[]string{"source", "field", "method"},
+	)
 )
 
 func IncreaseHTTPRequestCount(method, path string, status int) {
@@ -67,6 +152,57 @@ func RecordBusinessEvent(eventType string) {
 	businessEventsTotal.WithLabelValues(eventType).Inc()
 }
 
+// RecordSelectorLookup counts a scraper selector lookup as a hit or a miss
+func RecordSelectorLookup(selector string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	selectorLookupsTotal.WithLabelValues(selector, result).Inc()
+}
+
+// RecordSelectorHitRatio publishes the hit ratio of a selector at the end of a scrape run
+func RecordSelectorHitRatio(selector string, suffix string, ratio float64) {
+	selectorHitRatio.WithLabelValues(selector, suffix).Set(ratio)
+}
+
+// RecordSelectorDrift counts a drift alert raised for a selector
+func RecordSelectorDrift(selector string, suffix string) {
+	selectorDriftAlertsTotal.WithLabelValues(selector, suffix).Inc()
+}
+
+// RecordSelectorsVersion publishes the version of the selector set the scraper switched to
+func RecordSelectorsVersion(version string) {
+	selectorsVersion.Reset()
+	selectorsVersion.WithLabelValues(version).Set(1)
+}
+
+// RecordDataAge publishes the age of the oldest stored security and how many are past the maximum age
+func RecordDataAge(suffix string, oldest time.Duration, stale int) {
+	oldestDataAge.WithLabelValues(suffix).Set(oldest.Seconds())
+	staleSecurities.WithLabelValues(suffix).Set(float64(stale))
+}
+
+// RecordHostRequest counts a page requested from a host by its result
+func RecordHostRequest(host string, result string) {
+	hostRequestsTotal.WithLabelValues(host, result).Inc()
+}
+
+// RecordHostWait records how long a page waited for the rate limit of its host
+func RecordHostWait(host string, wait time.Duration) {
+	hostWaitDuration.WithLabelValues(host).Observe(wait.Seconds())
+}
+
+// RecordHostPause publishes until when a host is paused after blocking the scraper
+func RecordHostPause(host string, until time.Time) {
+	hostPausedUntil.WithLabelValues(host).Set(float64(until.Unix()))
+}
+
+// RecordExtraction counts a value scraped by a source with the given method
+func RecordExtraction(source string, field string, method string) {
+	extractionsTotal.WithLabelValues(source, field, method).Inc()
+}
+
 // Example usage in a handler with custom metrics
 // func ExampleHandler(c echo.Context) error {
 // 	// Simulate a database query
@@ -0,0 +1,212 @@
+package helpers
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+)
+
+// Goals of the solver
+const (
+	BalanceGoal = "balance" // Balance at the end of the accumulation
+	IncomeGoal  = "income"  // Income paid in the last year of the accumulation
+)
+
+// Inputs the solver can find
+const (
+	SolveContribution = "contribution"
+	SolvePrincipal    = "principal"
+	SolveYears        = "years"
+)
+
+const (
+	MaxSolverYears  = 50
+	maxSolverAmount = 1e12 // Past this amount a goal is considered out of reach
+)
+
+var Goals = []string{BalanceGoal, IncomeGoal}
+
+var SolverUnknowns = []string{SolveContribution, SolvePrincipal, SolveYears}
+
+// Projection runs a calculator with every input fixed but the ones the solver can find
+type Projection func(principal float64, contribution float64, years int) (CalculationResults, error)
+
+// Solution holds the inputs of the projection that reaches the goal
+type Solution struct {
+	Principal    float64
+	Contribution float64
+	Years        int
+}
+
+// GoalResults describes the goal a projection was solved for
+type GoalResults struct {
+	Goal     string  `json:"goal"`
+	Target   float64 `json:"target"`
+	Solve    string  `json:"solve"`
+	Solution float64 `json:"solution"` // Amount, or number of years, found for the unknown
+	Reached  float64 `json:"reached"`  // Balance or income of the solution, at least the target
+}
+
+// lastYearIncome is the income paid in the last year of the accumulation, the interest for a savings account
+func lastYearIncome(results CalculationResults) float64 {
+	last := len(results.YearResults) - 1
+	if results.Withdrawal != nil {
+		last -= results.Withdrawal.Years
+	}
+	if last < 0 {
+		return 0
+	}
+
+	income := 0.0
+	for _, month := range results.YearResults[last].MonthsResults {
+		if month.MonthlyGainedFromDividends != nil {
+			income += *month.MonthlyGainedFromDividends
+		} else {
+			income += month.MonthlyGainedFromPriceInc
+		}
+	}
+
+	return income
+}
+
+// SolveGoal finds by bisection over the projection the smallest contribution, principal or number of years
+// that reaches the target balance or yearly income, the other inputs staying as given
+func SolveGoal(goal string, target float64, unknown string, principal float64, contribution float64, years int, project Projection) (CalculationResults, Solution, error) {
+	goal = strings.ToLower(strings.TrimSpace(goal))
+	if !slices.Contains(Goals, goal) {
+		return CalculationResults{}, Solution{}, fmt.Errorf("invalid goal: %s, must be one of %s", goal, strings.Join(Goals, ", "))
+	}
+
+	unknown = strings.ToLower(strings.TrimSpace(unknown))
+	if !slices.Contains(SolverUnknowns, unknown) {
+		return CalculationResults{}, Solution{}, fmt.Errorf("invalid input to solve: %s, must be one of %s", unknown, strings.Join(SolverUnknowns, ", "))
+	}
+
+	if target <= 0 {
+		return CalculationResults{}, Solution{}, fmt.Errorf("invalid target: %.2f, must be positive", target)
+	}
+
+	measure := func(results CalculationResults) float64 {
+		if goal == IncomeGoal {
+			return lastYearIncome(results)
+		}
+		return results.FinalBalance
+	}
+
+	solution := Solution{Principal: principal, Contribution: contribution, Years: years}
+
+	// Projects the solution with the unknown set to x, years being rounded down
+	at := func(x float64) (CalculationResults, float64, error) {
+		switch unknown {
+		case SolveContribution:
+			solution.Contribution = x
+		case SolvePrincipal:
+			solution.Principal = x
+		case SolveYears:
+			solution.Years = int(x)
+		}
+
+		results, err := project(solution.Principal, solution.Contribution, solution.Years)
+		if err != nil {
+			return CalculationResults{}, 0, err
+		}
+
+		return results, measure(results), nil
+	}
+
+	unreachable := fmt.Errorf("the goal cannot be reached by changing the %s alone", unknown)
+
+	var results CalculationResults
+	var reached float64
+	var err error
+
+	if unknown == SolveYears {
+		low, high := 1, MaxSolverYears
+		if results, reached, err = at(float64(high)); err != nil {
+			return CalculationResults{}, Solution{}, err
+		}
+		if reached < target {
+			return CalculationResults{}, Solution{}, fmt.Errorf("%w within %d years", unreachable, MaxSolverYears)
+		}
+
+		for low < high {
+			mid := (low + high) / 2
+			_, midReached, err := at(float64(mid))
+			if err != nil {
+				return CalculationResults{}, Solution{}, err
+			}
+			if midReached >= target {
+				high = mid
+			} else {
+				low = mid + 1
+			}
+		}
+
+		if results, reached, err = at(float64(high)); err != nil {
+			return CalculationResults{}, Solution{}, err
+		}
+	} else {
+		low, high := 0.0, math.Max(target, 1)
+
+		if results, reached, err = at(low); err != nil {
+			return CalculationResults{}, Solution{}, err
+		}
+
+		if reached < target {
+			// Widen the bracket until it holds the solution
+			for {
+				_, highReached, err := at(high)
+				if err != nil {
+					return CalculationResults{}, Solution{}, err
+				}
+				if highReached >= target {
+					break
+				}
+				low = high
+				high *= 2
+				if high > maxSolverAmount {
+					return CalculationResults{}, Solution{}, unreachable
+				}
+			}
+
+			// To the cent
+			for high-low > 0.01 {
+				mid := (low + high) / 2
+				_, midReached, err := at(mid)
+				if err != nil {
+					return CalculationResults{}, Solution{}, err
+				}
+				if midReached >= target {
+					high = mid
+				} else {
+					low = mid
+				}
+			}
+
+			if results, reached, err = at(math.Ceil(high*100) / 100); err != nil {
+				return CalculationResults{}, Solution{}, err
+			}
+		}
+	}
+
+	goalResults := &GoalResults{
+		Goal:    goal,
+		Target:  target,
+		Solve:   unknown,
+		Reached: reached,
+	}
+
+	switch unknown {
+	case SolveContribution:
+		goalResults.Solution = solution.Contribution
+	case SolvePrincipal:
+		goalResults.Solution = solution.Principal
+	case SolveYears:
+		goalResults.Solution = float64(solution.Years)
+	}
+
+	results.Goal = goalResults
+
+	return results, solution, nil
+}
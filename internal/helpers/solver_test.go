@@ -0,0 +1,98 @@
+package helpers
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+// linear projects a balance of the principal and the contributions of every month, that pays 4% of it
+// over the last year. The balance stops growing at cap when it is set.
+func linear(cap float64) Projection {
+	return func(principal float64, contribution float64, years int) (CalculationResults, error) {
+		balance := principal + contribution*12*float64(years)
+		if cap > 0 {
+			balance = math.Min(balance, cap)
+		}
+
+		months := make([]MonthCalcResults, 12)
+		for i := range months {
+			months[i] = MonthCalcResults{MonthlyGainedFromDividends: Ptr(balance * 0.04 / 12)}
+		}
+
+		return CalculationResults{FinalBalance: balance, YearResults: []YearCalcResults{{MonthsResults: months}}}, nil
+	}
+}
+
+func TestSolveGoal(t *testing.T) {
+	tests := []struct {
+		name         string
+		goal         string
+		target       float64
+		unknown      string
+		principal    float64
+		contribution float64
+		years        int
+		cap          float64
+		solution     float64
+		err          string
+	}{
+		{name: "contribution", goal: BalanceGoal, target: 12000, unknown: SolveContribution, years: 10, solution: 100},
+		{name: "contribution to the cent", goal: BalanceGoal, target: 10000, unknown: SolveContribution, years: 3, solution: 10000.0 / 36},
+		{name: "principal", goal: BalanceGoal, target: 5000, unknown: SolvePrincipal, years: 5, solution: 5000},
+		{name: "principal below the first bracket", goal: BalanceGoal, target: 0.5, unknown: SolvePrincipal, years: 5, solution: 0.5},
+		{name: "years", goal: BalanceGoal, target: 10000, unknown: SolveYears, principal: 1000, contribution: 100, solution: 8},
+		{name: "already reached", goal: BalanceGoal, target: 10000, unknown: SolveContribution, principal: 20000, years: 5, solution: 0},
+		{name: "income widening the bracket", goal: IncomeGoal, target: 400, unknown: SolvePrincipal, years: 1, solution: 10000},
+		{name: "years out of reach", goal: BalanceGoal, target: 1e6, unknown: SolveYears, principal: 100, err: "within 50 years"},
+		{name: "amount out of reach", goal: BalanceGoal, target: 1000, unknown: SolvePrincipal, years: 5, cap: 500, err: "cannot be reached by changing the principal"},
+		{name: "unknown goal", goal: "wealth", target: 1000, unknown: SolvePrincipal, err: "invalid goal"},
+		{name: "unknown input", goal: BalanceGoal, target: 1000, unknown: "rate", err: "invalid input to solve"},
+		{name: "no target", goal: BalanceGoal, unknown: SolvePrincipal, err: "invalid target"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			results, solution, err := SolveGoal(test.goal, test.target, test.unknown, test.principal, test.contribution, test.years, linear(test.cap))
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("expected an error about %q, got %v", test.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// The bisection stops within a cent above the exact amount, that is then rounded up to the cent
+			if diff := results.Goal.Solution - test.solution; diff < 0 || diff >= 0.02 {
+				t.Errorf("expected a solution of %f, got %f", test.solution, results.Goal.Solution)
+			}
+			if results.Goal.Reached < test.target {
+				t.Errorf("expected the solution to reach %f, got %f", test.target, results.Goal.Reached)
+			}
+
+			solved := map[string]float64{SolveContribution: solution.Contribution, SolvePrincipal: solution.Principal, SolveYears: float64(solution.Years)}
+			if solved[test.unknown] != results.Goal.Solution {
+				t.Errorf("expected the solved inputs to hold the solution %f, got %f", results.Goal.Solution, solved[test.unknown])
+			}
+		})
+	}
+}
+
+func TestSolveGoalProjectionError(t *testing.T) {
+	failure := errors.New("no price")
+	project := func(principal float64, contribution float64, years int) (CalculationResults, error) {
+		return CalculationResults{}, failure
+	}
+
+	for _, unknown := range SolverUnknowns {
+		t.Run(unknown, func(t *testing.T) {
+			_, _, err := SolveGoal(BalanceGoal, 1000, unknown, 0, 0, 5, project)
+			if !errors.Is(err, failure) {
+				t.Errorf("expected the error of the projection, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package helpers
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+)
+
+// Account types of the tax profile
+const (
+	TaxableAccount = "taxable"
+	TFSAAccount    = "tfsa"
+	RRSPAccount    = "rrsp"
+	K401Account    = "401k"
+	ISAAccount     = "isa"
+)
+
+var AccountTypes = []string{TaxableAccount, TFSAAccount, RRSPAccount, K401Account, ISAAccount}
+
+// DividendWithholding is the tax withheld at source on dividends paid to foreign residents, in percent,
+// by country of the exchange and then by country of residence with "*" for every other country.
+// Treaty rates apply where a treaty lowers the rate at source, statutory rates where it only allows a reclaim.
+var DividendWithholding = map[string]map[string]float64{
+	"US": {"*": 30, "CA": 15, "GB": 15, "DE": 15, "IT": 15, "CH": 15, "AU": 15, "JP": 10, "FR": 15, "ES": 15, "NL": 15, "IE": 15},
+	"CA": {"*": 25, "US": 15, "GB": 15, "DE": 15, "IT": 15, "CH": 15, "AU": 15, "JP": 15, "FR": 15, "ES": 15, "NL": 15, "IE": 15},
+	"GB": {"*": 0},
+	"DE": {"*": 26.375},
+	"IT": {"*": 26},
+	"CH": {"*": 35},
+	"JP": {"*": 15.315},
+	"AU": {"*": 30},
+}
+
+// TaxProfile describes how the returns of an investment are taxed for its holder, rates are marginal and in percent
+type TaxProfile struct {
+	Account          string
+	Residence        string // ISO country code
+	IncomeRate       float64
+	DividendRate     float64
+	CapitalGainsRate float64
+}
+
+// NewTaxProfile validates the tax inputs of the calculator, nil when no account type is given
+func NewTaxProfile(account string, residence string, incomeRate float64, dividendRate float64, capitalGainsRate float64) (*TaxProfile, error) {
+	account = strings.ToLower(strings.TrimSpace(account))
+	if account == "" {
+		return nil, nil
+	}
+
+	if !slices.Contains(AccountTypes, account) {
+		return nil, fmt.Errorf("invalid account type: %s, must be one of %s", account, strings.Join(AccountTypes, ", "))
+	}
+
+	residence = strings.ToUpper(strings.TrimSpace(residence))
+	if len(residence) != 2 {
+		return nil, fmt.Errorf("invalid country of residence: %s, expected a 2 letter country code", residence)
+	}
+
+	for _, rate := range []float64{incomeRate, dividendRate, capitalGainsRate} {
+		if rate < 0 || rate > 100 {
+			return nil, fmt.Errorf("invalid tax rate: %.2f, must be between 0 and 100", rate)
+		}
+	}
+
+	return &TaxProfile{
+		Account:          account,
+		Residence:        residence,
+		IncomeRate:       incomeRate,
+		DividendRate:     dividendRate,
+		CapitalGainsRate: capitalGainsRate,
+	}, nil
+}
+
+// Sheltered tells whether the account defers or exempts the taxes of its holder on dividends and gains
+func (p *TaxProfile) Sheltered() bool {
+	return p.Account != TaxableAccount
+}
+
+// Withholding is the rate withheld at source on dividends of a security listed in source
+func (p *TaxProfile) Withholding(source string) float64 {
+	if source == "" || source == p.Residence {
+		return 0
+	}
+
+	// The Canada-US treaty exempts retirement accounts such as the RRSP from US withholding
+	if p.Account == RRSPAccount && source == "US" && p.Residence == "CA" {
+		return 0
+	}
+
+	rates, ok := DividendWithholding[source]
+	if !ok {
+		return 0
+	}
+	if rate, ok := rates[p.Residence]; ok {
+		return rate
+	}
+	return rates["*"]
+}
+
+// DividendTax is the share of a dividend lost to taxes in the year it is paid, in percent.
+// In a taxable account the withholding is credited against the domestic rate.
+func (p *TaxProfile) DividendTax(source string) float64 {
+	withholding := p.Withholding(source)
+	if p.Sheltered() {
+		return withholding
+	}
+	return math.Max(withholding, p.DividendRate)
+}
+
+// AfterTax is what is left of the final balance once it is sold or withdrawn, basis being what was paid for it
+func (p *TaxProfile) AfterTax(balance float64, basis float64) float64 {
+	switch p.Account {
+	case RRSPAccount, K401Account:
+		// Withdrawals are taxed as income
+		return balance * (1 - p.IncomeRate/100)
+	case TaxableAccount:
+		gain := balance - basis
+		if gain <= 0 {
+			return balance
+		}
+		return balance - gain*p.CapitalGainsRate/100
+	default:
+		return balance
+	}
+}
@@ -0,0 +1,92 @@
+package helpers
+
+import "testing"
+
+func TestDividendTax(t *testing.T) {
+	tests := []struct {
+		name      string
+		account   string
+		residence string
+		source    string
+		withheld  float64
+		tax       float64
+	}{
+		{name: "domestic taxable", account: TaxableAccount, residence: "CA", source: "CA", withheld: 0, tax: 25},
+		{name: "treaty rate credited", account: TaxableAccount, residence: "CA", source: "US", withheld: 15, tax: 25},
+		{name: "withholding above the domestic rate", account: TaxableAccount, residence: "CA", source: "CH", withheld: 35, tax: 35},
+		{name: "default rate without a treaty", account: TaxableAccount, residence: "BR", source: "US", withheld: 30, tax: 30},
+		{name: "tfsa keeps the withholding", account: TFSAAccount, residence: "CA", source: "US", withheld: 15, tax: 15},
+		{name: "rrsp exempt from us withholding", account: RRSPAccount, residence: "CA", source: "US", withheld: 0, tax: 0},
+		{name: "isa of a uk security", account: ISAAccount, residence: "GB", source: "GB", withheld: 0, tax: 0},
+		{name: "unknown source", account: TaxableAccount, residence: "US", source: "BR", withheld: 0, tax: 25},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			profile, err := NewTaxProfile(test.account, test.residence, 40, 25, 20)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expectClose(t, "withholding", profile.Withholding(test.source), test.withheld)
+			expectClose(t, "dividend tax", profile.DividendTax(test.source), test.tax)
+		})
+	}
+}
+
+func TestAfterTax(t *testing.T) {
+	tests := []struct {
+		name     string
+		account  string
+		balance  float64
+		basis    float64
+		expected float64
+	}{
+		{name: "taxable gain", account: TaxableAccount, balance: 15000, basis: 10000, expected: 14000},
+		{name: "taxable loss", account: TaxableAccount, balance: 8000, basis: 10000, expected: 8000},
+		{name: "rrsp taxed as income", account: RRSPAccount, balance: 15000, basis: 10000, expected: 9000},
+		{name: "401k taxed as income", account: K401Account, balance: 15000, basis: 10000, expected: 9000},
+		{name: "tfsa tax free", account: TFSAAccount, balance: 15000, basis: 10000, expected: 15000},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			profile, err := NewTaxProfile(test.account, "CA", 40, 25, 20)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expectClose(t, "after tax", profile.AfterTax(test.balance, test.basis), test.expected)
+		})
+	}
+}
+
+func TestNewTaxProfile(t *testing.T) {
+	tests := []struct {
+		name      string
+		account   string
+		residence string
+		rate      float64
+		valid     bool
+		none      bool
+	}{
+		{name: "no account", account: " ", none: true, valid: true},
+		{name: "normalized", account: " TFSA ", residence: "ca", rate: 30, valid: true},
+		{name: "unknown account", account: "roth", residence: "US", rate: 30},
+		{name: "invalid residence", account: TaxableAccount, residence: "CAN", rate: 30},
+		{name: "rate above 100", account: TaxableAccount, residence: "CA", rate: 120},
+		{name: "negative rate", account: TaxableAccount, residence: "CA", rate: -1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			profile, err := NewTaxProfile(test.account, test.residence, test.rate, test.rate, test.rate)
+			if (err == nil) != test.valid {
+				t.Fatalf("expected valid %v, got %v", test.valid, err)
+			}
+			if test.valid && (profile == nil) != test.none {
+				t.Errorf("expected no profile %v, got %+v", test.none, profile)
+			}
+		})
+	}
+}
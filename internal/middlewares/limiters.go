@@ -9,6 +9,10 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
+// shapingParams only narrow down or page through a response, so every plan may send them and they do not count as filters.
+// A cursor only continues the order it was issued for.
+var shapingParams = []string{"fields", "include", "cursor"}
+
 func QueryParamMiddleware() echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
@@ -33,18 +37,24 @@ func QueryParamMiddleware() echo.MiddlewareFunc {
 				return echo.NewHTTPError(http.StatusForbidden, "Invalid subscription plan")
 			}
 
-			// Get query parameters
+			// Get query parameters, the shaping ones are open to every plan
 			queryParams := c.QueryParams()
+			filters := 0
+			for param := range queryParams {
+				if !contains(shapingParams, param) {
+					filters++
+				}
+			}
 
 			// Check maximum parameter limit
-			if plan.MaxParams != -1 && len(queryParams) > plan.MaxParams {
+			if plan.MaxParams != -1 && filters > plan.MaxParams {
 				return echo.NewHTTPError(http.StatusForbidden, "Exceeded maximum allowed query parameters")
 			}
 
 			// Check allowed parameters
 			if plan.AllowedParams != nil {
 				for param := range queryParams {
-					if !contains(plan.AllowedParams, param) {
+					if !contains(plan.AllowedParams, param) && !contains(shapingParams, param) {
 						return echo.NewHTTPError(http.StatusForbidden, "Unauthorized query parameter: "+param)
 					}
 				}
@@ -0,0 +1,56 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Francesco99975/finexo/cmd/boot"
+	"github.com/labstack/echo/v4"
+)
+
+func TestQueryParamMiddleware(t *testing.T) {
+	boot.Environment = &boot.Config{GoEnv: "development"}
+
+	tests := []struct {
+		name   string
+		plan   string
+		query  string
+		status int
+	}{
+		{name: "allowed filters", plan: "free", query: "exchange=NASDAQ&currency=USD", status: http.StatusOK},
+		{name: "filter outside the plan", plan: "free", query: "minPrice=100", status: http.StatusForbidden},
+		{name: "too many filters", plan: "free", query: "exchange=NASDAQ&country=US&currency=USD", status: http.StatusForbidden},
+		{name: "shaping open to every plan", plan: "free", query: "exchange=NASDAQ&currency=USD&fields=ticker,price&include=dividend&cursor=abc", status: http.StatusOK},
+		{name: "anything on pro", plan: "pro", query: "minPrice=100&order=yield&fields=ticker", status: http.StatusOK},
+		{name: "unknown plan", plan: "gold", query: "fields=ticker", status: http.StatusForbidden},
+		{name: "missing plan", query: "fields=ticker", status: http.StatusUnauthorized},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/stocks?"+test.query, nil)
+			if test.plan != "" {
+				req.Header.Set("X-RapidAPI-Subscription", test.plan)
+			}
+			c := echo.New().NewContext(req, httptest.NewRecorder())
+
+			handler := QueryParamMiddleware()(func(c echo.Context) error {
+				return c.NoContent(http.StatusOK)
+			})
+
+			status := http.StatusOK
+			if err := handler(c); err != nil {
+				httpErr, ok := err.(*echo.HTTPError)
+				if !ok {
+					t.Fatalf("expected an HTTP error, got %v", err)
+				}
+				status = httpErr.Code
+			}
+
+			if status != test.status {
+				t.Errorf("expected status %d, got %d", test.status, status)
+			}
+		})
+	}
+}
@@ -39,7 +39,12 @@ func (bm *BrowserManager) launchNewBrowser() *rod.Browser {
 			Set("disable-dev-shm-usage").
 			Set("disable-extensions").MustLaunch()
 	} else {
-		u = launcher.New().NoSandbox(true).Headless(true).Devtools(false).
+		l := launcher.New()
+		// A locally installed browser spares the download, and lets the fixtures run offline
+		if path, found := launcher.LookPath(); found {
+			l = l.Bin(path)
+		}
+		u = l.NoSandbox(true).Headless(true).Devtools(false).
 			Set("disable-dev-shm-usage").
 			Set("disable-extensions").MustLaunch()
 	}
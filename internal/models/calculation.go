@@ -0,0 +1,276 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/Francesco99975/finexo/internal/helpers"
+	"github.com/jmoiron/sqlx"
+)
+
+// BacktestMonthLayout is the layout of the first month of a backtest
+const BacktestMonthLayout = "2006-01"
+
+// CalculationError is a failed calculation, Message being safe to show to the user
+type CalculationError struct {
+	Message string
+	Err     error
+}
+
+func (e *CalculationError) Error() string {
+	return e.Message + ": " + e.Err.Error()
+}
+
+func (e *CalculationError) Unwrap() error {
+	return e.Err
+}
+
+// Events are the business events of a calculation of the input
+func (input CalcInput) Events() []string {
+	events := []string{}
+
+	if input.SID == "default" {
+		events = append(events, "calculate_hisa")
+	} else {
+		events = append(events, "calculate_investment")
+		if input.Simulate {
+			events = append(events, "simulate_investment")
+		}
+	}
+
+	if input.Backtest {
+		events = append(events, "backtest_investment")
+	} else if input.Solve != "" {
+		events = append(events, "solve_goal")
+	}
+
+	return events
+}
+
+// Calculate projects the input on the security it names, or on a savings account when its SID is default.
+// With an input to solve it is first found so that the projection reaches the goal.
+func Calculate(db *sqlx.DB, input CalcInput) (helpers.CalculationResults, error) {
+	var vars *SecurityVars
+	currency := input.Currency
+	if input.SID != "default" {
+		var err error
+		vars, err = GetSecurityVars(db, input.SID)
+		if err != nil {
+			return helpers.CalculationResults{}, &CalculationError{Message: "Could not identify security to do calculations", Err: err}
+		}
+		currency = vars.Currency
+	}
+
+	// Without an inflation rate the one of the currency is assumed
+	inflation := helpers.InflationFor(currency)
+	if input.Inflation != nil {
+		inflation = *input.Inflation
+	}
+
+	withdrawal, err := helpers.NewWithdrawalPlan(input.Withdraw, input.WithdrawalAmount, input.WithdrawalRate, inflation, input.WithdrawalYears)
+	if err != nil {
+		return helpers.CalculationResults{}, &CalculationError{Message: err.Error(), Err: err}
+	}
+
+	var project helpers.Projection
+	var simulate func(principal float64, contribution float64, years int) (*helpers.SimulationResults, error)
+	if vars != nil {
+		tax, err := helpers.NewTaxProfile(input.AccountType, input.Residence, input.IncomeTax, input.DividendTax, input.CapitalGainsTax)
+		if err != nil {
+			return helpers.CalculationResults{}, &CalculationError{Message: err.Error(), Err: err}
+		}
+
+		project = func(principal float64, contribution float64, years int) (helpers.CalculationResults, error) {
+			results, err := helpers.CalculateInvestment(input.SID, vars.Price, vars.Yield, vars.ExpenseRatio, principal, contribution, input.ContribFrequency, vars.Frequency, input.PriceMod, input.YieldMod, years, vars.PayoutMonth, vars.Currency, tax, vars.Country, withdrawal)
+			if err != nil {
+				return helpers.CalculationResults{}, &CalculationError{Message: "Failed to calculate investment compound", Err: err}
+			}
+			return results, nil
+		}
+
+		// The simulation is run once, on the inputs that reach the goal
+		if input.Simulate {
+			simulate = func(principal float64, contribution float64, years int) (*helpers.SimulationResults, error) {
+				simulation, err := helpers.SimulateInvestment(vars.Price, vars.Yield, vars.ExpenseRatio, principal, contribution, input.ContribFrequency, vars.Frequency, input.PriceMod, input.YieldMod, vars.Volatility, years, vars.PayoutMonth, input.Paths, input.Target, vars.Currency, tax, vars.Country)
+				if err != nil {
+					return nil, &CalculationError{Message: "Failed to simulate investment", Err: err}
+				}
+				return simulation, nil
+			}
+		}
+	} else {
+		project = func(principal float64, contribution float64, years int) (helpers.CalculationResults, error) {
+			results, err := helpers.CalculateHISAInvestment(principal, contribution, input.ContribFrequency, input.CompundingFrequency, input.Rate, years, input.Currency, withdrawal)
+			if err != nil {
+				return helpers.CalculationResults{}, &CalculationError{Message: "Failed to calculate hisa compound", Err: err}
+			}
+			return results, nil
+		}
+	}
+
+	solution := helpers.Solution{Principal: input.Principal, Contribution: input.Contribution, Years: input.Years}
+
+	var results helpers.CalculationResults
+	if input.Solve != "" {
+		results, solution, err = helpers.SolveGoal(input.Goal, input.GoalAmount, input.Solve, input.Principal, input.Contribution, input.Years, project)
+	} else {
+		results, err = project(input.Principal, input.Contribution, input.Years)
+	}
+	if err != nil {
+		if _, ok := err.(*CalculationError); ok {
+			return helpers.CalculationResults{}, err
+		}
+		return helpers.CalculationResults{}, &CalculationError{Message: err.Error(), Err: err}
+	}
+
+	if simulate != nil {
+		if results.Simulation, err = simulate(solution.Principal, solution.Contribution, solution.Years); err != nil {
+			return helpers.CalculationResults{}, err
+		}
+	}
+
+	if err := results.AdjustForInflation(inflation); err != nil {
+		return helpers.CalculationResults{}, &CalculationError{Message: err.Error(), Err: err}
+	}
+
+	return results, nil
+}
+
+// BacktestComparison holds a plan replayed on the history of a security next to its projection over as many years
+type BacktestComparison struct {
+	Backtest        helpers.CalculationResults `json:"backtest"`
+	Projection      helpers.CalculationResults `json:"projection"`
+	ProjectionInput CalcInput                  `json:"-"` // Input of the projection, to store it apart from the backtest
+}
+
+// CalculateBacktest replays the input on the stored prices and dividends of its security since the Since month,
+// then projects it from today over as many years. Neither has a goal or a withdrawal phase so that both compare.
+func CalculateBacktest(db *sqlx.DB, input CalcInput) (BacktestComparison, error) {
+	results, months, err := backtest(db, input)
+	if err != nil {
+		return BacktestComparison{}, err
+	}
+
+	projected := input
+	projected.Backtest = false
+	projected.Years = int(math.Ceil(float64(months) / 12))
+	projected.Solve = ""
+	projected.Withdraw = ""
+
+	projection, err := Calculate(db, projected)
+	if err != nil {
+		return BacktestComparison{}, err
+	}
+
+	return BacktestComparison{Backtest: results, Projection: projection, ProjectionInput: projected}, nil
+}
+
+// backtest replays the input on the stored history of its security, along with the number of months replayed
+func backtest(db *sqlx.DB, input CalcInput) (helpers.CalculationResults, int, error) {
+	if input.SID == "" || input.SID == "default" {
+		err := errors.New("no security to backtest")
+		return helpers.CalculationResults{}, 0, &CalculationError{Message: "A security is needed to backtest", Err: err}
+	}
+
+	since, err := time.Parse(BacktestMonthLayout, input.Since)
+	if err != nil {
+		return helpers.CalculationResults{}, 0, &CalculationError{Message: "The backtest must start from a month as YYYY-MM", Err: err}
+	}
+	if since.After(time.Now()) {
+		err := fmt.Errorf("invalid first month: %s, must not be in the future", input.Since)
+		return helpers.CalculationResults{}, 0, &CalculationError{Message: err.Error(), Err: err}
+	}
+
+	vars, err := GetSecurityVars(db, input.SID)
+	if err != nil {
+		return helpers.CalculationResults{}, 0, &CalculationError{Message: "Could not identify security to do calculations", Err: err}
+	}
+
+	tax, err := helpers.NewTaxProfile(input.AccountType, input.Residence, input.IncomeTax, input.DividendTax, input.CapitalGainsTax)
+	if err != nil {
+		return helpers.CalculationResults{}, 0, &CalculationError{Message: err.Error(), Err: err}
+	}
+
+	closes, err := GetMonthlyCloses(db, input.SID, since)
+	if err != nil {
+		return helpers.CalculationResults{}, 0, err
+	}
+	if len(closes) == 0 {
+		err := fmt.Errorf("no prices of %s since %s", input.SID, input.Since)
+		return helpers.CalculationResults{}, 0, &CalculationError{Message: "No price history is stored for the security since " + input.Since, Err: err}
+	}
+
+	events, err := GetDividendEvents(db, input.SID)
+	if err != nil {
+		return helpers.CalculationResults{}, 0, err
+	}
+	dividends := helpers.MapSlice(events, func(event DividendEvent) helpers.DividendPayment {
+		return helpers.DividendPayment{ExDivDate: event.ExDivDate, Amount: float64(event.Amount) / 100.0}
+	})
+
+	results, err := helpers.Backtest(input.SID, closes, dividends, input.Principal, input.Contribution, input.ContribFrequency, vars.Frequency, input.DRIP, vars.Currency, tax, vars.Country)
+	if err != nil {
+		return helpers.CalculationResults{}, 0, &CalculationError{Message: "Failed to backtest investment", Err: err}
+	}
+
+	return results, len(closes), nil
+}
+
+// CalculatePortfolio projects the holdings of the input, every one of them quoted in the same currency
+func CalculatePortfolio(db *sqlx.DB, input PortfolioInput) (helpers.CalculationResults, error) {
+	weights, err := input.ParseHoldings()
+	if err != nil {
+		return helpers.CalculationResults{}, &CalculationError{Message: err.Error(), Err: err}
+	}
+
+	holdings := []helpers.Holding{}
+	currency := ""
+	for _, weight := range weights {
+		vars, err := GetSecurityVars(db, weight.SID)
+		if err != nil {
+			return helpers.CalculationResults{}, &CalculationError{Message: fmt.Sprintf("Could not identify %s to do calculations", weight.SID), Err: err}
+		}
+
+		// Prices are not converted, every holding must be quoted in the same currency
+		if currency != "" && vars.Currency != currency {
+			err := fmt.Errorf("%s is quoted in %s, the portfolio in %s", weight.SID, vars.Currency, currency)
+			return helpers.CalculationResults{}, &CalculationError{Message: err.Error(), Err: err}
+		}
+		currency = vars.Currency
+
+		holdings = append(holdings, helpers.Holding{
+			SID:          weight.SID,
+			Weight:       weight.Weight,
+			Price:        vars.Price,
+			Yield:        vars.Yield,
+			ExpenseRatio: vars.ExpenseRatio,
+			Frequency:    vars.Frequency,
+			PayoutMonth:  vars.PayoutMonth,
+			Country:      vars.Country,
+		})
+	}
+
+	tax, err := helpers.NewTaxProfile(input.AccountType, input.Residence, input.IncomeTax, input.DividendTax, input.CapitalGainsTax)
+	if err != nil {
+		return helpers.CalculationResults{}, &CalculationError{Message: err.Error(), Err: err}
+	}
+
+	results, err := helpers.CalculatePortfolio(holdings, input.Principal, input.Contribution, input.ContribFrequency, input.PriceMod, input.YieldMod, input.Years, input.Rebalance, currency, tax)
+	if err != nil {
+		return helpers.CalculationResults{}, &CalculationError{Message: "Failed to calculate portfolio", Err: err}
+	}
+
+	// Without an inflation rate the one of the currency is assumed
+	inflation := helpers.InflationFor(currency)
+	if input.Inflation != nil {
+		inflation = *input.Inflation
+	}
+
+	if err := results.AdjustForInflation(inflation); err != nil {
+		return helpers.CalculationResults{}, &CalculationError{Message: err.Error(), Err: err}
+	}
+
+	return results, nil
+}
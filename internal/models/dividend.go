@@ -22,6 +22,8 @@ type Dividend struct {
 	Frequency     NullableString `db:"frequency" json:"frequency,omitempty"` // Enum: Frequency
 	ExDivDate     NullableTime   `db:"edd" json:"exDivDate,omitempty"`
 	PayoutDate    NullableTime   `db:"pd" json:"payoutDate,omitempty"`
+
+	Events []DividendEvent `db:"-" json:"events,omitempty"` // Scraped payment history (if any)
 }
 
 func (d *Dividend) PrettyPrintString() string {
@@ -76,6 +78,10 @@ func InsertDividend(tx *sqlx.Tx, dividend *Dividend) error {
 		return fmt.Errorf("failed to insert dividend for %s (%s): %w", dividend.Ticker, dividend.Exchange, err)
 	}
 
+	if err := InsertDividendEvents(tx, dividend.Events); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -156,5 +162,9 @@ func UpdateDividend(tx *sqlx.Tx, dividend *Dividend) error {
 		return fmt.Errorf("failed to update dividend (%s): %w", dividend.Ticker, err)
 	}
 
+	if err := InsertDividendEvents(tx, dividend.Events); err != nil {
+		return err
+	}
+
 	return nil
 }
@@ -0,0 +1,166 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/Francesco99975/finexo/internal/database"
+	"github.com/jmoiron/sqlx"
+)
+
+// DividendAnalytics is derived from the stored payment history of a security.
+// Only the regular payments of full calendar years are taken into account.
+type DividendAnalytics struct {
+	Ticker      string      `db:"ticker" json:"ticker"`
+	Exchange    string      `db:"exchange" json:"exchange"`
+	CAGR1       NullableInt `db:"cagr1" json:"cagr1,omitempty"`
+	CAGR3       NullableInt `db:"cagr3" json:"cagr3,omitempty"`
+	CAGR5       NullableInt `db:"cagr5" json:"cagr5,omitempty"`
+	CAGR10      NullableInt `db:"cagr10" json:"cagr10,omitempty"`
+	YearsGrowth int         `db:"yog" json:"yearsGrowth"`
+	Cuts        int         `db:"cuts" json:"cuts"`
+	LastCutYear NullableInt `db:"lcy" json:"lastCutYear,omitempty"`
+	Computed    time.Time   `db:"computed" json:"computed"`
+}
+
+// AnnualDividends sums the regular payments by the year of their ex-dividend date
+func AnnualDividends(events []DividendEvent) map[int]int {
+	totals := make(map[int]int)
+	for _, event := range events {
+		if event.Type != string(DistributionRegular) {
+			continue
+		}
+		totals[event.ExDivDate.Year()] += event.Amount
+	}
+	return totals
+}
+
+// dividendCAGR is the compound growth (x100) of the annual payout over the given years ending with last
+func dividendCAGR(totals map[int]int, last int, years int) NullableInt {
+	start, end := totals[last-years], totals[last]
+	if start <= 0 || end <= 0 {
+		return NullableInt{Valid: false}
+	}
+
+	rate := math.Pow(float64(end)/float64(start), 1.0/float64(years)) - 1
+	return ValidInt(int64(math.Round(rate * 10000)))
+}
+
+// AnalyzeDividendEvents computes growth rates, streak and cuts of a payment history,
+// the year of now is still in progress and therefore ignored
+func AnalyzeDividendEvents(ticker string, exchange string, events []DividendEvent, now time.Time) *DividendAnalytics {
+	analytics := &DividendAnalytics{Ticker: ticker, Exchange: exchange, Computed: now}
+
+	totals := AnnualDividends(events)
+	last := now.Year() - 1
+
+	// The history usually starts mid-year, so the first year is as partial as the current one
+	first := last
+	for year := range totals {
+		first = min(first, year)
+	}
+	delete(totals, first)
+
+	// A cut is a full year paying less than the previous one, a year without payments after paying counts too
+	for year := first + 2; year <= last; year++ {
+		if totals[year-1] > 0 && totals[year] < totals[year-1] {
+			analytics.Cuts++
+			analytics.LastCutYear = ValidInt(int64(year))
+		}
+	}
+
+	// Growth needs a payout in the last full year, a suspended dividend only has cuts
+	if totals[last] <= 0 {
+		return analytics
+	}
+
+	analytics.CAGR1 = dividendCAGR(totals, last, 1)
+	analytics.CAGR3 = dividendCAGR(totals, last, 3)
+	analytics.CAGR5 = dividendCAGR(totals, last, 5)
+	analytics.CAGR10 = dividendCAGR(totals, last, 10)
+
+	for year := last; totals[year-1] > 0 && totals[year] > totals[year-1]; year-- {
+		analytics.YearsGrowth++
+	}
+
+	return analytics
+}
+
+// UpdateDividendAnalytics recomputes the analytics of every security with a payment history
+// and writes the five years growth rate and the streak back to the dividends table
+func UpdateDividendAnalytics(db *sqlx.DB, now time.Time) (updated int, err error) {
+	events := []DividendEvent{}
+	err = db.Select(&events, `
+		SELECT ticker, exchange, edd, pd, amount, type
+		FROM dividend_events
+		ORDER BY ticker, exchange, edd
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get dividend events: %w", err)
+	}
+
+	histories := make(map[string][]DividendEvent)
+	for _, event := range events {
+		key := event.Ticker + ":" + event.Exchange
+		histories[key] = append(histories[key], event)
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return 0, fmt.Errorf("failed to start transaction: %w", err)
+	}
+
+	defer database.HandleTransaction(tx, &err)
+
+	for key, history := range histories {
+		ticker, exchange, _ := strings.Cut(key, ":")
+		analytics := AnalyzeDividendEvents(ticker, exchange, history, now)
+
+		_, err = tx.NamedExec(`
+			INSERT INTO dividend_analytics (ticker, exchange, cagr1, cagr3, cagr5, cagr10, yog, cuts, lcy, computed)
+			VALUES (:ticker, :exchange, :cagr1, :cagr3, :cagr5, :cagr10, :yog, :cuts, :lcy, :computed)
+			ON CONFLICT (ticker, exchange) DO UPDATE SET
+				cagr1 = EXCLUDED.cagr1, cagr3 = EXCLUDED.cagr3, cagr5 = EXCLUDED.cagr5, cagr10 = EXCLUDED.cagr10,
+				yog = EXCLUDED.yog, cuts = EXCLUDED.cuts, lcy = EXCLUDED.lcy, computed = EXCLUDED.computed
+		`, analytics)
+		if err != nil {
+			return 0, fmt.Errorf("failed to upsert dividend analytics for %s: %w", key, err)
+		}
+
+		_, err = tx.Exec("UPDATE dividends SET lgr = $1, yog = $2 WHERE ticker = $3 AND exchange = $4", analytics.CAGR5, analytics.YearsGrowth, ticker, exchange)
+		if err != nil {
+			return 0, fmt.Errorf("failed to update dividend growth for %s: %w", key, err)
+		}
+
+		updated++
+	}
+
+	return updated, nil
+}
+
+func GetDividendAnalytics(db *sqlx.DB, input string) (*DividendAnalytics, error) {
+	// Parse the input into ticker and exchange
+	parts := strings.Split(input, ":")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid input format, expected: ticker:exchange")
+	}
+	ticker, exchange := parts[0], parts[1]
+
+	analytics := []*DividendAnalytics{}
+	err := db.Select(&analytics, `
+		SELECT ticker, exchange, cagr1, cagr3, cagr5, cagr10, yog, cuts, lcy, computed
+		FROM dividend_analytics
+		WHERE ticker = $1 AND exchange = $2
+	`, ticker, exchange)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dividend analytics: %w", err)
+	}
+
+	if len(analytics) == 0 {
+		return nil, nil
+	}
+
+	return analytics[0], nil
+}
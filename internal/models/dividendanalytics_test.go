@@ -0,0 +1,80 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+// quarterly pays amount four times a year for each year and amount of payouts
+func quarterly(payouts map[int]int) []DividendEvent {
+	events := []DividendEvent{}
+	for year, amount := range payouts {
+		for _, month := range []time.Month{time.March, time.June, time.September, time.December} {
+			events = append(events, DividendEvent{
+				ExDivDate: time.Date(year, month, 1, 0, 0, 0, 0, time.UTC),
+				Amount:    amount,
+				Type:      string(DistributionRegular),
+			})
+		}
+	}
+	return events
+}
+
+func TestAnalyzeDividendEvents(t *testing.T) {
+	now := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		payouts map[int]int
+		cagr1   NullableInt
+		streak  int
+		cuts    int
+		lastCut NullableInt
+	}{
+		{
+			name:    "growing",
+			payouts: map[int]int{2020: 10, 2021: 10, 2022: 11, 2023: 12, 2024: 15},
+			cagr1:   ValidInt(2500),
+			streak:  3,
+		},
+		{
+			name:    "cut",
+			payouts: map[int]int{2020: 10, 2021: 10, 2022: 12, 2023: 6, 2024: 7},
+			cagr1:   ValidInt(1667),
+			streak:  1,
+			cuts:    1,
+			lastCut: ValidInt(2023),
+		},
+		{
+			name:    "suspended in the last full year",
+			payouts: map[int]int{2020: 10, 2021: 10, 2022: 12, 2023: 12},
+			cuts:    1,
+			lastCut: ValidInt(2024),
+		},
+		{
+			name:    "suspended for years",
+			payouts: map[int]int{2019: 10, 2020: 10, 2021: 8},
+			cuts:    2,
+			lastCut: ValidInt(2022),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			analytics := AnalyzeDividendEvents("TEST", "NYSE", quarterly(test.payouts), now)
+
+			if analytics.CAGR1 != test.cagr1 {
+				t.Errorf("expected cagr1 %v, got %v", test.cagr1, analytics.CAGR1)
+			}
+			if analytics.YearsGrowth != test.streak {
+				t.Errorf("expected a streak of %d, got %d", test.streak, analytics.YearsGrowth)
+			}
+			if analytics.Cuts != test.cuts {
+				t.Errorf("expected %d cuts, got %d", test.cuts, analytics.Cuts)
+			}
+			if analytics.LastCutYear != test.lastCut {
+				t.Errorf("expected the last cut in %v, got %v", test.lastCut, analytics.LastCutYear)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// DividendEvent is a single dividend payment as listed in the history of a security
+type DividendEvent struct {
+	Ticker     string       `db:"ticker" json:"ticker,omitempty"`
+	Exchange   string       `db:"exchange" json:"exchange,omitempty"`
+	ExDivDate  time.Time    `db:"edd" json:"exDivDate"`
+	PayoutDate NullableTime `db:"pd" json:"payoutDate,omitempty"`
+	Amount     int          `db:"amount" json:"amount"`
+	Type       string       `db:"type" json:"type"` // Enum: DistributionType
+}
+
+// InsertDividendEvents upserts the payment history, events already stored are refreshed with the scraped values
+func InsertDividendEvents(tx *sqlx.Tx, events []DividendEvent) error {
+	query := `
+		INSERT INTO dividend_events (ticker, exchange, edd, pd, amount, type)
+		VALUES (:ticker, :exchange, :edd, :pd, :amount, :type)
+		ON CONFLICT (ticker, exchange, edd, type) DO UPDATE SET
+			pd = EXCLUDED.pd, amount = EXCLUDED.amount
+	`
+
+	for _, event := range events {
+		_, err := tx.NamedExec(query, event)
+		if err != nil {
+			return fmt.Errorf("failed to insert dividend event for %s (%s) on %s: %w", event.Ticker, event.Exchange, event.ExDivDate.Format("2006-01-02"), err)
+		}
+	}
+
+	return nil
+}
+
+// GetDividendEvents returns the payment history of a security, newest first
+func GetDividendEvents(db *sqlx.DB, input string) ([]DividendEvent, error) {
+	// Parse the input into ticker and exchange
+	parts := strings.Split(input, ":")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid input format, expected: ticker:exchange")
+	}
+	ticker, exchange := parts[0], parts[1]
+
+	query := `
+		SELECT ticker, exchange, edd, pd, amount, type
+		FROM dividend_events
+		WHERE ticker = $1 AND exchange = $2
+		ORDER BY edd DESC
+	`
+
+	events := []DividendEvent{}
+	err := db.Select(&events, query, ticker, exchange)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dividend events: %w", err)
+	}
+
+	return events, nil
+}
@@ -1,9 +1,11 @@
 package models
 
 import (
+	"database/sql"
 	"errors"
 	"fmt"
 	"math"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -159,16 +161,101 @@ func (s *SelectedSecurityView) Scan(rows *sqlx.Rows) error {
 }
 
 type CalcInput struct {
-	SID                 string  `form:"sid"`
-	Rate                float64 `form:"rate"`
-	CompundingFrequency string  `form:"compoundingfrequency"`
-	Principal           float64 `form:"principal"`
-	Currency            string  `form:"currency"`
-	ContribFrequency    string  `form:"contribfrequency"`
-	Contribution        float64 `form:"contribution"`
-	PriceMod            float64 `form:"pricemod"`
-	YieldMod            float64 `form:"yieldmod"`
-	Years               int     `form:"years"`
+	SID                 string   `form:"sid" json:"sid"`
+	Rate                float64  `form:"rate" json:"rate"`
+	CompundingFrequency string   `form:"compoundingfrequency" json:"compoundingfrequency"`
+	Principal           float64  `form:"principal" json:"principal"`
+	Currency            string   `form:"currency" json:"currency"`
+	ContribFrequency    string   `form:"contribfrequency" json:"contribfrequency"`
+	Contribution        float64  `form:"contribution" json:"contribution"`
+	PriceMod            float64  `form:"pricemod" json:"pricemod"`
+	YieldMod            float64  `form:"yieldmod" json:"yieldmod"`
+	Years               int      `form:"years" json:"years"`
+	Simulate            bool     `form:"simulate" json:"simulate"`
+	Paths               int      `form:"paths" json:"paths"`
+	Target              float64  `form:"target" json:"target"`
+	AccountType         string   `form:"account" json:"account"`     // Empty for a pre-tax projection
+	Residence           string   `form:"residence" json:"residence"` // Country code of the holder
+	IncomeTax           float64  `form:"incometax" json:"incometax"`
+	DividendTax         float64  `form:"dividendtax" json:"dividendtax"`
+	CapitalGainsTax     float64  `form:"capitalgainstax" json:"capitalgainstax"`
+	Withdraw            string   `form:"withdraw" json:"withdraw"` // Withdrawal strategy, empty for no withdrawal phase
+	WithdrawalAmount    float64  `form:"withdrawalamount" json:"withdrawalamount"`
+	WithdrawalRate      float64  `form:"withdrawalrate" json:"withdrawalrate"`
+	Inflation           *float64 `form:"inflation" json:"inflation"` // Nil for the default inflation of the currency
+	WithdrawalYears     int      `form:"withdrawalyears" json:"withdrawalyears"`
+	Solve               string   `form:"solve" json:"solve"` // Input the goal is solved for, empty to project the inputs as given
+	Goal                string   `form:"goal" json:"goal"`
+	GoalAmount          float64  `form:"goalamount" json:"goalamount"`
+	Backtest            bool     `form:"backtest" json:"backtest"` // Replay the plan on the history of the security next to its projection
+	Since               string   `form:"since" json:"since"`       // First month of the backtest, as YYYY-MM
+	DRIP                bool     `form:"drip" json:"drip"`         // Reinvest the dividends of the backtest
+}
+
+// PortfolioInput is the form of the portfolio calculator, Holdings has a "TICKER:EXCHANGE WEIGHT" line per security
+type PortfolioInput struct {
+	Holdings         string   `form:"holdings" json:"holdings"`
+	Principal        float64  `form:"principal" json:"principal"`
+	ContribFrequency string   `form:"contribfrequency" json:"contribfrequency"`
+	Contribution     float64  `form:"contribution" json:"contribution"`
+	PriceMod         float64  `form:"pricemod" json:"pricemod"`
+	YieldMod         float64  `form:"yieldmod" json:"yieldmod"`
+	Years            int      `form:"years" json:"years"`
+	Rebalance        string   `form:"rebalance" json:"rebalance"`
+	AccountType      string   `form:"account" json:"account"`
+	Residence        string   `form:"residence" json:"residence"`
+	IncomeTax        float64  `form:"incometax" json:"incometax"`
+	DividendTax      float64  `form:"dividendtax" json:"dividendtax"`
+	CapitalGainsTax  float64  `form:"capitalgainstax" json:"capitalgainstax"`
+	Inflation        *float64 `form:"inflation" json:"inflation"` // Nil for the default inflation of the currency
+}
+
+type HoldingWeight struct {
+	SID    string
+	Weight float64
+}
+
+// ParseHoldings reads the holdings of a portfolio, the weights are scaled to add up to 100
+func (p *PortfolioInput) ParseHoldings() ([]HoldingWeight, error) {
+	holdings := []HoldingWeight{}
+	total := 0.0
+
+	for _, line := range strings.FieldsFunc(p.Holdings, func(r rune) bool { return r == '\n' || r == ';' }) {
+		fields := strings.FieldsFunc(line, func(r rune) bool { return r == ' ' || r == '\t' || r == ',' || r == '\r' })
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid holding: %s, expected: ticker:exchange weight", strings.TrimSpace(line))
+		}
+
+		sid := strings.ToUpper(fields[0])
+		if len(strings.Split(sid, ":")) != 2 {
+			return nil, fmt.Errorf("invalid holding: %s, expected: ticker:exchange", fields[0])
+		}
+
+		weight, err := strconv.ParseFloat(strings.TrimSuffix(fields[1], "%"), 64)
+		if err != nil || weight <= 0 {
+			return nil, fmt.Errorf("invalid weight for %s: %s", sid, fields[1])
+		}
+
+		if slices.ContainsFunc(holdings, func(h HoldingWeight) bool { return h.SID == sid }) {
+			return nil, fmt.Errorf("duplicate holding: %s", sid)
+		}
+
+		holdings = append(holdings, HoldingWeight{SID: sid, Weight: weight})
+		total += weight
+	}
+
+	if len(holdings) == 0 {
+		return nil, fmt.Errorf("no holdings given")
+	}
+
+	for i := range holdings {
+		holdings[i].Weight = holdings[i].Weight / total * 100
+	}
+
+	return holdings, nil
 }
 
 type SecurityVars struct {
@@ -178,19 +265,29 @@ type SecurityVars struct {
 	Frequency    string
 	ExpenseRatio float64
 	PayoutMonth  int
+	Volatility   float64 // Annualized, in percent
+	Country      string  // Country code of the exchange
 }
 
+// MarketVolatility is the annualized volatility assumed for the market when a security has neither price history nor range
+const MarketVolatility = 16.0
+
 func (s *SecurityVars) Scan(rows *sqlx.Rows) error {
 
 	var price int
 	var yield int
 	var er NullableInt
 	var payoutDate NullableTime
+	var yearLow, yearHigh int
+	var beta NullableInt
+	var historyVolatility sql.NullFloat64
 
 	// Scan all fields from the row
 	err := rows.Scan(
 		&price, &s.Currency,
 		&yield, &s.Frequency, &er, &payoutDate,
+		&yearLow, &yearHigh, &beta, &historyVolatility,
+		&s.Country,
 	)
 	if err != nil {
 		return err
@@ -223,6 +320,19 @@ func (s *SecurityVars) Scan(rows *sqlx.Rows) error {
 		s.PayoutMonth = 0
 	}
 
+	// Volatility from the stored daily closes, else from the 52-week range
+	// (a year of a random walk spans about 1.6 standard deviations), else from beta
+	switch {
+	case historyVolatility.Valid:
+		s.Volatility = historyVolatility.Float64 * 100
+	case yearLow > 0 && yearHigh > yearLow:
+		s.Volatility = math.Log(float64(yearHigh)/float64(yearLow)) / 1.6 * 100
+	case beta.Valid && beta.Int64 > 0:
+		s.Volatility = float64(beta.Int64) / 100 * MarketVolatility
+	default:
+		s.Volatility = MarketVolatility
+	}
+
 	return nil
 }
 
@@ -230,6 +340,7 @@ type SecParams struct {
 	Exchange        []string  `query:"exchange"`
 	Country         []string  `query:"country"`
 	Currency        []string  `query:"currency"` // New field
+	Sector          []string  `query:"sector"`   // New field
 	MinPrice        int       `query:"minPrice"`
 	MaxPrice        int       `query:"maxPrice"`
 	Consensus       string    `query:"consensus"`      // New field
@@ -254,6 +365,8 @@ type SecParams struct {
 	MaxYield        int       `query:"maxYield"`        // New field
 	MinPayoutRatio  int       `query:"minPayoutRatio"`  // New field
 	MaxPayoutRatio  int       `query:"maxPayoutRatio"`  // New field
+	MinGrowth       *int      `query:"minGrowth"`       // New field - nil when unset
+	MinYearsGrowth  *int      `query:"minYearsGrowth"`  // New field - nil when unset
 	Frequency       []string  `query:"frequency"`       // New field
 	MinHoldings     int       `query:"minHoldings"`     // New field
 	MaxHoldings     int       `query:"maxHoldings"`     // New field
@@ -269,12 +382,14 @@ type SecParams struct {
 	Order           []string  `query:"order"`
 	Asc             string    `query:"asc"`
 	Limit           int       `query:"limit"`
+	Cursor          *Cursor   `query:"cursor"`
 }
 
 type SecParamsPointers struct {
 	Exchange        *string  `query:"exchange"`
 	Country         *string  `query:"country"`
 	Currency        *string  `query:"currency"` // New field
+	Sector          *string  `query:"sector"`   // New field
 	MinPrice        *float64 `query:"minPrice"`
 	MaxPrice        *float64 `query:"maxPrice"`
 	Consensus       *string  `query:"consensus"`      // New field
@@ -299,6 +414,8 @@ type SecParamsPointers struct {
 	MaxYield        *float64 `query:"maxYield"`        // New field - depends on dividend
 	MinPayoutRatio  *float64 `query:"minPayoutRatio"`  // New field - depends on dividend
 	MaxPayoutRatio  *float64 `query:"maxPayoutRatio"`  // New field - depends on dividend
+	MinGrowth       *float64 `query:"minGrowth"`       // New field - depends on dividend
+	MinYearsGrowth  *int     `query:"minYearsGrowth"`  // New field - depends on dividend
 	Frequency       *string  `query:"frequency"`       // New field - depends on dividend
 	MinHoldings     *float64 `query:"minHoldings"`     // New field
 	MaxHoldings     *float64 `query:"maxHoldings"`     // New field
@@ -314,6 +431,7 @@ type SecParamsPointers struct {
 	Order           *string  `query:"order"`
 	Asc             *string  `query:"asc"`
 	Limit           *int     `query:"limit"`
+	Cursor          *string  `query:"cursor"`
 }
 
 // Possible valid values for Order and Asc fields
@@ -332,12 +450,15 @@ var (
 		"pe":          true, // New field
 		"yield":       true, // New field
 		"payout":      true, // New field
+		"growth":      true, // New field
+		"streak":      true, // New field
 		"holdings":    true, // New field
 		"aum":         true, // New field
 		"expense":     true, // New field
 		"nav":         true, // New field
 		"inception":   true, // New field
 		"pc":          true,
+		"pcp":         true,
 		"ppc":         true,
 		"updated":     true,
 	}
@@ -431,6 +552,7 @@ func (p *SecParamsPointers) Validate() (*SecParams, error) {
 	params.Exchange = parseCSV(p.Exchange, true)
 	params.Country = parseCSV(p.Country, true)
 	params.Currency = parseCSV(p.Currency, true)
+	params.Sector = parseCSV(p.Sector, false)
 	params.Order = parseCSV(p.Order, false)
 	params.Family = parseCSV(p.Family, false)
 	params.Frequency = parseCSV(p.Frequency, false)
@@ -445,11 +567,13 @@ func (p *SecParamsPointers) Validate() (*SecParams, error) {
 	}
 
 	// Validate MinCov and MaxCov
-	if p.MinCov != nil && p.MaxCov != nil {
-		if *p.MinCov > *p.MaxCov {
-			return nil, errors.New("minCov cannot be greater than maxCov")
-		}
+	if p.MinCov != nil && p.MaxCov != nil && *p.MinCov > *p.MaxCov {
+		return nil, errors.New("minCov cannot be greater than maxCov")
+	}
+	if p.MinCov != nil {
 		params.MinCov = *p.MinCov
+	}
+	if p.MaxCov != nil {
 		params.MaxCov = *p.MaxCov
 	}
 
@@ -556,7 +680,7 @@ func (p *SecParamsPointers) Validate() (*SecParams, error) {
 
 	// Validate MinYield and MaxYield
 
-	if p.MinYield != nil {
+	if p.MinYield != nil || p.MaxYield != nil {
 		if !params.Dividend {
 			return nil, errors.New("minYield and maxYield require dividend to be true")
 		}
@@ -570,7 +694,7 @@ func (p *SecParamsPointers) Validate() (*SecParams, error) {
 
 	// Validate MinPayoutRatio and MaxPayoutRatio
 
-	if p.MinPayoutRatio != nil {
+	if p.MinPayoutRatio != nil || p.MaxPayoutRatio != nil {
 		if !params.Dividend {
 			return nil, errors.New("minPayoutRatio and maxPayoutRatio require dividend to be true")
 		}
@@ -581,6 +705,32 @@ func (p *SecParamsPointers) Validate() (*SecParams, error) {
 		}
 	}
 
+	// Validate MinGrowth (5 years growth rate, can be negative)
+	if p.MinGrowth != nil {
+		if !params.Dividend {
+			return nil, errors.New("minGrowth requires dividend to be true")
+		}
+
+		var minGrowth, unused int
+		err = ValidateIntRange(p.MinGrowth, nil, &minGrowth, &unused)
+		if err != nil {
+			return nil, err
+		}
+		params.MinGrowth = &minGrowth
+	}
+
+	// Validate MinYearsGrowth
+	if p.MinYearsGrowth != nil {
+		if !params.Dividend {
+			return nil, errors.New("minYearsGrowth requires dividend to be true")
+		}
+
+		if *p.MinYearsGrowth < 0 {
+			return nil, errors.New("minYearsGrowth cannot be negative")
+		}
+		params.MinYearsGrowth = p.MinYearsGrowth
+	}
+
 	// Validate Order (must be a list of valid sorting fields)
 	if p.Order != nil {
 		orderList := parseCSV(p.Order, false)
@@ -609,10 +759,43 @@ func (p *SecParamsPointers) Validate() (*SecParams, error) {
 		params.Limit = *p.Limit
 	}
 
+	// Validate Cursor (must come from a page listed with the same ordering)
+	if p.Cursor != nil && strings.TrimSpace(*p.Cursor) != "" {
+		cursor, err := DecodeCursor(strings.TrimSpace(*p.Cursor))
+		if err != nil {
+			return nil, err
+		}
+		if !cursor.Matches(params) {
+			return nil, errors.New("cursor does not match the requested order and asc")
+		}
+		params.Cursor = cursor
+	}
+
 	// All validations passed
 	return params, nil
 }
 
+// ValidateTypology rejects the filters that do not apply to the securities being listed
+func (p *SecParams) ValidateTypology(typology string) error {
+	etfOnly := p.MinHoldings != 0 || p.MaxHoldings != 0 || len(p.Family) > 0 ||
+		p.MinAum != 0 || p.MaxAum != 0 || p.MinExpenseRatio != 0 || p.MaxExpenseRatio != 0 ||
+		p.MinNav != 0 || p.MaxNav != 0 || !p.MinInception.IsZero() || !p.MaxInception.IsZero()
+
+	if typology != "ETF" && etfOnly {
+		return errors.New("holdings, family, aum, expenseRatio, nav and inception filters only apply to ETFs")
+	}
+
+	for _, col := range p.Order {
+		if strings.HasPrefix(OrderColumns[col], "e.") || strings.HasPrefix(OrderColumns[col], "NULLIF(e.") {
+			if typology != "ETF" {
+				return fmt.Errorf("order value %s only applies to ETFs", col)
+			}
+		}
+	}
+
+	return nil
+}
+
 // Helper function to get the keys of a map
 func keys(m map[string]bool) []string {
 	k := make([]string, 0, len(m))
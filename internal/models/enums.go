@@ -2,11 +2,16 @@ package models
 
 import (
 	"errors"
+	"slices"
 	"strings"
 )
 
 type Timing string
 type Frequency string
+type DistributionType string
+
+// Extraction is the method a scraped value was read with
+type Extraction string
 
 const (
 	TimingFWD Timing = "fwd"
@@ -19,6 +24,13 @@ const (
 	FrequencyQuarterly Frequency = "quarterly"
 	FrequencySemi      Frequency = "semi-annual"
 	FrequencyYearly    Frequency = "annual"
+
+	DistributionRegular         DistributionType = "regular"
+	DistributionSpecial         DistributionType = "special"
+	DistributionReturnOfCapital DistributionType = "return-of-capital"
+
+	ExtractionJSON Extraction = "json" // Structured data embedded in the page
+	ExtractionDOM  Extraction = "dom"  // Visible text of the rendered page
 )
 
 func ParseFrequency(frequency string) (Frequency, error) {
@@ -39,3 +51,16 @@ func ParseFrequency(frequency string) (Frequency, error) {
 		return FrequencyUnknown, errors.New("invalid frequency")
 	}
 }
+
+// ParseDistributionType classifies a dividend from the notes found next to it, regular when nothing stands out
+func ParseDistributionType(note string) DistributionType {
+	note = strings.ToLower(note)
+	switch {
+	case strings.Contains(note, "special"), strings.Contains(note, "extra"):
+		return DistributionSpecial
+	case strings.Contains(note, "return of capital"), slices.Contains(strings.Fields(note), "roc"):
+		return DistributionReturnOfCapital
+	default:
+		return DistributionRegular
+	}
+}
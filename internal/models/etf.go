@@ -7,7 +7,6 @@ import (
 
 	"github.com/Francesco99975/finexo/internal/database"
 	"github.com/jmoiron/sqlx"
-	"github.com/lib/pq"
 )
 
 // ETF represents a row from the etfs table.
@@ -176,6 +175,10 @@ func CreateETF(db *sqlx.DB, etf *ETF) error {
 		return err
 	}
 
+	if err := InsertPrice(tx, &etf.Security); err != nil {
+		return err
+	}
+
 	// Step 2: Insert into the etfs table
 	etfsQuery := `
 		INSERT INTO etfs (ticker, exchange, family, holdings, aum, er, nav, inception)
@@ -237,6 +240,10 @@ func UpdateETF(db *sqlx.DB, etf *ETF) error {
 		return err
 	}
 
+	if err := InsertPrice(tx, &etf.Security); err != nil {
+		return err
+	}
+
 	// Step 2: Update the ETFs table
 	query := "UPDATE etfs SET "
 	args := make(map[string]any)
@@ -401,14 +408,7 @@ func GetETF(db *sqlx.DB, input string) (*ETF, error) {
 	return &etf, nil
 }
 
-func GetETFs(
-	db *sqlx.DB,
-	exchange, country []string,
-	minPrice, maxPrice int,
-	orderBy []string, orderDirection string,
-	limit int,
-	dividend bool,
-) ([]ETF, error) {
+func GetETFs(db *sqlx.DB, params *SecParams) (*Page[ETF], error) {
 	// Base query selecting relevant ETF fields, including related securities
 	query := `
 		SELECT
@@ -435,100 +435,36 @@ func GetETFs(
 		INNER JOIN etfs e ON s.ticker = e.ticker AND s.exchange = e.exchange
 		LEFT JOIN etf_related_securities er ON e.ticker = er.etf_ticker AND e.exchange = er.etf_exchange
 	`
+	from := " FROM securities s INNER JOIN etfs e ON s.ticker = e.ticker AND s.exchange = e.exchange"
 
 	// Adjust JOIN type based on dividend presence
-	if dividend {
-		query += " INNER JOIN dividends d ON s.ticker = d.ticker AND s.exchange = d.exchange"
-	} else {
-		query += " LEFT JOIN dividends d ON s.ticker = d.ticker AND s.exchange = d.exchange"
+	join := " LEFT JOIN dividends d ON s.ticker = d.ticker AND s.exchange = d.exchange"
+	if params.Dividend {
+		join = " INNER JOIN dividends d ON s.ticker = d.ticker AND s.exchange = d.exchange"
 	}
+	query += join
 
-	// WHERE conditions
-	query += `
-		WHERE s.typology = 'ETF'
-		AND (array_length(COALESCE($1, ARRAY[]::text[]), 1) = 0 OR s.exchange = ANY($1::text[]))
-		AND (array_length(COALESCE($2, ARRAY[]::text[]), 1) = 0 OR s.exchange IN (SELECT title FROM exchanges WHERE cc = ANY($2::text[])))
-		AND CAST($3 AS NUMERIC) = -1 OR s.price >= CAST($3 AS NUMERIC)
-		AND CAST($4 AS NUMERIC) = -1 OR s.price <= CAST($4 AS NUMERIC)
-	`
-
-	// Grouping by ETF to ensure `STRING_AGG()` works correctly
-	query += " GROUP BY s.ticker, s.exchange, e.holdings, e.family, e.aum, e.er, e.nav, e.inception, d.yield, d.tm, d.ap, d.pr, d.lgr, d.yog, d.lad, d.frequency, d.edd, d.pd"
-
-	// Apply ordering
-	orderColumn := map[string]string{
-		"price":       "s.price",
-		"consensus":   "s.consensus", // New field
-		"score":       "s.score",     // New field
-		"coverage":    "s.coverage",  // New field
-		"volume":      "s.volume",
-		"avgvolume":   "s.avgvolume",
-		"marketcap":   "s.cap",
-		"outstanding": "s.outstanding", // New field
-		"beta":        "s.beta",        // New field
-		"eps":         "s.eps",         // New field
-		"pe":          "s.pe",          // New field
-		"yield":       "d.yield",       // New field
-		"payout":      "d.pr",          // New field
-		"holdings":    "e.holdings",    // New field
-		"aum":         "e.aum",         // New field
-		"expense":     "e.er",          // New field
-		"nav":         "e.nav",         // New field
-		"inception":   "e.inception",   // New field
-		"pc":          "s.pc",
-		"pcp":         "s.pcp",
-		"updated":     "s.updated",
-	}
-
-	order := "s.price ASC"
-	if len(orderBy) > 0 {
-		for _, col := range orderBy {
-			if colx, exists := orderColumn[col]; exists {
-				if orderDirection == "desc" {
-					order = fmt.Sprintf("%s DESC", colx)
-				} else {
-					order = fmt.Sprintf("%s ASC", colx)
-				}
-			}
-		}
-	}
-	query += fmt.Sprintf(" ORDER BY %s", order)
+	filters := NewSecurityQuery(params, "ETF")
 
-	// PostgreSQL does NOT support named parameters in LIMIT
-	if limit > 0 {
-		query += fmt.Sprintf(" LIMIT %d", limit) // Convert to integer before execution
+	// Count every match before the cursor narrows the query down to the page
+	var total int
+	err := db.Get(&total, "SELECT COUNT(*)"+from+join+filters.Clause(), filters.Args()...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count ETFs: %w", err)
 	}
 
-	// Handle empty slices by converting them to PostgreSQL-friendly empty arrays
-	var exchangeArray any = "{}" // PostgreSQL empty array format
-	var countryArray any = "{}"
-
-	if len(exchange) > 0 {
-		exchangeArray = pq.Array(exchange) // Use pq.Array() only when non-empty
-	}
-	if len(country) > 0 {
-		countryArray = pq.Array(country)
+	if params.Cursor != nil {
+		filters.After(params, params.Cursor)
 	}
 
-	// Define "unset" sentinel values
-	const unsetPrice = -1
+	query += filters.Clause()
 
-	// If minPrice or maxPrice are 0, replace with -1
-	minP := minPrice
-	maxP := maxPrice
-	if minPrice == 0 {
-		minP = unsetPrice
-	}
-	if maxPrice == 0 {
-		maxP = unsetPrice
-	}
+	// Grouping by ETF to ensure `STRING_AGG()` works correctly
+	query += " GROUP BY s.ticker, s.exchange, e.holdings, e.family, e.aum, e.er, e.nav, e.inception, d.yield, d.tm, d.ap, d.pr, d.lgr, d.yog, d.lad, d.frequency, d.edd, d.pd"
 
-	args := []any{
-		exchangeArray, // Wrap slices in pq.Array() for PostgreSQL compatibility
-		countryArray,
-		minP, // Now it defaults to -1 instead of 0
-		maxP,
-	}
+	query += OrderClause(params)
+	query += LimitClause(params)
+	args := filters.Args()
 
 	// Execute query using `Queryx`, NOT `NamedQuery`
 	rows, err := db.Queryx(query, args...)
@@ -551,5 +487,7 @@ func GetETFs(
 		return nil, fmt.Errorf("error iterating over ETF rows: %w", err)
 	}
 
-	return etfs, nil
+	return NewPage(params, etfs, total, func(etf *ETF) (string, error) {
+		return NextCursor(params, &etf.Security, etf)
+	})
 }
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"database/sql/driver"
 	"encoding/json"
+	"time"
 )
 
 type NullableString sql.NullString
@@ -16,6 +17,16 @@ func (ns NullableString) MarshalJSON() ([]byte, error) {
 	return json.Marshal(nil) // Return null if invalid
 }
 
+// Implement json.Unmarshaler for NullableString
+func (ns *NullableString) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*ns = NullableString{Valid: false}
+		return nil
+	}
+	ns.Valid = true
+	return json.Unmarshal(data, &ns.String)
+}
+
 // Implement sql.Scanner for NullableString
 func (ns *NullableString) Scan(value any) error {
 	return (*sql.NullString)(ns).Scan(value)
@@ -38,6 +49,16 @@ func (ni NullableInt) MarshalJSON() ([]byte, error) {
 	return json.Marshal(nil) // Return null if invalid
 }
 
+// Implement json.Unmarshaler for NullableInt
+func (ni *NullableInt) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*ni = NullableInt{Valid: false}
+		return nil
+	}
+	ni.Valid = true
+	return json.Unmarshal(data, &ni.Int64)
+}
+
 // Implement sql.Scanner for NullableInt
 func (ni *NullableInt) Scan(value any) error {
 	return (*sql.NullInt64)(ni).Scan(value)
@@ -59,6 +80,16 @@ func (nt NullableTime) MarshalJSON() ([]byte, error) {
 	return json.Marshal(nil)
 }
 
+// Implement json.Unmarshaler for NullableTime
+func (nt *NullableTime) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*nt = NullableTime{Valid: false}
+		return nil
+	}
+	nt.Valid = true
+	return json.Unmarshal(data, &nt.Time)
+}
+
 // Implement sql.Scanner for NullableTime
 func (nt *NullableTime) Scan(value any) error {
 	return (*sql.NullTime)(nt).Scan(value)
@@ -70,3 +101,18 @@ func (nt NullableTime) Value() (driver.Value, error) {
 	nullTime := sql.NullTime(nt) // Convert to sql.NullTime
 	return nullTime.Value()      // Call the Value method of sql.NullTime
 }
+
+// ValidString wraps a scraped string into a valid NullableString
+func ValidString(s string) NullableString {
+	return NullableString{String: s, Valid: true}
+}
+
+// ValidInt wraps a scraped integer into a valid NullableInt
+func ValidInt(i int64) NullableInt {
+	return NullableInt{Int64: i, Valid: true}
+}
+
+// ValidTime wraps a scraped date into a valid NullableTime
+func ValidTime(t time.Time) NullableTime {
+	return NullableTime{Time: t, Valid: true}
+}
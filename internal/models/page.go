@@ -0,0 +1,234 @@
+package models
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Page is the envelope of the list endpoints.
+// NextCursor is empty on the last page.
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	Count      int    `json:"count"`
+	Total      int    `json:"total"`
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// Cursor points right after the last row of a page, it is only valid for the ordering it was created with
+type Cursor struct {
+	Order    []string `json:"o"`
+	Asc      string   `json:"a"`
+	Values   []any    `json:"v"` // Values of the sort columns of the last row, nil for NULL
+	Ticker   string   `json:"t"`
+	Exchange string   `json:"e"`
+}
+
+func EncodeCursor(cursor *Cursor) (string, error) {
+	raw, err := json.Marshal(cursor)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cursor: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func DecodeCursor(encoded string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	// Numbers are kept as strings so that BIGINT values survive the round trip
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	var cursor Cursor
+	if err := decoder.Decode(&cursor); err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	for i, value := range cursor.Values {
+		if number, ok := value.(json.Number); ok {
+			cursor.Values[i] = number.String()
+		}
+	}
+
+	if cursor.Ticker == "" || cursor.Exchange == "" {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	return &cursor, nil
+}
+
+// Matches tells whether the cursor was created for the same ordering as the params
+func (c *Cursor) Matches(params *SecParams) bool {
+	keys, _ := SortKeys(params)
+	return slices.Equal(c.Order, keys) && c.Asc == params.Asc && len(c.Values) == len(keys)
+}
+
+// SortKeys are the order values in use, price ascending when none is requested
+func SortKeys(params *SecParams) ([]string, bool) {
+	keys := []string{}
+	for _, col := range params.Order {
+		if _, ok := OrderColumns[col]; ok {
+			keys = append(keys, col)
+		}
+	}
+
+	if len(keys) == 0 {
+		return []string{"price"}, false
+	}
+
+	return keys, params.Asc == "desc"
+}
+
+// sortValue returns the value of an order column for a listed security, etf is nil for stocks and REITs
+func sortValue(key string, security *Security, etf *ETF) any {
+	value := func(valuer driver.Valuer) any {
+		v, _ := valuer.Value()
+		return v
+	}
+
+	dividend := security.Dividend
+	if dividend == nil {
+		dividend = &Dividend{}
+		switch key {
+		case "yield", "payout", "growth", "streak":
+			return nil
+		}
+	}
+
+	if etf == nil {
+		etf = &ETF{}
+	}
+
+	switch key {
+	case "price":
+		return security.Price
+	case "consensus":
+		return value(security.Consensus)
+	case "score":
+		return value(security.Score)
+	case "coverage":
+		return value(security.Coverage)
+	case "volume":
+		return value(security.Volume)
+	case "avgvolume":
+		return value(security.AvgVolume)
+	case "marketcap":
+		return value(security.MarketCap)
+	case "outstanding":
+		return value(security.Outstanding)
+	case "beta":
+		return value(security.Beta)
+	case "eps":
+		return value(security.EPS)
+	case "pe":
+		return value(security.PE)
+	case "yield":
+		return dividend.Yield
+	case "payout":
+		return value(dividend.PayoutRatio)
+	case "growth":
+		return value(dividend.GrowthRate)
+	case "streak":
+		return value(dividend.YearsGrowth)
+	case "holdings":
+		return etf.Holdings
+	case "aum":
+		return value(etf.AUM)
+	case "expense":
+		return value(etf.ExpenseRatio)
+	case "nav":
+		return value(etf.NAV)
+	case "inception":
+		return value(etf.InceptionDate)
+	case "pc":
+		return security.PC
+	case "pcp", "ppc":
+		return security.PCP
+	case "updated":
+		return security.Updated
+	default:
+		return nil
+	}
+}
+
+// NextCursor builds the cursor of the row after which the following page starts
+func NextCursor(params *SecParams, security *Security, etf *ETF) (string, error) {
+	keys, _ := SortKeys(params)
+
+	cursor := &Cursor{
+		Order:    keys,
+		Asc:      params.Asc,
+		Values:   make([]any, len(keys)),
+		Ticker:   security.Ticker,
+		Exchange: security.Exchange,
+	}
+
+	for i, key := range keys {
+		cursor.Values[i] = sortValue(key, security, etf)
+	}
+
+	return EncodeCursor(cursor)
+}
+
+// After restricts the query to the rows following the cursor in the order given by OrderClause,
+// where every sort column puts its NULLs last and ticker, exchange break the ties
+func (q *SecurityQuery) After(params *SecParams, cursor *Cursor) {
+	keys, desc := SortKeys(params)
+
+	comparison := ">"
+	if desc {
+		comparison = "<"
+	}
+
+	var alternatives []string
+	var equal []string
+
+	for i, key := range keys {
+		column := OrderColumns[key]
+
+		if cursor.Values[i] == nil {
+			// Nothing comes after NULL on this column, only ties carry on
+			equal = append(equal, column+" IS NULL")
+			continue
+		}
+
+		after := q.placeholders(fmt.Sprintf("(%s IS NULL OR %s %s %%s)", column, column, comparison), cursor.Values[i])
+		alternatives = append(alternatives, strings.Join(append(slices.Clone(equal), after), " AND "))
+		equal = append(equal, q.placeholders(column+" = %s", cursor.Values[i]))
+	}
+
+	alternatives = append(alternatives, strings.Join(append(slices.Clone(equal), q.placeholders("s.ticker > %s", cursor.Ticker)), " AND "))
+	equal = append(equal, q.placeholders("s.ticker = %s", cursor.Ticker))
+	alternatives = append(alternatives, strings.Join(append(slices.Clone(equal), q.placeholders("s.exchange > %s", cursor.Exchange)), " AND "))
+
+	q.conditions = append(q.conditions, "(("+strings.Join(alternatives, ") OR (")+"))")
+}
+
+// NewPage trims the extra row fetched by LimitClause and turns it into the cursor of the next page
+func NewPage[T any](params *SecParams, items []T, total int, cursor func(item *T) (string, error)) (*Page[T], error) {
+	page := &Page[T]{Items: items, Total: total}
+
+	if params.Limit > 0 && len(items) > params.Limit {
+		page.Items = items[:params.Limit]
+
+		next, err := cursor(&page.Items[len(page.Items)-1])
+		if err != nil {
+			return nil, err
+		}
+		page.NextCursor = next
+	}
+
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+	page.Count = len(page.Items)
+
+	return page, nil
+}
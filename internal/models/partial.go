@@ -0,0 +1,79 @@
+package models
+
+// PartialSecurity holds whatever a single data source managed to scrape for a security.
+// Every field is optional: the merger decides, field by field, which source wins.
+type PartialSecurity struct {
+	Source string `json:"source"`
+	// Extractions tells how each value was read, keyed by the json name of its field
+	Extractions map[string]Extraction `json:"extractions,omitempty"`
+
+	// Security fields
+	Typology    NullableString `json:"typology"`
+	Currency    NullableString `json:"currency"`
+	FullName    NullableString `json:"fullName"`
+	Sector      NullableString `json:"sector"`
+	Industry    NullableString `json:"industry"`
+	SubIndustry NullableString `json:"subIndustry"`
+	Price       NullableInt    `json:"price"`
+	PC          NullableInt    `json:"pc"`
+	PCP         NullableInt    `json:"pcp"`
+	YearLow     NullableInt    `json:"yearLow"`
+	YearHigh    NullableInt    `json:"yearHigh"`
+	DayLow      NullableInt    `json:"dayLow"`
+	DayHigh     NullableInt    `json:"dayHigh"`
+	Consensus   NullableString `json:"consensus"`
+	Score       NullableInt    `json:"score"`
+	Coverage    NullableInt    `json:"coverage"`
+	MarketCap   NullableInt    `json:"marketCap"`
+	Volume      NullableInt    `json:"volume"`
+	AvgVolume   NullableInt    `json:"avgVolume"`
+	Outstanding NullableInt    `json:"outstanding"`
+	Beta        NullableInt    `json:"beta"`
+	PClose      NullableInt    `json:"previousClose"`
+	COpen       NullableInt    `json:"currentOpen"`
+	Bid         NullableInt    `json:"bid"`
+	BidSize     NullableInt    `json:"bidSize"`
+	Ask         NullableInt    `json:"ask"`
+	AskSize     NullableInt    `json:"askSize"`
+	EPS         NullableInt    `json:"eps"`
+	PE          NullableInt    `json:"pe"`
+	Target      NullableInt    `json:"target"`
+	STM         NullableString `json:"stm"`
+	MarketDate  NullableTime   `json:"marketDate"`
+
+	// Dividend fields
+	Yield          NullableInt     `json:"yield"`
+	DividendTiming NullableString  `json:"dividendTiming"`
+	PayoutRatio    NullableInt     `json:"payoutRatio"`
+	LastAnnounced  NullableInt     `json:"lastAnnounced"`
+	Frequency      NullableString  `json:"frequency"`
+	ExDivDate      NullableTime    `json:"exDivDate"`
+	PayoutDate     NullableTime    `json:"payoutDate"`
+	DividendEvents []DividendEvent `json:"dividendEvents"`
+
+	// ETF fields
+	Family        NullableString   `json:"family"`
+	AUM           NullableInt      `json:"aum"`
+	ExpenseRatio  NullableInt      `json:"expenseRatio"`
+	NAV           NullableInt      `json:"nav"`
+	InceptionDate NullableTime     `json:"inception"`
+	Holdings      []PartialHolding `json:"holdings"`
+}
+
+// PartialHolding is a raw top holding of an ETF as scraped, before its exchange is resolved.
+type PartialHolding struct {
+	Seed       string `json:"seed"`
+	Allocation int    `json:"allocation"`
+}
+
+func NewPartialSecurity(source string) *PartialSecurity {
+	return &PartialSecurity{Source: source}
+}
+
+// Extracted records the method the value of the field was read with
+func (p *PartialSecurity) Extracted(field string, method Extraction) {
+	if p.Extractions == nil {
+		p.Extractions = make(map[string]Extraction)
+	}
+	p.Extractions[field] = method
+}
@@ -0,0 +1,119 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Francesco99975/finexo/internal/helpers"
+	"github.com/jmoiron/sqlx"
+)
+
+// Price is an OHLCV bar, values in cents
+type Price struct {
+	Date   time.Time   `db:"date" json:"date"`
+	Open   int         `db:"open" json:"open"`
+	High   int         `db:"high" json:"high"`
+	Low    int         `db:"low" json:"low"`
+	Close  int         `db:"close" json:"close"`
+	Volume NullableInt `db:"volume" json:"volume,omitempty"`
+}
+
+var PriceIntervals = map[string]string{
+	"day":   "day",
+	"week":  "week",
+	"month": "month",
+}
+
+// InsertPrice records the bar of the session the quote was scraped from, a later scrape of the same session overwrites it.
+// Without a known session nothing is recorded, a scrape on a weekend or a holiday would otherwise add a bar for a closed market.
+func InsertPrice(tx *sqlx.Tx, security *Security) error {
+	if !security.MarketDate.Valid {
+		return nil
+	}
+
+	query := `
+		INSERT INTO prices (ticker, exchange, date, open, high, low, close, volume)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (ticker, exchange, date) DO UPDATE SET
+			open = EXCLUDED.open, high = EXCLUDED.high, low = EXCLUDED.low,
+			close = EXCLUDED.close, volume = EXCLUDED.volume
+	`
+
+	_, err := tx.Exec(query, security.Ticker, security.Exchange, security.MarketDate.Time, security.COpen, security.DayHigh, security.DayLow, security.Price, security.Volume)
+	if err != nil {
+		return fmt.Errorf("failed to insert price: %w", err)
+	}
+
+	return nil
+}
+
+// GetPriceHistory aggregates the daily bars of a security of the given typology between from and to (inclusive)
+func GetPriceHistory(db *sqlx.DB, typology string, input string, from time.Time, to time.Time, interval string) ([]Price, error) {
+	// Parse the input into ticker and exchange
+	parts := strings.Split(input, ":")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid input format, expected: ticker:exchange")
+	}
+	ticker, exchange := parts[0], parts[1]
+
+	unit, ok := PriceIntervals[interval]
+	if !ok {
+		return nil, fmt.Errorf("invalid interval: %s", interval)
+	}
+
+	// The interval is whitelisted above, date_trunc does not accept it as a parameter inside GROUP BY
+	query := fmt.Sprintf(`
+		SELECT
+			date_trunc('%[1]s', p.date)::date AS date,
+			(array_agg(p.open ORDER BY p.date ASC))[1] AS open,
+			MAX(p.high) AS high,
+			MIN(p.low) AS low,
+			(array_agg(p.close ORDER BY p.date DESC))[1] AS close,
+			SUM(p.volume)::bigint AS volume
+		FROM prices p
+		JOIN securities s ON s.ticker = p.ticker AND s.exchange = p.exchange
+		WHERE p.ticker = $1 AND p.exchange = $2 AND s.typology = $3
+			AND p.date BETWEEN $4 AND $5
+		GROUP BY date_trunc('%[1]s', p.date)
+		ORDER BY date ASC
+	`, unit)
+
+	prices := []Price{}
+	err := db.Select(&prices, query, ticker, exchange, typology, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get price history: %w", err)
+	}
+
+	return prices, nil
+}
+
+// GetMonthlyCloses returns the last close of every month of a security since from, oldest first
+func GetMonthlyCloses(db *sqlx.DB, input string, from time.Time) ([]helpers.PricePoint, error) {
+	// Parse the input into ticker and exchange
+	parts := strings.Split(input, ":")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid input format, expected: ticker:exchange")
+	}
+	ticker, exchange := parts[0], parts[1]
+
+	query := `
+		SELECT
+			date_trunc('month', date)::date AS date,
+			(array_agg(close ORDER BY date DESC))[1] AS close
+		FROM prices
+		WHERE ticker = $1 AND exchange = $2 AND date >= $3
+		GROUP BY date_trunc('month', date)
+		ORDER BY date ASC
+	`
+
+	prices := []Price{}
+	err := db.Select(&prices, query, ticker, exchange, from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get monthly closes: %w", err)
+	}
+
+	return helpers.MapSlice(prices, func(p Price) helpers.PricePoint {
+		return helpers.PricePoint{Date: p.Date, Close: float64(p.Close) / 100.0}
+	}), nil
+}
@@ -0,0 +1,174 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// OrderColumns maps the validated order values to the columns of the list queries
+var OrderColumns = map[string]string{
+	"price":       "s.price",
+	"consensus":   "s.consensus",
+	"score":       "s.score",
+	"coverage":    "s.coverage",
+	"volume":      "s.volume",
+	"avgvolume":   "s.avgvolume",
+	"marketcap":   "s.cap",
+	"outstanding": "s.outstanding",
+	"beta":        "s.beta",
+	"eps":         "s.eps",
+	"pe":          "s.pe",
+	"yield":       "d.yield",
+	"payout":      "d.pr",
+	"growth":      "d.lgr",
+	"streak":      "d.yog",
+	"holdings":    "e.holdings",
+	"aum":         "NULLIF(e.aum, '')::bigint",
+	"expense":     "NULLIF(e.er, '')::int",
+	"nav":         "e.nav",
+	"inception":   "e.inception",
+	"pc":          "s.pc",
+	"pcp":         "s.pcp",
+	"ppc":         "s.pcp",
+	"updated":     "s.updated",
+}
+
+// SecurityQuery accumulates the conditions and positional arguments of a list query
+type SecurityQuery struct {
+	conditions []string
+	args       []any
+}
+
+// Where adds a condition, each %s in it is replaced by the placeholder of the matching value
+func (q *SecurityQuery) Where(condition string, values ...any) {
+	q.conditions = append(q.conditions, q.placeholders(condition, values...))
+}
+
+// placeholders binds the values and returns the condition with their positional parameters
+func (q *SecurityQuery) placeholders(condition string, values ...any) string {
+	placeholders := make([]any, len(values))
+	for i, value := range values {
+		q.args = append(q.args, value)
+		placeholders[i] = fmt.Sprintf("$%d", len(q.args))
+	}
+	return fmt.Sprintf(condition, placeholders...)
+}
+
+// WhereIn adds an ANY condition on the column, skipped for an empty list
+func (q *SecurityQuery) WhereIn(column string, values []string) {
+	if len(values) == 0 {
+		return
+	}
+	q.Where(column+" = ANY(%s::text[])", pq.Array(values))
+}
+
+// WhereRange adds the bounds that are set, 0 meaning unset like everywhere else in SecParams
+func WhereRange[T int | int64](q *SecurityQuery, column string, min T, max T) {
+	if min != 0 {
+		q.Where(column+" >= %s", min)
+	}
+	if max != 0 {
+		q.Where(column+" <= %s", max)
+	}
+}
+
+func (q *SecurityQuery) Clause() string {
+	if len(q.conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(q.conditions, " AND ")
+}
+
+func (q *SecurityQuery) Args() []any {
+	return q.args
+}
+
+// NewSecurityQuery turns the validated params into the WHERE clause of the list query of a typology.
+// The query must alias securities as s, dividends as d and, for ETFs, etfs as e.
+func NewSecurityQuery(params *SecParams, typology string) *SecurityQuery {
+	q := &SecurityQuery{}
+
+	q.Where("s.typology = %s", typology)
+
+	q.WhereIn("s.exchange", params.Exchange)
+	if len(params.Country) > 0 {
+		q.Where("s.exchange IN (SELECT title FROM exchanges WHERE cc = ANY(%s::text[]))", pq.Array(params.Country))
+	}
+	q.WhereIn("UPPER(s.currency)", params.Currency)
+	q.WhereIn("LOWER(s.sector)", params.Sector)
+
+	if params.Consensus != "" {
+		q.Where("UPPER(s.consensus) = %s", params.Consensus)
+	}
+
+	WhereRange(q, "s.price", params.MinPrice, params.MaxPrice)
+	WhereRange(q, "s.score", params.MinScore, params.MaxScore)
+	WhereRange(q, "s.coverage", params.MinCov, params.MaxCov)
+	WhereRange(q, "s.cap", params.MinCap, params.MaxCap)
+	WhereRange(q, "s.volume", params.MinVol, params.MaxVol)
+	WhereRange(q, "s.outstanding", params.MinOutstanding, params.MaxOutstanding)
+	WhereRange(q, "s.beta", params.MinBeta, params.MaxBeta)
+	WhereRange(q, "s.eps", params.MinEps, params.MaxEps)
+	WhereRange(q, "s.pe", params.MinPe, params.MaxPe)
+
+	// Dividend filters, Validate already made sure dividend is requested
+	WhereRange(q, "d.yield", params.MinYield, params.MaxYield)
+	WhereRange(q, "d.pr", params.MinPayoutRatio, params.MaxPayoutRatio)
+	q.WhereIn("d.frequency::text", params.Frequency)
+	if params.MinGrowth != nil {
+		q.Where("d.lgr >= %s", *params.MinGrowth)
+	}
+	if params.MinYearsGrowth != nil {
+		q.Where("d.yog >= %s", *params.MinYearsGrowth)
+	}
+
+	// ETF filters, ValidateTypology rejects them for the other typologies
+	if typology != "ETF" {
+		return q
+	}
+
+	// Holdings are a count, Validate scales every float filter by 100
+	WhereRange(q, "e.holdings", params.MinHoldings/100, params.MaxHoldings/100)
+	q.WhereIn("LOWER(e.family)", params.Family)
+	WhereRange(q, "NULLIF(e.aum, '')::bigint", params.MinAum, params.MaxAum)
+	WhereRange(q, "NULLIF(e.er, '')::int", params.MinExpenseRatio, params.MaxExpenseRatio)
+	WhereRange(q, "e.nav", params.MinNav, params.MaxNav)
+	if !params.MinInception.IsZero() {
+		q.Where("e.inception >= %s", params.MinInception)
+	}
+	if !params.MaxInception.IsZero() {
+		q.Where("e.inception <= %s", params.MaxInception)
+	}
+
+	return q
+}
+
+// OrderClause orders by every requested column in turn, ticker and exchange keep the order stable
+func OrderClause(params *SecParams) string {
+	keys, desc := SortKeys(params)
+
+	direction := "ASC"
+	if desc {
+		direction = "DESC"
+	}
+
+	order := []string{}
+	for _, key := range keys {
+		order = append(order, fmt.Sprintf("%s %s NULLS LAST", OrderColumns[key], direction))
+	}
+
+	order = append(order, "s.ticker ASC", "s.exchange ASC")
+
+	return " ORDER BY " + strings.Join(order, ", ")
+}
+
+// LimitClause is appended last, PostgreSQL does NOT support named parameters in LIMIT.
+// One extra row is fetched to know whether there is a next page.
+func LimitClause(params *SecParams) string {
+	if params.Limit <= 0 {
+		return ""
+	}
+	return fmt.Sprintf(" LIMIT %d", params.Limit+1)
+}
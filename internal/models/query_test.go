@@ -0,0 +1,295 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+func TestNewSecurityQuery(t *testing.T) {
+	growth, years := 500, 10
+	inception := time.Date(2010, time.January, 1, 0, 0, 0, 0, time.UTC)
+	latest := time.Date(2020, time.December, 31, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		params   SecParams
+		typology string
+		clause   string
+		args     []any
+	}{
+		{
+			name:     "no filters",
+			typology: "STOCK",
+			clause:   " WHERE s.typology = $1",
+			args:     []any{"STOCK"},
+		},
+		{
+			name:     "exchange",
+			params:   SecParams{Exchange: []string{"NASDAQ", "NYSE"}},
+			typology: "STOCK",
+			clause:   " WHERE s.typology = $1 AND s.exchange = ANY($2::text[])",
+			args:     []any{"STOCK", pq.Array([]string{"NASDAQ", "NYSE"})},
+		},
+		{
+			name:     "country",
+			params:   SecParams{Country: []string{"CA"}},
+			typology: "STOCK",
+			clause:   " WHERE s.typology = $1 AND s.exchange IN (SELECT title FROM exchanges WHERE cc = ANY($2::text[]))",
+			args:     []any{"STOCK", pq.Array([]string{"CA"})},
+		},
+		{
+			name:     "currency",
+			params:   SecParams{Currency: []string{"USD"}},
+			typology: "STOCK",
+			clause:   " WHERE s.typology = $1 AND UPPER(s.currency) = ANY($2::text[])",
+			args:     []any{"STOCK", pq.Array([]string{"USD"})},
+		},
+		{
+			name:     "sector",
+			params:   SecParams{Sector: []string{"energy"}},
+			typology: "STOCK",
+			clause:   " WHERE s.typology = $1 AND LOWER(s.sector) = ANY($2::text[])",
+			args:     []any{"STOCK", pq.Array([]string{"energy"})},
+		},
+		{
+			name:     "consensus",
+			params:   SecParams{Consensus: "BUY"},
+			typology: "STOCK",
+			clause:   " WHERE s.typology = $1 AND UPPER(s.consensus) = $2",
+			args:     []any{"STOCK", "BUY"},
+		},
+		{
+			name:     "price",
+			params:   SecParams{MinPrice: 1000, MaxPrice: 5000},
+			typology: "STOCK",
+			clause:   " WHERE s.typology = $1 AND s.price >= $2 AND s.price <= $3",
+			args:     []any{"STOCK", 1000, 5000},
+		},
+		{
+			name:     "score",
+			params:   SecParams{MinScore: 200},
+			typology: "STOCK",
+			clause:   " WHERE s.typology = $1 AND s.score >= $2",
+			args:     []any{"STOCK", 200},
+		},
+		{
+			name:     "coverage",
+			params:   SecParams{MaxCov: 10},
+			typology: "STOCK",
+			clause:   " WHERE s.typology = $1 AND s.coverage <= $2",
+			args:     []any{"STOCK", 10},
+		},
+		{
+			name:     "market cap",
+			params:   SecParams{MinCap: 1000000000, MaxCap: 5000000000},
+			typology: "STOCK",
+			clause:   " WHERE s.typology = $1 AND s.cap >= $2 AND s.cap <= $3",
+			args:     []any{"STOCK", int64(1000000000), int64(5000000000)},
+		},
+		{
+			name:     "volume",
+			params:   SecParams{MinVol: 100000},
+			typology: "STOCK",
+			clause:   " WHERE s.typology = $1 AND s.volume >= $2",
+			args:     []any{"STOCK", int64(100000)},
+		},
+		{
+			name:     "outstanding",
+			params:   SecParams{MaxOutstanding: 2000000},
+			typology: "STOCK",
+			clause:   " WHERE s.typology = $1 AND s.outstanding <= $2",
+			args:     []any{"STOCK", int64(2000000)},
+		},
+		{
+			name:     "beta",
+			params:   SecParams{MinBeta: 50, MaxBeta: 150},
+			typology: "STOCK",
+			clause:   " WHERE s.typology = $1 AND s.beta >= $2 AND s.beta <= $3",
+			args:     []any{"STOCK", 50, 150},
+		},
+		{
+			name:     "eps",
+			params:   SecParams{MinEps: 100},
+			typology: "STOCK",
+			clause:   " WHERE s.typology = $1 AND s.eps >= $2",
+			args:     []any{"STOCK", 100},
+		},
+		{
+			name:     "pe",
+			params:   SecParams{MaxPe: 2500},
+			typology: "STOCK",
+			clause:   " WHERE s.typology = $1 AND s.pe <= $2",
+			args:     []any{"STOCK", 2500},
+		},
+		{
+			name:     "yield",
+			params:   SecParams{Dividend: true, MinYield: 300, MaxYield: 800},
+			typology: "REIT",
+			clause:   " WHERE s.typology = $1 AND d.yield >= $2 AND d.yield <= $3",
+			args:     []any{"REIT", 300, 800},
+		},
+		{
+			name:     "payout ratio",
+			params:   SecParams{Dividend: true, MaxPayoutRatio: 7500},
+			typology: "STOCK",
+			clause:   " WHERE s.typology = $1 AND d.pr <= $2",
+			args:     []any{"STOCK", 7500},
+		},
+		{
+			name:     "frequency",
+			params:   SecParams{Dividend: true, Frequency: []string{"monthly"}},
+			typology: "REIT",
+			clause:   " WHERE s.typology = $1 AND d.frequency::text = ANY($2::text[])",
+			args:     []any{"REIT", pq.Array([]string{"monthly"})},
+		},
+		{
+			name:     "growth",
+			params:   SecParams{Dividend: true, MinGrowth: &growth, MinYearsGrowth: &years},
+			typology: "STOCK",
+			clause:   " WHERE s.typology = $1 AND d.lgr >= $2 AND d.yog >= $3",
+			args:     []any{"STOCK", 500, 10},
+		},
+		{
+			name:     "holdings",
+			params:   SecParams{MinHoldings: 5000, MaxHoldings: 50000},
+			typology: "ETF",
+			clause:   " WHERE s.typology = $1 AND e.holdings >= $2 AND e.holdings <= $3",
+			args:     []any{"ETF", 50, 500},
+		},
+		{
+			name:     "family",
+			params:   SecParams{Family: []string{"vanguard"}},
+			typology: "ETF",
+			clause:   " WHERE s.typology = $1 AND LOWER(e.family) = ANY($2::text[])",
+			args:     []any{"ETF", pq.Array([]string{"vanguard"})},
+		},
+		{
+			name:     "aum",
+			params:   SecParams{MinAum: 100000000},
+			typology: "ETF",
+			clause:   " WHERE s.typology = $1 AND NULLIF(e.aum, '')::bigint >= $2",
+			args:     []any{"ETF", int64(100000000)},
+		},
+		{
+			name:     "expense ratio",
+			params:   SecParams{MaxExpenseRatio: 20},
+			typology: "ETF",
+			clause:   " WHERE s.typology = $1 AND NULLIF(e.er, '')::int <= $2",
+			args:     []any{"ETF", 20},
+		},
+		{
+			name:     "nav",
+			params:   SecParams{MinNav: 1000, MaxNav: 9000},
+			typology: "ETF",
+			clause:   " WHERE s.typology = $1 AND e.nav >= $2 AND e.nav <= $3",
+			args:     []any{"ETF", 1000, 9000},
+		},
+		{
+			name:     "inception",
+			params:   SecParams{MinInception: inception, MaxInception: latest},
+			typology: "ETF",
+			clause:   " WHERE s.typology = $1 AND e.inception >= $2 AND e.inception <= $3",
+			args:     []any{"ETF", inception, latest},
+		},
+		{
+			name:     "etf filters ignored for other typologies",
+			params:   SecParams{MinNav: 1000, Family: []string{"vanguard"}},
+			typology: "STOCK",
+			clause:   " WHERE s.typology = $1",
+			args:     []any{"STOCK"},
+		},
+		{
+			name:     "combined filters number their placeholders in turn",
+			params:   SecParams{Exchange: []string{"TSX"}, MinPrice: 1000, Dividend: true, MinYield: 400, MaxNav: 5000},
+			typology: "ETF",
+			clause:   " WHERE s.typology = $1 AND s.exchange = ANY($2::text[]) AND s.price >= $3 AND d.yield >= $4 AND e.nav <= $5",
+			args:     []any{"ETF", pq.Array([]string{"TSX"}), 1000, 400, 5000},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			q := NewSecurityQuery(&test.params, test.typology)
+
+			if clause := q.Clause(); clause != test.clause {
+				t.Errorf("expected clause %q, got %q", test.clause, clause)
+			}
+			if !reflect.DeepEqual(q.Args(), test.args) {
+				t.Errorf("expected args %#v, got %#v", test.args, q.Args())
+			}
+		})
+	}
+}
+
+func TestWhereRange(t *testing.T) {
+	tests := []struct {
+		name     string
+		min, max int64
+		clause   string
+		args     []any
+	}{
+		{name: "unset", clause: ""},
+		{name: "min", min: 10, clause: " WHERE s.cap >= $1", args: []any{int64(10)}},
+		{name: "max", max: 20, clause: " WHERE s.cap <= $1", args: []any{int64(20)}},
+		{name: "both", min: 10, max: 20, clause: " WHERE s.cap >= $1 AND s.cap <= $2", args: []any{int64(10), int64(20)}},
+		{name: "negative", min: -20, max: -10, clause: " WHERE s.cap >= $1 AND s.cap <= $2", args: []any{int64(-20), int64(-10)}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			q := &SecurityQuery{}
+			WhereRange(q, "s.cap", test.min, test.max)
+
+			if clause := q.Clause(); clause != test.clause {
+				t.Errorf("expected clause %q, got %q", test.clause, clause)
+			}
+			if !reflect.DeepEqual(q.Args(), test.args) {
+				t.Errorf("expected args %#v, got %#v", test.args, q.Args())
+			}
+		})
+	}
+}
+
+func TestOrderClause(t *testing.T) {
+	tests := []struct {
+		name   string
+		params SecParams
+		order  string
+	}{
+		{
+			name:  "price by default",
+			order: " ORDER BY s.price ASC NULLS LAST, s.ticker ASC, s.exchange ASC",
+		},
+		{
+			name:   "descending",
+			params: SecParams{Order: []string{"yield"}, Asc: "desc"},
+			order:  " ORDER BY d.yield DESC NULLS LAST, s.ticker ASC, s.exchange ASC",
+		},
+		{
+			name:   "multiple columns",
+			params: SecParams{Order: []string{"yield", "marketcap", "aum"}},
+			order:  " ORDER BY d.yield ASC NULLS LAST, s.cap ASC NULLS LAST, NULLIF(e.aum, '')::bigint ASC NULLS LAST, s.ticker ASC, s.exchange ASC",
+		},
+		{
+			name:   "multiple columns descending",
+			params: SecParams{Order: []string{"pe", "beta"}, Asc: "desc"},
+			order:  " ORDER BY s.pe DESC NULLS LAST, s.beta DESC NULLS LAST, s.ticker ASC, s.exchange ASC",
+		},
+		{
+			name:   "unknown columns skipped",
+			params: SecParams{Order: []string{"bogus", "volume"}},
+			order:  " ORDER BY s.volume ASC NULLS LAST, s.ticker ASC, s.exchange ASC",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if order := OrderClause(&test.params); order != test.order {
+				t.Errorf("expected %q, got %q", test.order, order)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// RefreshCandidate is a known security the refresh planner may pick, along with what it is ranked on
+type RefreshCandidate struct {
+	Seed   string
+	Age    time.Duration // Time since the security was last refreshed
+	Volume int64         // Average volume, the last volume when there is none
+	Hits   int64         // Views over the popularity window
+}
+
+// DataAge describes how fresh the stored securities are
+type DataAge struct {
+	Oldest time.Duration // Age of the security refreshed the longest time ago
+	Stale  int           // Securities older than the maximum age
+}
+
+// securitySuffixFilter matches the securities of the exchanges a seed suffix stands for,
+// "." standing for the exchanges without a suffix and "" for every exchange
+const securitySuffixFilter = `($1 = '' OR ($1 = '.' AND e.suffix IS NULL) OR e.suffix = $1)`
+
+// GetRefreshCandidates returns the stored securities of the exchanges matching the seed suffix,
+// each with the seed it is scraped from
+func GetRefreshCandidates(db *sqlx.DB, suffix string) ([]RefreshCandidate, error) {
+	query := fmt.Sprintf(`
+		SELECT
+			s.ticker || COALESCE('.' || e.suffix, '') AS seed,
+			EXTRACT(EPOCH FROM NOW() - s.updated) AS age,
+			COALESCE(s.avgvolume, s.volume, 0) AS volume,
+			COALESCE(h.hits, 0) AS hits
+		FROM securities s
+		JOIN exchanges e ON e.title = s.exchange
+		LEFT JOIN (
+			SELECT ticker, exchange, SUM(hits) AS hits
+			FROM security_hits
+			GROUP BY ticker, exchange
+		) h ON h.ticker = s.ticker AND h.exchange = s.exchange
+		WHERE %s
+	`, securitySuffixFilter)
+
+	rows := []struct {
+		Seed   string  `db:"seed"`
+		Age    float64 `db:"age"`
+		Volume int64   `db:"volume"`
+		Hits   int64   `db:"hits"`
+	}{}
+	err := db.Select(&rows, query, suffix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get refresh candidates: %w", err)
+	}
+
+	candidates := make([]RefreshCandidate, 0, len(rows))
+	for _, row := range rows {
+		candidates = append(candidates, RefreshCandidate{
+			Seed:   row.Seed,
+			Age:    time.Duration(row.Age * float64(time.Second)),
+			Volume: row.Volume,
+			Hits:   row.Hits,
+		})
+	}
+
+	return candidates, nil
+}
+
+// GetDataAge measures the age of the stored securities of the exchanges matching the seed suffix
+func GetDataAge(db *sqlx.DB, suffix string, maxAge time.Duration) (DataAge, error) {
+	query := fmt.Sprintf(`
+		SELECT
+			COALESCE(EXTRACT(EPOCH FROM NOW() - MIN(s.updated)), 0) AS oldest,
+			COUNT(*) FILTER (WHERE s.updated < NOW() - $2 * INTERVAL '1 second') AS stale
+		FROM securities s
+		JOIN exchanges e ON e.title = s.exchange
+		WHERE %s
+	`, securitySuffixFilter)
+
+	var row struct {
+		Oldest float64 `db:"oldest"`
+		Stale  int     `db:"stale"`
+	}
+	err := db.Get(&row, query, suffix, maxAge.Seconds())
+	if err != nil {
+		return DataAge{}, fmt.Errorf("failed to get data age: %w", err)
+	}
+
+	return DataAge{Oldest: time.Duration(row.Oldest * float64(time.Second)), Stale: row.Stale}, nil
+}
@@ -6,7 +6,6 @@ import (
 
 	"github.com/Francesco99975/finexo/internal/database"
 	"github.com/jmoiron/sqlx"
-	"github.com/lib/pq"
 )
 
 // REIT represents a row from the reits table.
@@ -97,6 +96,10 @@ func CreateReit(db *sqlx.DB, reit *REIT) error {
 		return err
 	}
 
+	if err := InsertPrice(tx, &reit.Security); err != nil {
+		return err
+	}
+
 	// Insert into reits table
 	reitsQuery := `
 		INSERT INTO reits (ticker, exchange, occupation, focus, ffo, pffo, tm)
@@ -127,6 +130,10 @@ func UpdateREIT(db *sqlx.DB, reit *REIT) error {
 		return err
 	}
 
+	if err := InsertPrice(tx, &reit.Security); err != nil {
+		return err
+	}
+
 	// Step 2: Update the REITs table
 	query := "UPDATE reits SET "
 	args := make(map[string]any)
@@ -244,14 +251,7 @@ func GetREIT(db *sqlx.DB, input string) (*REIT, error) {
 	return &reit, nil
 }
 
-func GetREITs(
-	db *sqlx.DB,
-	exchange, country []string,
-	minPrice, maxPrice int,
-	orderBy []string, orderDirection string,
-	limit int,
-	dividend bool,
-) ([]REIT, error) {
+func GetREITs(db *sqlx.DB, params *SecParams) (*Page[REIT], error) {
 	// Base query selecting relevant security fields where typology = 'STOCK'
 	query := `
 		SELECT
@@ -271,92 +271,33 @@ func GetREITs(
 		FROM securities s
 		INNER JOIN reits r ON s.ticker = r.ticker AND s.exchange = r.exchange
 	`
+	from := " FROM securities s INNER JOIN reits r ON s.ticker = r.ticker AND s.exchange = r.exchange"
 
 	// Adjust JOIN type based on dividend presence
-	if dividend {
-		query += " INNER JOIN dividends d ON s.ticker = d.ticker AND s.exchange = d.exchange"
-	} else {
-		query += " LEFT JOIN dividends d ON s.ticker = d.ticker AND s.exchange = d.exchange"
+	join := " LEFT JOIN dividends d ON s.ticker = d.ticker AND s.exchange = d.exchange"
+	if params.Dividend {
+		join = " INNER JOIN dividends d ON s.ticker = d.ticker AND s.exchange = d.exchange"
 	}
+	query += join
 
-	// WHERE conditions
-	query += `
-	WHERE s.typology = 'REIT'
-	AND (array_length(COALESCE($1, ARRAY[]::text[]), 1) = 0 OR s.exchange = ANY($1::text[]))
-	AND (array_length(COALESCE($2, ARRAY[]::text[]), 1) = 0 OR s.exchange IN (SELECT title FROM exchanges WHERE cc = ANY($2::text[])))
-	AND CAST($3 AS NUMERIC) = -1 OR s.price >= CAST($3 AS NUMERIC)
-	AND CAST($4 AS NUMERIC) = -1 OR s.price <= CAST($4 AS NUMERIC)
-`
-
-	// Apply ordering
-	orderColumn := map[string]string{
-		"price":       "s.price",
-		"consensus":   "s.consensus", // New field
-		"score":       "s.score",     // New field
-		"coverage":    "s.coverage",  // New field
-		"volume":      "s.volume",
-		"avgvolume":   "s.avgvolume",
-		"marketcap":   "s.cap",
-		"outstanding": "s.outstanding", // New field
-		"beta":        "s.beta",        // New field
-		"eps":         "s.eps",         // New field
-		"pe":          "s.pe",          // New field
-		"yield":       "d.yield",       // New field
-		"payout":      "d.pr",          // New field
-		"pc":          "s.pc",
-		"pcp":         "s.pcp",
-		"updated":     "s.updated",
-	}
+	filters := NewSecurityQuery(params, "REIT")
 
-	order := "s.price ASC" // Default ordering
-	if len(orderBy) > 0 {
-		for _, col := range orderBy {
-			if colx, exists := orderColumn[col]; exists {
-				if orderDirection == "desc" {
-					order = fmt.Sprintf("%s DESC", colx)
-				} else {
-					order = fmt.Sprintf("%s ASC", colx)
-				}
-			}
-		}
-	}
-	query += fmt.Sprintf(" ORDER BY %s", order)
-
-	// PostgreSQL does NOT support named parameters in LIMIT
-	if limit > 0 {
-		query += fmt.Sprintf(" LIMIT %d", limit)
+	// Count every match before the cursor narrows the query down to the page
+	var total int
+	err := db.Get(&total, "SELECT COUNT(*)"+from+join+filters.Clause(), filters.Args()...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count REITs: %w", err)
 	}
 
-	// Handle empty slices by converting them to PostgreSQL-friendly empty arrays
-	var exchangeArray any = "{}" // PostgreSQL empty array format
-	var countryArray any = "{}"
-
-	if len(exchange) > 0 {
-		exchangeArray = pq.Array(exchange) // Use pq.Array() only when non-empty
-	}
-	if len(country) > 0 {
-		countryArray = pq.Array(country)
+	if params.Cursor != nil {
+		filters.After(params, params.Cursor)
 	}
 
-	// Define "unset" sentinel values
-	const unsetPrice = -1
+	query += filters.Clause()
 
-	// If minPrice or maxPrice are 0, replace with -1
-	minP := minPrice
-	maxP := maxPrice
-	if minPrice == 0 {
-		minP = unsetPrice
-	}
-	if maxPrice == 0 {
-		maxP = unsetPrice
-	}
-
-	args := []any{
-		exchangeArray, // Wrap slices in pq.Array() for PostgreSQL compatibility
-		countryArray,
-		minP, // Now it defaults to -1 instead of 0
-		maxP,
-	}
+	query += OrderClause(params)
+	query += LimitClause(params)
+	args := filters.Args()
 
 	// Execute query using `Queryx`, NOT `NamedQuery`
 	rows, err := db.Queryx(query, args...)
@@ -379,5 +320,7 @@ func GetREITs(
 		return nil, fmt.Errorf("error iterating over reit rows: %w", err)
 	}
 
-	return reits, nil
+	return NewPage(params, reits, total, func(reit *REIT) (string, error) {
+		return NextCursor(params, &reit.Security, nil)
+	})
 }
@@ -0,0 +1,124 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Francesco99975/finexo/internal/helpers"
+	"github.com/jmoiron/sqlx"
+)
+
+// Calculators a scenario can be run on
+const (
+	CompoundScenario  = "compound"
+	PortfolioScenario = "portfolio"
+)
+
+const scenarioIDLength = 8
+
+// Scenario stores the inputs of a calculation under a short ID, its results being calculated again when shared
+type Scenario struct {
+	ID      string       `db:"id" json:"id"`
+	Kind    string       `db:"kind" json:"kind"`
+	Inputs  []byte       `db:"inputs" json:"inputs"`
+	Created time.Time    `db:"created" json:"created"`
+	Expires NullableTime `db:"expires" json:"expires,omitempty"`
+}
+
+// SaveScenario stores the inputs of a calculation of the given kind, kept for ttl or forever when ttl is 0
+func SaveScenario(db *sqlx.DB, kind string, inputs any, ttl time.Duration) (string, error) {
+	encoded, err := json.Marshal(inputs)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode scenario inputs: %w", err)
+	}
+
+	var expires NullableTime
+	if ttl > 0 {
+		expires = NullableTime{Time: time.Now().Add(ttl), Valid: true}
+	}
+
+	query := `
+		INSERT INTO scenarios (id, kind, inputs, expires)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (id) DO NOTHING
+	`
+
+	// A new ID is drawn in the unlikely event of a collision
+	for range 3 {
+		id, err := helpers.GenerateShortID(scenarioIDLength)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate scenario id: %w", err)
+		}
+
+		result, err := db.Exec(query, id, kind, string(encoded), expires)
+		if err != nil {
+			return "", fmt.Errorf("failed to insert scenario: %w", err)
+		}
+
+		inserted, err := result.RowsAffected()
+		if err != nil {
+			return "", fmt.Errorf("failed to insert scenario: %w", err)
+		}
+		if inserted > 0 {
+			return id, nil
+		}
+	}
+
+	return "", fmt.Errorf("failed to insert scenario: no free id")
+}
+
+// GetScenario returns the scenario with the given ID, nil when there is none or it expired
+func GetScenario(db *sqlx.DB, id string) (*Scenario, error) {
+	query := `
+		SELECT id, kind, inputs, created, expires
+		FROM scenarios
+		WHERE id = $1 AND (expires IS NULL OR expires > NOW())
+	`
+
+	scenarios := []*Scenario{}
+	err := db.Select(&scenarios, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scenario: %w", err)
+	}
+
+	if len(scenarios) == 0 {
+		return nil, nil
+	}
+
+	return scenarios[0], nil
+}
+
+// DeleteExpiredScenarios removes the scenarios past their expiry, returning how many there were
+func DeleteExpiredScenarios(db *sqlx.DB) (int64, error) {
+	result, err := db.Exec(`DELETE FROM scenarios WHERE expires <= NOW()`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired scenarios: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// Results calculates the results of the scenario again, on the data stored now
+func (s *Scenario) Results(db *sqlx.DB) (helpers.CalculationResults, error) {
+	switch s.Kind {
+	case CompoundScenario:
+		var input CalcInput
+		if err := json.Unmarshal(s.Inputs, &input); err != nil {
+			return helpers.CalculationResults{}, fmt.Errorf("failed to decode scenario inputs: %w", err)
+		}
+		if input.Backtest {
+			results, _, err := backtest(db, input)
+			return results, err
+		}
+		return Calculate(db, input)
+	case PortfolioScenario:
+		var input PortfolioInput
+		if err := json.Unmarshal(s.Inputs, &input); err != nil {
+			return helpers.CalculationResults{}, fmt.Errorf("failed to decode scenario inputs: %w", err)
+		}
+		return CalculatePortfolio(db, input)
+	default:
+		return helpers.CalculationResults{}, fmt.Errorf("invalid scenario kind: %s", s.Kind)
+	}
+}
@@ -0,0 +1,300 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type ScrapeJobStatus string
+
+const (
+	ScrapeJobPending ScrapeJobStatus = "pending"
+	ScrapeJobRunning ScrapeJobStatus = "running"
+	ScrapeJobDone    ScrapeJobStatus = "done"
+	ScrapeJobDead    ScrapeJobStatus = "dead"
+	ScrapeJobSkipped ScrapeJobStatus = "skipped"
+)
+
+// Delay before a failed job is tried again, doubled after every failure up to the maximum
+const (
+	scrapeJobBaseBackoff = time.Minute
+	scrapeJobMaxBackoff  = time.Hour
+)
+
+// ScrapeJob is a seed to scrape in a SeedDatabase run, leased by one worker at a time
+type ScrapeJob struct {
+	ID          int             `db:"id" json:"id"`
+	Seed        string          `db:"seed" json:"seed"`
+	Suffix      string          `db:"suffix" json:"suffix"`
+	RunStarted  time.Time       `db:"run_started" json:"runStarted"`
+	Status      ScrapeJobStatus `db:"status" json:"status"`
+	Rank        int             `db:"rank" json:"rank"`
+	Attempts    int             `db:"attempts" json:"attempts"`
+	NextRunAt   time.Time       `db:"next_run_at" json:"nextRunAt"`
+	LeasedUntil NullableTime    `db:"leased_until" json:"leasedUntil"`
+	LastError   NullableString  `db:"last_error" json:"lastError"`
+}
+
+// ScrapeJobCounts counts the jobs of a run by status
+type ScrapeJobCounts struct {
+	Pending int `db:"pending" json:"pending"`
+	Running int `db:"running" json:"running"`
+	Done    int `db:"done" json:"done"`
+	Dead    int `db:"dead" json:"dead"`
+	Skipped int `db:"skipped" json:"skipped"`
+}
+
+func (c ScrapeJobCounts) Total() int {
+	return c.Pending + c.Running + c.Done + c.Dead + c.Skipped
+}
+
+// ScrapeJobBackoff is how long a job waits after its given failed attempt
+func ScrapeJobBackoff(attempts int) time.Duration {
+	backoff := float64(scrapeJobBaseBackoff) * math.Pow(2, float64(max(attempts-1, 0)))
+	return time.Duration(math.Min(backoff, float64(scrapeJobMaxBackoff)))
+}
+
+// Seeds scraped in a previous run are queued again, the dead ones stay in the dead letters of the run they failed in
+const enqueueScrapeJobsQuery = `
+	INSERT INTO scrape_jobs (seed, suffix, run_started, rank)
+	SELECT seed, $2, $3, rank
+	FROM UNNEST($1::text[]) WITH ORDINALITY AS planned(seed, rank)
+	ON CONFLICT (suffix, seed) DO UPDATE SET
+		run_started = EXCLUDED.run_started,
+		rank = EXCLUDED.rank,
+		status = 'pending',
+		attempts = 0,
+		next_run_at = NOW(),
+		leased_until = NULL,
+		last_error = NULL
+	WHERE scrape_jobs.status <> 'dead'
+`
+
+// EnqueueScrapeJobs queues the seeds of a new run for the suffix in the order they are to be scraped
+func EnqueueScrapeJobs(db *sqlx.DB, suffix string, seeds []string, runStarted time.Time) error {
+	_, err := db.Exec(enqueueScrapeJobsQuery, pq.Array(uniqueSeeds(seeds)), suffix, runStarted)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue scrape jobs: %w", err)
+	}
+
+	return nil
+}
+
+// uniqueSeeds keeps the first of the seeds planned more than once, a row cannot be upserted twice by one insert
+func uniqueSeeds(seeds []string) []string {
+	seen := make(map[string]bool, len(seeds))
+	unique := make([]string, 0, len(seeds))
+	for _, seed := range seeds {
+		if seen[seed] {
+			continue
+		}
+		seen[seed] = true
+		unique = append(unique, seed)
+	}
+
+	return unique
+}
+
+// GetUnfinishedScrapeRun returns the start of the run for the suffix that still has jobs to scrape, nil if there is none
+func GetUnfinishedScrapeRun(db *sqlx.DB, suffix string) (*time.Time, error) {
+	query := `
+		SELECT MAX(run_started)
+		FROM scrape_jobs
+		WHERE suffix = $1 AND status IN ('pending', 'running')
+	`
+
+	var started NullableTime
+	err := db.Get(&started, query, suffix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unfinished scrape run: %w", err)
+	}
+
+	if !started.Valid {
+		return nil, nil
+	}
+
+	return &started.Time, nil
+}
+
+// GetUnfinishedScrapeSuffixes returns the suffixes of the runs interrupted before all their jobs were scraped
+func GetUnfinishedScrapeSuffixes(db *sqlx.DB) ([]string, error) {
+	query := `
+		SELECT DISTINCT suffix
+		FROM scrape_jobs
+		WHERE status IN ('pending', 'running')
+	`
+
+	suffixes := []string{}
+	err := db.Select(&suffixes, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unfinished scrape suffixes: %w", err)
+	}
+
+	return suffixes, nil
+}
+
+// CountScrapeJobs counts the jobs of the latest run for the suffix by status
+func CountScrapeJobs(db *sqlx.DB, suffix string) (ScrapeJobCounts, error) {
+	query := `
+		SELECT
+			COUNT(*) FILTER (WHERE status = 'pending') AS pending,
+			COUNT(*) FILTER (WHERE status = 'running') AS running,
+			COUNT(*) FILTER (WHERE status = 'done') AS done,
+			COUNT(*) FILTER (WHERE status = 'dead') AS dead,
+			COUNT(*) FILTER (WHERE status = 'skipped') AS skipped
+		FROM scrape_jobs
+		WHERE suffix = $1 AND run_started = (SELECT MAX(run_started) FROM scrape_jobs WHERE suffix = $1)
+	`
+
+	var counts ScrapeJobCounts
+	err := db.Get(&counts, query, suffix)
+	if err != nil {
+		return ScrapeJobCounts{}, fmt.Errorf("failed to count scrape jobs: %w", err)
+	}
+
+	return counts, nil
+}
+
+// LeaseScrapeJob hands the next due job for the suffix to the calling worker for the lease duration,
+// nil when none is due. Jobs leased by other workers are skipped, those whose lease expired are taken over.
+func LeaseScrapeJob(db *sqlx.DB, suffix string, lease time.Duration) (*ScrapeJob, error) {
+	query := `
+		UPDATE scrape_jobs
+		SET status = 'running', attempts = attempts + 1, leased_until = NOW() + $2 * INTERVAL '1 second'
+		WHERE id = (
+			SELECT id
+			FROM scrape_jobs
+			WHERE suffix = $1 AND (
+				(status = 'pending' AND next_run_at <= NOW()) OR
+				(status = 'running' AND leased_until < NOW())
+			)
+			ORDER BY next_run_at, rank
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, seed, suffix, run_started, status, rank, attempts, next_run_at, leased_until, last_error
+	`
+
+	jobs := []*ScrapeJob{}
+	err := db.Select(&jobs, query, suffix, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to lease scrape job: %w", err)
+	}
+
+	if len(jobs) == 0 {
+		return nil, nil
+	}
+
+	return jobs[0], nil
+}
+
+// NextScrapeJobIn returns how long until a job for the suffix could next be leased, nil if none is left to scrape
+func NextScrapeJobIn(db *sqlx.DB, suffix string) (*time.Duration, error) {
+	query := `
+		SELECT EXTRACT(EPOCH FROM MIN(CASE WHEN status = 'pending' THEN next_run_at ELSE leased_until END) - NOW())
+		FROM scrape_jobs
+		WHERE suffix = $1 AND status IN ('pending', 'running')
+	`
+
+	var seconds *float64
+	err := db.Get(&seconds, query, suffix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get next scrape job: %w", err)
+	}
+
+	if seconds == nil {
+		return nil, nil
+	}
+
+	wait := time.Duration(max(*seconds, 0) * float64(time.Second))
+	return &wait, nil
+}
+
+// CompleteScrapeJob marks a leased job as scraped
+func CompleteScrapeJob(db *sqlx.DB, job *ScrapeJob) error {
+	query := `
+		UPDATE scrape_jobs
+		SET status = 'done', leased_until = NULL, last_error = NULL
+		WHERE id = $1
+	`
+
+	_, err := db.Exec(query, job.ID)
+	if err != nil {
+		return fmt.Errorf("failed to complete scrape job: %w", err)
+	}
+
+	return nil
+}
+
+// FailScrapeJob records the error of a leased job and schedules it again after a backoff,
+// or moves it to the dead letters once it failed maxAttempts times. It returns the new status of the job.
+func FailScrapeJob(db *sqlx.DB, job *ScrapeJob, cause error, maxAttempts int) (ScrapeJobStatus, error) {
+	status := ScrapeJobPending
+	if job.Attempts >= maxAttempts {
+		status = ScrapeJobDead
+	}
+
+	query := `
+		UPDATE scrape_jobs
+		SET status = $2, next_run_at = NOW() + $3 * INTERVAL '1 second', leased_until = NULL, last_error = $4
+		WHERE id = $1
+	`
+
+	_, err := db.Exec(query, job.ID, status, ScrapeJobBackoff(job.Attempts).Seconds(), cause.Error())
+	if err != nil {
+		return status, fmt.Errorf("failed to fail scrape job: %w", err)
+	}
+
+	return status, nil
+}
+
+// PostponeScrapeJob schedules a leased job again at the given time without counting the attempt,
+// for a job that could not be scraped because a host it needs is paused
+func PostponeScrapeJob(db *sqlx.DB, job *ScrapeJob, until time.Time, cause error) error {
+	query := `
+		UPDATE scrape_jobs
+		SET status = 'pending', attempts = GREATEST(attempts - 1, 0), next_run_at = NOW() + $2 * INTERVAL '1 second',
+			leased_until = NULL, last_error = $3
+		WHERE id = $1
+	`
+
+	_, err := db.Exec(query, job.ID, max(time.Until(until).Seconds(), 0), cause.Error())
+	if err != nil {
+		return fmt.Errorf("failed to postpone scrape job: %w", err)
+	}
+
+	return nil
+}
+
+// SkipScrapeJobs gives up on the jobs for the suffix not leased yet, once the run went past its budget
+func SkipScrapeJobs(db *sqlx.DB, suffix string) (int64, error) {
+	result, err := db.Exec(`UPDATE scrape_jobs SET status = 'skipped' WHERE suffix = $1 AND status = 'pending'`, suffix)
+	if err != nil {
+		return 0, fmt.Errorf("failed to skip scrape jobs: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// GetDeadScrapeJobs returns the jobs that failed too many times, latest runs first
+func GetDeadScrapeJobs(db *sqlx.DB, limit int) ([]*ScrapeJob, error) {
+	query := `
+		SELECT id, seed, suffix, run_started, status, rank, attempts, next_run_at, leased_until, last_error
+		FROM scrape_jobs
+		WHERE status = 'dead'
+		ORDER BY run_started DESC, seed
+		LIMIT $1
+	`
+
+	jobs := []*ScrapeJob{}
+	err := db.Select(&jobs, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dead scrape jobs: %w", err)
+	}
+
+	return jobs, nil
+}
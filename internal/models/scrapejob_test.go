@@ -0,0 +1,193 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+func TestUniqueSeeds(t *testing.T) {
+	tests := []struct {
+		name   string
+		seeds  []string
+		unique []string
+	}{
+		{name: "none", seeds: []string{}, unique: []string{}},
+		{name: "unique", seeds: []string{"AAPL", "MSFT"}, unique: []string{"AAPL", "MSFT"}},
+		{name: "duplicates keep the first rank", seeds: []string{"AAPL", "MSFT", "AAPL", "VFV", "MSFT"}, unique: []string{"AAPL", "MSFT", "VFV"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if unique := uniqueSeeds(test.seeds); !slices.Equal(unique, test.unique) {
+				t.Errorf("expected %v, got %v", test.unique, unique)
+			}
+		})
+	}
+}
+
+func TestEnqueueScrapeJobsKeepsDeadJobsDead(t *testing.T) {
+	update := enqueueScrapeJobsQuery[strings.Index(enqueueScrapeJobsQuery, "DO UPDATE"):]
+
+	if !strings.Contains(update, "status = 'pending'") {
+		t.Fatal("expected the jobs of a previous run to be queued again")
+	}
+	if !strings.Contains(update, "WHERE scrape_jobs.status <> 'dead'") {
+		t.Error("expected the dead jobs to be left out of the requeue")
+	}
+}
+
+// recorder is a database/sql driver that records the statements it is given, every query returning no rows
+type recorder struct {
+	queries []string
+	args    [][]driver.Value
+}
+
+func (r *recorder) Connect(ctx context.Context) (driver.Conn, error) { return r, nil }
+func (r *recorder) Driver() driver.Driver                            { return r }
+func (r *recorder) Open(name string) (driver.Conn, error)            { return r, nil }
+func (r *recorder) Prepare(query string) (driver.Stmt, error)        { return nil, driver.ErrSkip }
+func (r *recorder) Close() error                                     { return nil }
+func (r *recorder) Begin() (driver.Tx, error)                        { return nil, driver.ErrSkip }
+
+func (r *recorder) record(query string, args []driver.NamedValue) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	r.queries = append(r.queries, query)
+	r.args = append(r.args, values)
+}
+
+func (r *recorder) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	r.record(query, args)
+	return driver.RowsAffected(1), nil
+}
+
+func (r *recorder) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	r.record(query, args)
+	return emptyRows{}, nil
+}
+
+type emptyRows struct{}
+
+func (emptyRows) Columns() []string              { return []string{} }
+func (emptyRows) Close() error                   { return nil }
+func (emptyRows) Next(dest []driver.Value) error { return io.EOF }
+
+func recordingDB() (*sqlx.DB, *recorder) {
+	r := &recorder{}
+	return sqlx.NewDb(sql.OpenDB(r), "postgres"), r
+}
+
+func TestEnqueueScrapeJobsDedupesSeeds(t *testing.T) {
+	db, r := recordingDB()
+
+	err := EnqueueScrapeJobs(db, "TO", []string{"RY", "TD", "RY"}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seeds, err := pq.Array([]string{"RY", "TD"}).Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.args[0][0] != seeds {
+		t.Errorf("expected the seeds %v, got %v", seeds, r.args[0][0])
+	}
+}
+
+func TestFailScrapeJob(t *testing.T) {
+	tests := []struct {
+		attempts int
+		status   ScrapeJobStatus
+		backoff  float64
+	}{
+		{attempts: 1, status: ScrapeJobPending, backoff: 60},
+		{attempts: 3, status: ScrapeJobPending, backoff: 240},
+		{attempts: 4, status: ScrapeJobPending, backoff: 480},
+		{attempts: 5, status: ScrapeJobDead, backoff: 960},
+		{attempts: 9, status: ScrapeJobDead, backoff: 3600},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("attempt %d", test.attempts), func(t *testing.T) {
+			db, r := recordingDB()
+
+			job := &ScrapeJob{ID: 7, Attempts: test.attempts}
+			status, err := FailScrapeJob(db, job, errors.New("no price"), 5)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if status != test.status {
+				t.Errorf("expected status %s, got %s", test.status, status)
+			}
+			expected := []driver.Value{int64(7), string(test.status), test.backoff, "no price"}
+			if !reflect.DeepEqual(r.args[0], expected) {
+				t.Errorf("expected args %v, got %v", expected, r.args[0])
+			}
+		})
+	}
+}
+
+func TestPostponeScrapeJob(t *testing.T) {
+	tests := []struct {
+		name  string
+		until time.Duration
+		delay float64
+	}{
+		{name: "paused", until: 10 * time.Minute, delay: 600},
+		{name: "pause over", until: -time.Minute, delay: 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db, r := recordingDB()
+
+			err := PostponeScrapeJob(db, &ScrapeJob{ID: 7, Attempts: 2}, time.Now().Add(test.until), errors.New("host is cooling down"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// The attempt the pause interrupted is given back
+			if query := r.queries[0]; !strings.Contains(query, "status = 'pending'") || !strings.Contains(query, "attempts = GREATEST(attempts - 1, 0)") {
+				t.Errorf("expected the job to be pending without the attempt, got %s", query)
+			}
+			if delay := r.args[0][1].(float64); delay > test.delay || delay < test.delay-1 {
+				t.Errorf("expected a delay of %f seconds, got %f", test.delay, delay)
+			}
+		})
+	}
+}
+
+func TestLeaseScrapeJobWithNoneDue(t *testing.T) {
+	db, r := recordingDB()
+
+	job, err := LeaseScrapeJob(db, "TO", 5*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job != nil {
+		t.Errorf("expected no job, got %+v", job)
+	}
+
+	if query := r.queries[0]; !strings.Contains(query, "SET status = 'running', attempts = attempts + 1") || !strings.Contains(query, "FOR UPDATE SKIP LOCKED") {
+		t.Errorf("expected the lease to count the attempt of a job no other worker holds, got %s", query)
+	}
+	expected := []driver.Value{"TO", float64(300)}
+	if !reflect.DeepEqual(r.args[0], expected) {
+		t.Errorf("expected args %v, got %v", expected, r.args[0])
+	}
+}
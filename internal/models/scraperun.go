@@ -0,0 +1,204 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Francesco99975/finexo/internal/database"
+	"github.com/jmoiron/sqlx"
+)
+
+// SelectorStats counts how many pages a selector matched during a scrape run
+type SelectorStats struct {
+	Hits   int `json:"hits"`
+	Misses int `json:"misses"`
+}
+
+func (s SelectorStats) Lookups() int {
+	return s.Hits + s.Misses
+}
+
+// Ratio is the share of lookups the selector matched, between 0 and 1
+func (s SelectorStats) Ratio() float64 {
+	if s.Lookups() == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Lookups())
+}
+
+type SelectorStatsMap map[string]SelectorStats
+
+// Implement driver.Valuer for SelectorStatsMap
+func (m SelectorStatsMap) Value() (driver.Value, error) {
+	return json.Marshal(m)
+}
+
+// Implement sql.Scanner for SelectorStatsMap
+func (m *SelectorStatsMap) Scan(value any) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("unsupported selector stats type: %T", value)
+	}
+	return json.Unmarshal(bytes, m)
+}
+
+// DriftAlert is raised when a selector matches far fewer pages than it did in the previous run
+type DriftAlert struct {
+	Selector string  `json:"selector"`
+	Current  float64 `json:"current"`
+	Previous float64 `json:"previous"`
+	Message  string  `json:"message"`
+}
+
+type DriftAlerts []DriftAlert
+
+// Implement driver.Valuer for DriftAlerts
+func (a DriftAlerts) Value() (driver.Value, error) {
+	if a == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(a)
+}
+
+// Implement sql.Scanner for DriftAlerts
+func (a *DriftAlerts) Scan(value any) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("unsupported drift alerts type: %T", value)
+	}
+	return json.Unmarshal(bytes, a)
+}
+
+// ScrapeRun is the quality summary of a single SeedDatabase run
+type ScrapeRun struct {
+	ID        int              `db:"id" json:"id"`
+	Suffix    string           `db:"suffix" json:"suffix"`
+	Started   time.Time        `db:"started" json:"started"`
+	Finished  time.Time        `db:"finished" json:"finished"`
+	Seeds     int              `db:"seeds" json:"seeds"`
+	Succeeded int              `db:"succeeded" json:"succeeded"`
+	Failed    int              `db:"failed" json:"failed"`
+	Selectors SelectorStatsMap `db:"selectors" json:"selectors"`
+	Alerts    DriftAlerts      `db:"alerts" json:"alerts"`
+	// Pages counts the pages the run loaded, against its page budget
+	Pages int `db:"-" json:"pages,omitempty"`
+
+	lock sync.Mutex
+}
+
+func NewScrapeRun(suffix string, seeds int) *ScrapeRun {
+	return &ScrapeRun{
+		Suffix:    suffix,
+		Started:   time.Now(),
+		Seeds:     seeds,
+		Selectors: make(SelectorStatsMap),
+	}
+}
+
+// RecordSelector counts a lookup of the selector, safe to call from concurrent scrapers
+func (r *ScrapeRun) RecordSelector(selector string, hit bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	stats := r.Selectors[selector]
+	if hit {
+		stats.Hits++
+	} else {
+		stats.Misses++
+	}
+	r.Selectors[selector] = stats
+}
+
+// RecordPage counts a page loaded by the run, returning how many were loaded so far
+func (r *ScrapeRun) RecordPage() int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.Pages++
+	return r.Pages
+}
+
+// PageCount returns how many pages the run loaded so far
+func (r *ScrapeRun) PageCount() int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.Pages
+}
+
+// Snapshot returns a copy of the selector counters collected so far
+func (r *ScrapeRun) Snapshot() SelectorStatsMap {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	snapshot := make(SelectorStatsMap, len(r.Selectors))
+	for selector, stats := range r.Selectors {
+		snapshot[selector] = stats
+	}
+	return snapshot
+}
+
+func CreateScrapeRun(db *sqlx.DB, run *ScrapeRun) (err error) {
+	tx, err := db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+
+	defer database.HandleTransaction(tx, &err)
+
+	query := `
+		INSERT INTO scrape_runs (suffix, started, finished, seeds, succeeded, failed, selectors, alerts)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id
+	`
+
+	err = tx.Get(&run.ID, query, run.Suffix, run.Started, run.Finished, run.Seeds, run.Succeeded, run.Failed, run.Snapshot(), run.Alerts)
+	if err != nil {
+		return fmt.Errorf("failed to insert scrape run: %w", err)
+	}
+
+	return nil
+}
+
+// GetLatestScrapeRun returns the last persisted run for the given seed suffix, nil if there is none
+func GetLatestScrapeRun(db *sqlx.DB, suffix string) (*ScrapeRun, error) {
+	query := `
+		SELECT id, suffix, started, finished, seeds, succeeded, failed, selectors, alerts
+		FROM scrape_runs
+		WHERE suffix = $1
+		ORDER BY started DESC
+		LIMIT 1
+	`
+
+	runs := []*ScrapeRun{}
+	err := db.Select(&runs, query, suffix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest scrape run: %w", err)
+	}
+
+	if len(runs) == 0 {
+		return nil, nil
+	}
+
+	return runs[0], nil
+}
+
+func GetScrapeRuns(db *sqlx.DB, limit int) ([]*ScrapeRun, error) {
+	query := `
+		SELECT id, suffix, started, finished, seeds, succeeded, failed, selectors, alerts
+		FROM scrape_runs
+		ORDER BY started DESC
+		LIMIT $1
+	`
+
+	runs := []*ScrapeRun{}
+	err := db.Select(&runs, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scrape runs: %w", err)
+	}
+
+	return runs, nil
+}
@@ -47,7 +47,8 @@ type Security struct {
 	Created     time.Time      `db:"created" json:"created"`
 	Updated     time.Time      `db:"updated" json:"updated"`
 
-	Dividend *Dividend `db:"-" json:"dividend,omitempty"` // Associated dividend data (if exists)
+	MarketDate NullableTime `db:"-" json:"-"`                  // Day of the session the scraped quote comes from, the date of its price bar
+	Dividend   *Dividend    `db:"-" json:"dividend,omitempty"` // Associated dividend data (if exists)
 }
 
 // Scan implements the sql.Scanner interface for Security.
@@ -372,7 +373,7 @@ func GetSecurityView(db *sqlx.DB, tp, input string) (*SelectedSecurityView, erro
 
 			COALESCE(d.yield, 0),
     		d.ap , d.pr,
-     		COALESCE(d.frequency, 'unknown'),
+		COALESCE(d.frequency, 'unknown'),
 
 			COALESCE(e.family, ''), e.er
 
@@ -407,6 +408,44 @@ func GetSecurityView(db *sqlx.DB, tp, input string) (*SelectedSecurityView, erro
 	return &selectedSecurity, nil
 }
 
+// The volatility is annualized from the daily log returns. Bars are missing on the days the scraper did not run,
+// so each return is scaled down by the square root of the weekdays it spans to stay a one day return.
+// Casts are written with CAST, NamedQuery would read the :: shorthand as a named parameter.
+const securityVarsQuery = `
+	SELECT
+		s.price, s.currency,
+
+		COALESCE(d.yield, 0),
+     		COALESCE(d.frequency, 'unknown'),
+		e.er, d.pd,
+
+		s.yrl, s.yrh, s.beta,
+		(
+			SELECT CASE WHEN COUNT(r) >= 30 THEN STDDEV_SAMP(r) * SQRT(252) END
+			FROM (
+				SELECT LN(CAST(q.close AS float) / NULLIF(q.previous, 0)) / SQRT(NULLIF((
+					SELECT COUNT(*)
+					FROM generate_series(q.previous_date + 1, q.date, INTERVAL '1 day') AS day
+					WHERE EXTRACT(ISODOW FROM day) < 6
+				), 0)) AS r
+				FROM (
+					SELECT p.date, p.close,
+						LAG(p.close) OVER (ORDER BY p.date) AS previous,
+						LAG(p.date) OVER (ORDER BY p.date) AS previous_date
+					FROM prices p
+					WHERE p.ticker = s.ticker AND p.exchange = s.exchange AND p.date > CURRENT_DATE - INTERVAL '1 year'
+				) q
+			) returns
+		),
+		x.cc
+
+	FROM securities s
+	INNER JOIN exchanges x ON x.title = s.exchange
+	LEFT JOIN dividends d ON s.ticker = d.ticker AND s.exchange = d.exchange
+	LEFT JOIN etfs e ON s.ticker = e.ticker AND s.exchange = e.exchange
+	WHERE s.ticker = :ticker AND s.exchange = :exchange
+`
+
 func GetSecurityVars(db *sqlx.DB, input string) (*SecurityVars, error) {
 	// Parse the input into ticker and exchange
 	parts := strings.Split(input, ":")
@@ -415,22 +454,8 @@ func GetSecurityVars(db *sqlx.DB, input string) (*SecurityVars, error) {
 	}
 	ticker, exchange := parts[0], parts[1]
 
-	query := `
-		SELECT
-			s.price, s.currency,
-
-			COALESCE(d.yield, 0),
-     		COALESCE(d.frequency, 'unknown'),
-			e.er, d.pd
-
-		FROM securities s
-		LEFT JOIN dividends d ON s.ticker = d.ticker AND s.exchange = d.exchange
-		LEFT JOIN etfs e ON s.ticker = e.ticker AND s.exchange = e.exchange
-		WHERE s.ticker = :ticker AND s.exchange = :exchange
-	`
-
 	// Execute the query using NamedQuery
-	rows, err := db.NamedQuery(query, map[string]any{
+	rows, err := db.NamedQuery(securityVarsQuery, map[string]any{
 		"ticker":   ticker,
 		"exchange": exchange,
 	})
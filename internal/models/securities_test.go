@@ -0,0 +1,25 @@
+package models
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func TestSecurityVarsQueryCompiles(t *testing.T) {
+	compiled, args, err := sqlx.Named(securityVarsQuery, map[string]any{"ticker": "AAPL", "exchange": "NASDAQ"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every colon left after compiling is a cast the named parameters ate
+	if strings.Contains(compiled, ":") {
+		t.Errorf("expected no colon in the compiled query, got:\n%s", compiled)
+	}
+
+	if !reflect.DeepEqual(args, []any{"AAPL", "NASDAQ"}) {
+		t.Errorf("expected the ticker and exchange as arguments, got %v", args)
+	}
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/Francesco99975/finexo/internal/database"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Days of hits the popularity of a security is measured on
+const popularityWindowDays = 30
+
+type securityKey struct {
+	Ticker   string
+	Exchange string
+}
+
+// Hits counted since the last flush, written in bulk so that serving a security does not write to the database
+var (
+	hitsMutex sync.Mutex
+	hits      = make(map[securityKey]int)
+)
+
+// RecordSecurityHit counts a view of the security with the given ticker:exchange ID
+func RecordSecurityHit(id string) {
+	ticker, exchange, found := strings.Cut(id, ":")
+	if !found || ticker == "" || exchange == "" {
+		return
+	}
+
+	hitsMutex.Lock()
+	defer hitsMutex.Unlock()
+
+	hits[securityKey{Ticker: ticker, Exchange: exchange}]++
+}
+
+// FlushSecurityHits adds the hits counted since the last flush to the daily totals, dropping the days
+// past the popularity window
+func FlushSecurityHits(db *sqlx.DB) (err error) {
+	hitsMutex.Lock()
+	pending := hits
+	hits = make(map[securityKey]int)
+	hitsMutex.Unlock()
+
+	tickers := make([]string, 0, len(pending))
+	exchanges := make([]string, 0, len(pending))
+	counts := make([]int64, 0, len(pending))
+	for key, count := range pending {
+		tickers = append(tickers, key.Ticker)
+		exchanges = append(exchanges, key.Exchange)
+		counts = append(counts, int64(count))
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+
+	defer database.HandleTransaction(tx, &err)
+
+	query := `
+		INSERT INTO security_hits (ticker, exchange, hits)
+		SELECT * FROM UNNEST($1::text[], $2::text[], $3::int[])
+		ON CONFLICT (ticker, exchange, day) DO UPDATE SET hits = security_hits.hits + EXCLUDED.hits
+	`
+
+	_, err = tx.Exec(query, pq.Array(tickers), pq.Array(exchanges), pq.Array(counts))
+	if err != nil {
+		return fmt.Errorf("failed to insert security hits: %w", err)
+	}
+
+	_, err = tx.Exec(`DELETE FROM security_hits WHERE day <= CURRENT_DATE - $1::int`, popularityWindowDays)
+	if err != nil {
+		return fmt.Errorf("failed to delete old security hits: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,352 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type shapeField struct {
+	Name   string // json path as requested, served under security for the security fields of ETFs and REITs
+	Column string
+}
+
+// Fields that can be requested with fields=, in the order they are served
+var (
+	securityShapeFields = []shapeField{
+		{"ticker", "s.ticker"}, {"exchange", "s.exchange"}, {"typology", "s.typology"}, {"currency", "s.currency"},
+		{"fullName", "s.fullname"}, {"sector", "s.sector"}, {"industry", "s.industry"}, {"subIndustry", "s.subindustry"},
+		{"price", "s.price"}, {"pc", "s.pc"}, {"pcp", "s.pcp"}, {"yearLow", "s.yrl"}, {"yearHigh", "s.yrh"},
+		{"dayLow", "s.drl"}, {"dayHigh", "s.drh"}, {"consensus", "s.consensus"}, {"score", "s.score"},
+		{"coverage", "s.coverage"}, {"marketCap", "s.cap"}, {"volume", "s.volume"}, {"avgVolume", "s.avgvolume"},
+		{"outstanding", "s.outstanding"}, {"beta", "s.beta"}, {"previousClose", "s.pclose"}, {"currentOpen", "s.copen"},
+		{"bid", "s.bid"}, {"bidSize", "s.bidsz"}, {"ask", "s.ask"}, {"askSize", "s.asksz"}, {"eps", "s.eps"},
+		{"pe", "s.pe"}, {"target", "s.target"}, {"stm", "s.stm"}, {"created", "s.created"}, {"updated", "s.updated"},
+	}
+
+	dividendShapeFields = []shapeField{
+		{"dividend.yield", "d.yield"}, {"dividend.annualPayout", "d.ap"}, {"dividend.timing", "d.tm"},
+		{"dividend.payoutRatio", "d.pr"}, {"dividend.growthRate", "d.lgr"}, {"dividend.yearsGrowth", "d.yog"},
+		{"dividend.lastAnnounced", "d.lad"}, {"dividend.frequency", "d.frequency"},
+		{"dividend.exDivDate", "d.edd"}, {"dividend.payoutDate", "d.pd"},
+	}
+
+	etfShapeFields = []shapeField{
+		{"holdings", "e.holdings"}, {"family", "e.family"}, {"aum", "NULLIF(e.aum, '')::bigint"},
+		{"expenseRatio", "NULLIF(e.er, '')::int"}, {"nav", "e.nav"}, {"inception", "e.inception"},
+	}
+
+	reitShapeFields = []shapeField{
+		{"occupation", "r.occupation"}, {"focus", "r.focus"}, {"ffo", "r.ffo"}, {"pffo", "r.pffo"}, {"timing", "r.tm"},
+	}
+
+	relatedShapeField = shapeField{"relatedSecurities", `(
+		SELECT STRING_AGG(er.related_ticker || ':' || er.related_exchange || ':' || er.allocation, ',')
+		FROM etf_related_securities er
+		WHERE er.etf_ticker = s.ticker AND er.etf_exchange = s.exchange
+	)`}
+)
+
+// shapeFrom is the FROM clause of each typology, aliased like the typed list queries
+var shapeFrom = map[string]string{
+	"STOCK": " FROM securities s",
+	"ETF":   " FROM securities s INNER JOIN etfs e ON s.ticker = e.ticker AND s.exchange = e.exchange",
+	"REIT":  " FROM securities s INNER JOIN reits r ON s.ticker = r.ticker AND s.exchange = r.exchange",
+}
+
+// Shape is a sparse fieldset of the securities API, selected in SQL and served like the full objects:
+// the dividend fields nested under dividend and, for ETFs and REITs, the security fields under security
+type Shape struct {
+	fields []shapeField
+	nested bool // The security fields are served under security
+}
+
+// path is the json path a field is served at
+func (s *Shape) path(field shapeField) string {
+	named := func(f shapeField) bool { return f.Name == field.Name }
+	if s.nested && (slices.ContainsFunc(securityShapeFields, named) || slices.ContainsFunc(dividendShapeFields, named)) {
+		return "security." + field.Name
+	}
+	return field.Name
+}
+
+// Names are the json paths served, in order
+func (s *Shape) Names() []string {
+	names := make([]string, len(s.fields))
+	for i, field := range s.fields {
+		names[i] = s.path(field)
+	}
+	return names
+}
+
+func shapeFields(typology string) []shapeField {
+	fields := slices.Clone(securityShapeFields)
+	switch typology {
+	case "ETF":
+		fields = append(fields, etfShapeFields...)
+	case "REIT":
+		fields = append(fields, reitShapeFields...)
+	}
+	return fields
+}
+
+// ParseShape validates the fields and include params of a request, nil when neither is set.
+// Without fields every plain field is served, include adds the nested dividend and, for ETFs, the related securities.
+func ParseShape(typology string, fields string, include string) (*Shape, error) {
+	fields = strings.TrimSpace(fields)
+	include = strings.TrimSpace(include)
+	if fields == "" && include == "" {
+		return nil, nil
+	}
+
+	available := shapeFields(typology)
+	requestable := append(slices.Clone(available), dividendShapeFields...)
+	shape := &Shape{nested: typology != "STOCK"}
+
+	add := func(field shapeField) {
+		if !slices.ContainsFunc(shape.fields, func(f shapeField) bool { return f.Name == field.Name }) {
+			shape.fields = append(shape.fields, field)
+		}
+	}
+
+	if fields == "" {
+		for _, field := range available {
+			add(field)
+		}
+	}
+
+	for _, name := range strings.Split(fields, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		if name == "dividend" {
+			for _, field := range dividendShapeFields {
+				add(field)
+			}
+			continue
+		}
+
+		index := slices.IndexFunc(requestable, func(f shapeField) bool { return f.Name == name })
+		if index == -1 {
+			return nil, fmt.Errorf("invalid field for %s: %s", strings.ToLower(typology), name)
+		}
+		add(requestable[index])
+	}
+
+	for _, name := range strings.Split(include, ",") {
+		name = strings.TrimSpace(name)
+		switch name {
+		case "":
+		case "dividend":
+			for _, field := range dividendShapeFields {
+				add(field)
+			}
+		case "related":
+			if typology != "ETF" {
+				return nil, fmt.Errorf("include=related only applies to ETFs")
+			}
+			add(relatedShapeField)
+		default:
+			return nil, fmt.Errorf("invalid include value: %s, must be related or dividend", name)
+		}
+	}
+
+	if len(shape.fields) == 0 {
+		return nil, fmt.Errorf("no fields requested")
+	}
+
+	return shape, nil
+}
+
+// selectList is the SELECT list of the shape, followed by the hidden columns the cursor is built from
+func (s *Shape) selectList(params *SecParams) string {
+	columns := []string{}
+	for _, field := range s.fields {
+		columns = append(columns, fmt.Sprintf(`%s AS "%s"`, field.Column, s.path(field)))
+	}
+
+	columns = append(columns, `s.ticker AS "_ticker"`, `s.exchange AS "_exchange"`)
+
+	if params != nil {
+		keys, _ := SortKeys(params)
+		for i, key := range keys {
+			columns = append(columns, fmt.Sprintf(`%s AS "_sort%d"`, OrderColumns[key], i))
+		}
+	}
+
+	return "SELECT " + strings.Join(columns, ", ")
+}
+
+// shapeRow turns a scanned row into the served object, NULLs are left out like the omitempty fields
+func shapeRow(row map[string]any) map[string]any {
+	shaped := make(map[string]any)
+
+	// An ETF without related securities aggregates to NULL, it is still served as an empty list
+	if related, ok := row[relatedShapeField.Name]; ok && related == nil {
+		shaped[relatedShapeField.Name] = []string{}
+	}
+
+	for name, value := range row {
+		if value == nil || strings.HasPrefix(name, "_") {
+			continue
+		}
+
+		if bytes, ok := value.([]byte); ok {
+			value = string(bytes)
+		}
+
+		if name == relatedShapeField.Name {
+			value = strings.Split(value.(string), ",")
+		}
+
+		// Nested paths such as security.dividend.yield build an object per level
+		object := shaped
+		parents := strings.Split(name, ".")
+		name, parents = parents[len(parents)-1], parents[:len(parents)-1]
+		for _, parent := range parents {
+			child, ok := object[parent].(map[string]any)
+			if !ok {
+				child = make(map[string]any)
+				object[parent] = child
+			}
+			object = child
+		}
+
+		object[name] = value
+	}
+
+	return shaped
+}
+
+// shapeCursor builds the next cursor from the hidden columns of the last row of a page
+func shapeCursor(params *SecParams, row map[string]any) (string, error) {
+	keys, _ := SortKeys(params)
+
+	text := func(value any) string {
+		if bytes, ok := value.([]byte); ok {
+			return string(bytes)
+		}
+		return fmt.Sprint(value)
+	}
+
+	cursor := &Cursor{
+		Order:    keys,
+		Asc:      params.Asc,
+		Values:   make([]any, len(keys)),
+		Ticker:   text(row["_ticker"]),
+		Exchange: text(row["_exchange"]),
+	}
+
+	for i := range keys {
+		switch value := row[fmt.Sprintf("_sort%d", i)].(type) {
+		case []byte:
+			cursor.Values[i] = string(value)
+		case time.Time:
+			cursor.Values[i] = value.Format(time.RFC3339Nano)
+		default:
+			cursor.Values[i] = value
+		}
+	}
+
+	return EncodeCursor(cursor)
+}
+
+func scanShapedRows(rows *sqlx.Rows) ([]map[string]any, error) {
+	defer rows.Close()
+
+	scanned := []map[string]any{}
+	for rows.Next() {
+		row := make(map[string]any)
+		if err := rows.MapScan(row); err != nil {
+			return nil, fmt.Errorf("failed to scan shaped row: %w", err)
+		}
+		scanned = append(scanned, row)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over shaped rows: %w", err)
+	}
+
+	return scanned, nil
+}
+
+// GetShapedSecurities lists the securities of a typology like GetStocks, GetETFs and GetREITs, serving only the shape
+func GetShapedSecurities(db *sqlx.DB, typology string, params *SecParams, shape *Shape) (*Page[map[string]any], error) {
+	join := " LEFT JOIN dividends d ON s.ticker = d.ticker AND s.exchange = d.exchange"
+	if params.Dividend {
+		join = " INNER JOIN dividends d ON s.ticker = d.ticker AND s.exchange = d.exchange"
+	}
+
+	filters := NewSecurityQuery(params, typology)
+
+	// Count every match before the cursor narrows the query down to the page
+	var total int
+	err := db.Get(&total, "SELECT COUNT(*)"+shapeFrom[typology]+join+filters.Clause(), filters.Args()...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count securities: %w", err)
+	}
+
+	if params.Cursor != nil {
+		filters.After(params, params.Cursor)
+	}
+
+	query := shape.selectList(params) + shapeFrom[typology] + join + filters.Clause() + OrderClause(params) + LimitClause(params)
+
+	rows, err := db.Queryx(query, filters.Args()...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve shaped securities: %w", err)
+	}
+
+	scanned, err := scanShapedRows(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := NewPage(params, scanned, total, func(row *map[string]any) (string, error) {
+		return shapeCursor(params, *row)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i, row := range page.Items {
+		page.Items[i] = shapeRow(row)
+	}
+
+	return page, nil
+}
+
+// GetShapedSecurity returns a single security of a typology serving only the shape, nil if there is none
+func GetShapedSecurity(db *sqlx.DB, typology string, input string, shape *Shape) (map[string]any, error) {
+	// Parse the input into ticker and exchange
+	parts := strings.Split(input, ":")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid input format, expected: ticker:exchange")
+	}
+	ticker, exchange := parts[0], parts[1]
+
+	query := shape.selectList(nil) + shapeFrom[typology] +
+		" LEFT JOIN dividends d ON s.ticker = d.ticker AND s.exchange = d.exchange" +
+		" WHERE s.typology = $1 AND s.ticker = $2 AND s.exchange = $3"
+
+	rows, err := db.Queryx(query, typology, ticker, exchange)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve shaped security: %w", err)
+	}
+
+	scanned, err := scanShapedRows(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(scanned) == 0 {
+		return nil, nil
+	}
+
+	return shapeRow(scanned[0]), nil
+}
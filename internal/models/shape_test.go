@@ -0,0 +1,72 @@
+package models
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestShapeNames(t *testing.T) {
+	tests := []struct {
+		typology string
+		fields   string
+		include  string
+		names    []string
+	}{
+		{
+			typology: "STOCK",
+			fields:   "ticker,price,dividend.yield",
+			names:    []string{"ticker", "price", "dividend.yield"},
+		},
+		{
+			typology: "ETF",
+			fields:   "ticker,nav,dividend.yield",
+			include:  "related",
+			names:    []string{"security.ticker", "nav", "security.dividend.yield", "relatedSecurities"},
+		},
+		{
+			typology: "REIT",
+			fields:   "price,ffo,timing",
+			names:    []string{"security.price", "ffo", "timing"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.typology, func(t *testing.T) {
+			shape, err := ParseShape(test.typology, test.fields, test.include)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if names := shape.Names(); !slices.Equal(names, test.names) {
+				t.Errorf("expected %v, got %v", test.names, names)
+			}
+		})
+	}
+}
+
+func TestShapeRowNestsLikeTheFullObjects(t *testing.T) {
+	row := map[string]any{
+		"security.ticker":         []byte("VFV"),
+		"security.price":          int64(14123),
+		"security.dividend.yield": int64(108),
+		"security.sector":         nil,
+		"nav":                     int64(14131),
+		"relatedSecurities":       nil,
+		"_ticker":                 []byte("VFV"),
+	}
+
+	expected := map[string]any{
+		"security": map[string]any{
+			"ticker":   "VFV",
+			"price":    int64(14123),
+			"dividend": map[string]any{"yield": int64(108)},
+		},
+		"nav":               int64(14131),
+		"relatedSecurities": []string{},
+	}
+
+	if shaped := shapeRow(row); !reflect.DeepEqual(shaped, expected) {
+		t.Errorf("expected %v, got %v", expected, shaped)
+	}
+}
@@ -6,7 +6,6 @@ import (
 
 	"github.com/Francesco99975/finexo/internal/database"
 	"github.com/jmoiron/sqlx"
-	"github.com/lib/pq"
 )
 
 func CreateStock(db *sqlx.DB, stock *Security) (err error) {
@@ -21,6 +20,10 @@ func CreateStock(db *sqlx.DB, stock *Security) (err error) {
 		return err
 	}
 
+	if err := InsertPrice(tx, stock); err != nil {
+		return err
+	}
+
 	if err := InsertDividend(tx, stock.Dividend); err != nil {
 		return err
 	}
@@ -53,6 +56,11 @@ func UpdateStock(db *sqlx.DB, stock *Security) (err error) {
 		}
 	}
 
+	// Record today's price bar
+	if err := InsertPrice(tx, stock); err != nil {
+		return err
+	}
+
 	// Handle Dividend update/insert
 	if stock.Dividend != nil {
 		var divExists bool
@@ -126,14 +134,7 @@ func GetStock(db *sqlx.DB, input string) (*Security, error) {
 	return &stock, nil
 }
 
-func GetStocks(
-	db *sqlx.DB,
-	exchange, country []string,
-	minPrice, maxPrice int,
-	orderBy []string, orderDirection string,
-	limit int,
-	dividend bool,
-) ([]Security, error) {
+func GetStocks(db *sqlx.DB, params *SecParams) (*Page[Security], error) {
 	// Base query selecting relevant security fields where typology = 'STOCK'
 	query := `
 		SELECT
@@ -149,92 +150,33 @@ func GetStocks(
     	d.edd AS dividend_exDivDate, d.pd AS dividend_payoutDate
 		FROM securities s
 	`
+	from := " FROM securities s"
 
 	// Adjust JOIN type based on dividend presence
-	if dividend {
-		query += " INNER JOIN dividends d ON s.ticker = d.ticker AND s.exchange = d.exchange"
-	} else {
-		query += " LEFT JOIN dividends d ON s.ticker = d.ticker AND s.exchange = d.exchange"
-	}
-
-	// WHERE conditions (Switch from named parameters to positional `$1, $2, etc.`)
-	query += `
-	WHERE s.typology = 'STOCK'
-	AND (array_length(COALESCE($1, ARRAY[]::text[]), 1) = 0 OR s.exchange = ANY($1::text[]))
-	AND (array_length(COALESCE($2, ARRAY[]::text[]), 1) = 0 OR s.exchange IN (SELECT title FROM exchanges WHERE cc = ANY($2::text[])))
-	AND CAST($3 AS NUMERIC) = -1 OR s.price >= CAST($3 AS NUMERIC)
-	AND CAST($4 AS NUMERIC) = -1 OR s.price <= CAST($4 AS NUMERIC)
-	`
-
-	// Apply ordering
-	orderColumn := map[string]string{
-		"price":       "s.price",
-		"consensus":   "s.consensus", // New field
-		"score":       "s.score",     // New field
-		"coverage":    "s.coverage",  // New field
-		"volume":      "s.volume",
-		"avgvolume":   "s.avgvolume",
-		"marketcap":   "s.cap",
-		"outstanding": "s.outstanding", // New field
-		"beta":        "s.beta",        // New field
-		"eps":         "s.eps",         // New field
-		"pe":          "s.pe",          // New field
-		"yield":       "d.yield",       // New field
-		"payout":      "d.pr",          // New field
-		"pc":          "s.pc",
-		"pcp":         "s.pcp",
-		"updated":     "s.updated",
+	join := " LEFT JOIN dividends d ON s.ticker = d.ticker AND s.exchange = d.exchange"
+	if params.Dividend {
+		join = " INNER JOIN dividends d ON s.ticker = d.ticker AND s.exchange = d.exchange"
 	}
+	query += join
 
-	order := "s.price ASC" // Default ordering
-	if len(orderBy) > 0 {
-		for _, col := range orderBy {
-			if colx, exists := orderColumn[col]; exists {
-				if orderDirection == "desc" {
-					order = fmt.Sprintf("%s DESC", colx)
-				} else {
-					order = fmt.Sprintf("%s ASC", colx)
-				}
-			}
-		}
-	}
-	query += fmt.Sprintf(" ORDER BY %s", order)
+	filters := NewSecurityQuery(params, "STOCK")
 
-	// PostgreSQL does NOT support named parameters in LIMIT
-	if limit > 0 {
-		query += fmt.Sprintf(" LIMIT %d", limit)
+	// Count every match before the cursor narrows the query down to the page
+	var total int
+	err := db.Get(&total, "SELECT COUNT(*)"+from+join+filters.Clause(), filters.Args()...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count stocks: %w", err)
 	}
 
-	// Handle empty slices by converting them to PostgreSQL-friendly empty arrays
-	var exchangeArray any = "{}" // PostgreSQL empty array format
-	var countryArray any = "{}"
-
-	if len(exchange) > 0 {
-		exchangeArray = pq.Array(exchange) // Use pq.Array() only when non-empty
-	}
-	if len(country) > 0 {
-		countryArray = pq.Array(country)
+	if params.Cursor != nil {
+		filters.After(params, params.Cursor)
 	}
 
-	// Define "unset" sentinel values
-	const unsetPrice = -1
+	query += filters.Clause()
 
-	// If minPrice or maxPrice are 0, replace with -1
-	minP := minPrice
-	maxP := maxPrice
-	if minPrice == 0 {
-		minP = unsetPrice
-	}
-	if maxPrice == 0 {
-		maxP = unsetPrice
-	}
-
-	args := []any{
-		exchangeArray, // Wrap slices in pq.Array() for PostgreSQL compatibility
-		countryArray,
-		minP, // Now it defaults to -1 instead of 0
-		maxP,
-	}
+	query += OrderClause(params)
+	query += LimitClause(params)
+	args := filters.Args()
 
 	// Execute query using `Queryx`, NOT `NamedQuery`
 	rows, err := db.Queryx(query, args...)
@@ -257,5 +199,7 @@ func GetStocks(
 		return nil, fmt.Errorf("error iterating over stock rows: %w", err)
 	}
 
-	return stocks, nil
+	return NewPage(params, stocks, total, func(stock *Security) (string, error) {
+		return NextCursor(params, stock, nil)
+	})
 }
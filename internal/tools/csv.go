@@ -10,7 +10,7 @@ import (
 )
 
 // GenerateCSV generates a CSV file with a PDF-like layout from CalculationResults
-func GenerateCSV(results helpers.CalculationResults) (string, error) {
+func GenerateCSV(results helpers.CalculationResults, f helpers.Formatter) (string, error) {
 	// Generate filename with SID and timestamp
 	timestamp := time.Now().Unix()
 	filename := fmt.Sprintf("%s-%d.csv", results.SID, timestamp)
@@ -29,14 +29,42 @@ func GenerateCSV(results helpers.CalculationResults) (string, error) {
 	// Write Overall Results Section
 	overallData := [][]string{
 		{"SID", results.SID},
-		{"Principal", results.Principal},
-		{"Rate", results.Rate},
+		{"Principal", f.Amount(results.Principal)},
+		{"Rate", f.Percent(results.Rate)},
 		{"Rate Frequency", results.RateFreq},
 		{"Currency", results.Currency},
-		{"Profit", results.Profit},
-		{"Total Contributions", results.TotalContributions},
+		{"Profit", f.Amount(results.Profit)},
+		{"Total Contributions", f.Amount(results.TotalContributions)},
 		{"Contribution Frequency", results.ContribFreq},
-		{"Final Balance", results.FinalBalance},
+		{"Final Balance", f.Amount(results.FinalBalance)},
+	}
+	if results.RealValues {
+		overallData = append(overallData, []string{"Values", fmt.Sprintf("Real, in today's money at %s inflation", f.Percent(*results.Inflation))})
+	}
+	if results.AfterTaxBalance != nil {
+		overallData = append(overallData,
+			[]string{"Account Type", results.AccountType},
+			[]string{"Total Tax Drag", f.MaybeAmount(results.TotalTaxDrag)},
+			[]string{"After-Tax Balance", f.Amount(*results.AfterTaxBalance)},
+		)
+	}
+	if results.Backtest != nil {
+		overallData = append(overallData,
+			[]string{"Backtest Period", f.Period(*results.Backtest)},
+			[]string{"DRIP", fmt.Sprintf("%t", results.Backtest.DRIP)},
+			[]string{"CAGR", f.Percent(results.Backtest.CAGR)},
+			[]string{"Max Drawdown", f.Percent(-results.Backtest.MaxDrawdown)},
+			[]string{"Total Dividends", f.Amount(results.Backtest.TotalDividends)},
+			[]string{"Yield on Cost", f.Percent(results.Backtest.YieldOnCost)},
+			[]string{"Dividends in Cash", f.Amount(results.Backtest.Cash)},
+		)
+	}
+	if results.Goal != nil {
+		overallData = append(overallData,
+			[]string{"Goal", fmt.Sprintf("%s of %s", f.Goal(*results.Goal), f.Amount(results.Goal.Target))},
+			[]string{"Solved " + f.Solve(*results.Goal), f.Solution(*results.Goal)},
+			[]string{"Goal Reached", f.Amount(results.Goal.Reached)},
+		)
 	}
 	for _, row := range overallData {
 		if err := writer.Write(row); err != nil {
@@ -49,17 +77,87 @@ func GenerateCSV(results helpers.CalculationResults) (string, error) {
 		return "", fmt.Errorf("failed to write blank row: %v", err)
 	}
 
+	// Write Withdrawal Phase
+	if results.Withdrawal != nil {
+		withdrawalData := [][]string{
+			{"Withdrawal Strategy", f.WithdrawalStrategy(*results.Withdrawal)},
+			{"Inflation", f.Percent(results.Withdrawal.Inflation)},
+			{"Starting Balance", f.Amount(results.Withdrawal.StartBalance)},
+			{"First Year Income", f.Amount(results.Withdrawal.FirstYearIncome)},
+			{"Sustainable Monthly Income (Today's Money)", f.Amount(results.Withdrawal.SustainableIncome)},
+			{"Total Withdrawn", f.Amount(results.Withdrawal.TotalWithdrawn)},
+			{"Depletion Year", f.DepletionYear(*results.Withdrawal)},
+			{"Ending Balance", f.Amount(results.Withdrawal.EndBalance)},
+			{""},
+		}
+
+		for _, row := range withdrawalData {
+			if err := writer.Write(row); err != nil {
+				return "", fmt.Errorf("failed to write withdrawal data: %v", err)
+			}
+		}
+	}
+
+	// Write Portfolio Holdings
+	if len(results.Holdings) > 0 {
+		holdingsData := [][]string{
+			{"Rebalancing", results.Rebalance},
+			{"Holding", "Weight", "Income", "Income Share", "Final Balance"},
+		}
+		for _, holding := range results.Holdings {
+			holdingsData = append(holdingsData, []string{holding.SID, f.Percent(holding.Weight), f.Amount(holding.Income), f.Percent(holding.IncomeShare), f.Amount(holding.FinalBalance)})
+		}
+		holdingsData = append(holdingsData, []string{""})
+
+		for _, row := range holdingsData {
+			if err := writer.Write(row); err != nil {
+				return "", fmt.Errorf("failed to write holdings data: %v", err)
+			}
+		}
+	}
+
+	// Write Monte Carlo Spread
+	if results.Simulation != nil {
+		simulationData := [][]string{
+			{"Simulated Paths", fmt.Sprintf("%d", results.Simulation.Paths)},
+			{"Volatility", f.Percent(results.Simulation.Volatility)},
+		}
+		if results.Simulation.Target != nil {
+			simulationData = append(simulationData,
+				[]string{"Target", f.Amount(*results.Simulation.Target)},
+				[]string{"Target Probability", f.Percent(*results.Simulation.TargetProbability)},
+			)
+		}
+		simulationData = append(simulationData, []string{"Year", "10th Percentile", "Median", "90th Percentile"})
+		for _, year := range results.Simulation.YearResults {
+			simulationData = append(simulationData, []string{year.YearName, f.Amount(year.P10), f.Amount(year.P50), f.Amount(year.P90)})
+		}
+		simulationData = append(simulationData, []string{""})
+
+		for _, row := range simulationData {
+			if err := writer.Write(row); err != nil {
+				return "", fmt.Errorf("failed to write simulation data: %v", err)
+			}
+		}
+	}
+
 	// Write Yearly and Monthly Breakdowns
 	for i, year := range results.YearResults {
 		// Write Yearly Summary
 		yearData := [][]string{
 			{"Year", year.YearName},
-			{"Share Amount", year.ShareAmount},
-			{"Total Year Gains", year.TotalYearGains},
-			{"Cumulative Gain", year.CumGain},
-			{"YoY Growth", year.YoyGrowth},
-			{"Total Growth", year.TotalGrowth},
-			{"Balance", year.Balance},
+			{"Share Amount", f.Shares(year.ShareAmount)},
+			{"Total Year Gains", f.Amount(year.TotalYearGains)},
+			{"Cumulative Gain", f.Amount(year.CumGain)},
+			{"YoY Growth", f.Percent(year.YoyGrowth)},
+			{"Total Growth", f.Percent(year.TotalGrowth)},
+			{"Balance", f.Amount(year.Balance)},
+		}
+		if year.TaxDrag != nil {
+			yearData = append(yearData, []string{"Tax Drag", f.Amount(*year.TaxDrag)})
+		}
+		if year.Withdrawn != nil {
+			yearData = append(yearData, []string{"Withdrawn", f.Amount(*year.Withdrawn)})
 		}
 		for _, row := range yearData {
 			if err := writer.Write(row); err != nil {
@@ -77,6 +175,9 @@ func GenerateCSV(results helpers.CalculationResults) (string, error) {
 			"Month", "Shares", "Contributions", "Price Gain", "Dividends Gain",
 			"Monthly Gain", "Cumulative Gain", "Balance", "Return", "DRIP",
 		}
+		if year.Withdrawn != nil {
+			monthHeaders = append(monthHeaders, "Withdrawal")
+		}
 		if err := writer.Write(monthHeaders); err != nil {
 			return "", fmt.Errorf("failed to write month headers: %v", err)
 		}
@@ -85,15 +186,18 @@ func GenerateCSV(results helpers.CalculationResults) (string, error) {
 		for _, month := range year.MonthsResults {
 			monthRow := []string{
 				month.MonthName,
-				month.ShareAmount,
-				month.Contributions,
-				month.MonthlyGainedFromPriceInc,
-				month.MonthlyGainedFromDividends,
-				month.MonthlyGain,
-				month.CumGain,
-				month.Balance,
-				month.Return,
-				month.DRIP,
+				f.Shares(month.ShareAmount),
+				f.Amount(month.Contributions),
+				f.Amount(month.MonthlyGainedFromPriceInc),
+				f.MaybeAmount(month.MonthlyGainedFromDividends),
+				f.Amount(month.MonthlyGain),
+				f.Amount(month.CumGain),
+				f.Amount(month.Balance),
+				f.Percent(month.Return),
+				f.DRIP(month.DRIPShares),
+			}
+			if year.Withdrawn != nil {
+				monthRow = append(monthRow, f.MaybeAmount(month.Withdrawal))
 			}
 			if err := writer.Write(monthRow); err != nil {
 				return "", fmt.Errorf("failed to write month data: %v", err)
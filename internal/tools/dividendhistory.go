@@ -0,0 +1,202 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Francesco99975/finexo/internal/models"
+	"github.com/labstack/gommon/log"
+)
+
+// DividendHistorySource scrapes payout ratio, frequency and the upcoming payout from dividendhistory.org
+type DividendHistorySource struct{}
+
+func (s *DividendHistorySource) Name() string {
+	return DIVIDENDHISTORY_SOURCE
+}
+
+func (s *DividendHistorySource) URL(target *ScrapeTarget) string {
+	exchange := target.Exchange
+
+	var dividendHistoryScrapingUrl string
+	if exchange.CC != "US" {
+		if exchange.CC == "UK" {
+			dividendHistoryScrapingUrl = BASE_DIVIDENDHISTORY_URL + fmt.Sprintf("%s/%s", strings.ToLower(exchange.CC), target.Ticker)
+		} else {
+			if exchange.Suffix.String == "NE" {
+				dividendHistoryScrapingUrl = BASE_DIVIDENDHISTORY_URL + fmt.Sprintf("%s/%s", "tsx", target.Ticker)
+			} else {
+				dividendHistoryScrapingUrl = BASE_DIVIDENDHISTORY_URL + fmt.Sprintf("%s/%s", strings.ToLower(exchange.Title), target.Ticker)
+			}
+		}
+	} else {
+		dividendHistoryScrapingUrl = BASE_DIVIDENDHISTORY_URL + target.Ticker
+	}
+
+	// Adjusting For REITS
+	return strings.ReplaceAll(dividendHistoryScrapingUrl, "-UN", ".UN")
+}
+
+func (s *DividendHistorySource) Fetch(ctx context.Context, target *ScrapeTarget) (*models.PartialSecurity, error) {
+	partial := models.NewPartialSecurity(s.Name())
+	page := target.Page.Context(ctx)
+
+	if err := openSource(page, target, s.Name(), s.URL(target)); err != nil {
+		var pause *HostPauseError
+		if errors.As(err, &pause) {
+			return nil, fmt.Errorf("failed to open page on Dividend History: %w. For seed %s", err, target.Seed)
+		}
+		log.Warnf("failed to open page on Dividend History: %v. For seed %s", err, target.Seed)
+	}
+
+	log.Debugf("Scraping Dividend History for %s", target)
+
+	paragraphs, err := findElements(target, page, DH_PARAGRAPHS_SELECTOR)
+	if err != nil || len(paragraphs) == 0 {
+		log.Warnf("failed to scrape Dividend History: %v. For seed %s", err, target.Seed)
+	} else {
+		for _, paragraph := range paragraphs {
+			pt := paragraph.MustText()
+			log.Debugf("Scraped Dividend History data PT: %s", pt)
+			paragraphText := strings.ReplaceAll(strings.ToLower(pt), " ", "")
+			paragraphText = strings.ReplaceAll(paragraphText, "\n", "")
+
+			if strings.Contains(paragraphText, "payoutratio") && strings.Contains(paragraphText, ":") {
+				log.Debugf("Scraped Dividend History data: %s", paragraphText)
+				scrapedPr, err := parseCents(strings.Split(paragraphText, ":")[1])
+				if err != nil {
+					log.Warnf("failed to parse payout ratio: %v. For seed %s", err, target.Seed)
+				} else {
+					partial.PayoutRatio = models.ValidInt(int64(scrapedPr))
+				}
+			}
+
+			if strings.Contains(paragraphText, "frequency") && strings.Contains(paragraphText, ":") {
+				log.Debugf("Scraped Dividend History data: %s", paragraphText)
+				freq, err := models.ParseFrequency(strings.Split(paragraphText, ":")[1])
+				if err != nil {
+					log.Warnf("failed to parse frequency: %v. For seed %s", err, target.Seed)
+					partial.Frequency = models.ValidString(string(models.FrequencyUnknown))
+				} else {
+					partial.Frequency = models.ValidString(string(freq))
+				}
+			}
+		}
+
+		if !partial.Frequency.Valid {
+			partial.Frequency = models.ValidString(string(models.FrequencyUnknown))
+		}
+	}
+
+	tableIndex := -1
+	payoutDates, err := findElements(target, page, DH_PAYOUT_DATES_SELECTOR)
+	if err != nil {
+		log.Warnf("failed to scrape Dividend History: %v. For seed %s", err, target.Seed)
+	} else {
+		for index, payoutDate := range payoutDates {
+			date, err := time.Parse("2006-01-02", payoutDate.MustText())
+			if err != nil {
+				log.Warnf("failed to parse payout date: %v. For seed %s", err, target.Seed)
+				continue
+			}
+
+			if date.After(target.now()) {
+				tableIndex = index + 1
+			} else {
+				break
+			}
+		}
+	}
+
+	rows, err := findElements(target, page, DH_TABLE_ROWS_SELECTOR)
+	if err != nil {
+		log.Warnf("failed to scrape Dividend History: %v. For seed %s", err, target.Seed)
+		return partial, nil
+	}
+
+	if tableIndex != -1 && len(rows) > tableIndex && len(rows) >= 3 {
+		relevantRowStr := rows[tableIndex].MustText()
+		log.Debugf("Scraped Dividend History data relevantRowStr: %s", relevantRowStr)
+		relevantRowArr := strings.Split(relevantRowStr, "\t")
+		if len(relevantRowArr) < 3 {
+			log.Warnf("unexpected Dividend History row: %s. For seed %s", relevantRowStr, target.Seed)
+		} else {
+			scrapedExDividendDate, err := time.Parse("2006-01-02", relevantRowArr[0])
+			if err != nil {
+				log.Warnf("failed to parse ex-dividend date: %v. For seed %s", err, target.Seed)
+			} else {
+				partial.ExDivDate = models.ValidTime(scrapedExDividendDate)
+			}
+
+			scrapedPayoutDate, err := time.Parse("2006-01-02", relevantRowArr[1])
+			if err != nil {
+				log.Warnf("failed to parse payout date: %v. For seed %s", err, target.Seed)
+			} else {
+				partial.PayoutDate = models.ValidTime(scrapedPayoutDate)
+			}
+
+			scrapedLad, err := parseDividendAmount(relevantRowArr[2])
+			if err != nil {
+				log.Warnf("failed to parse lad: %v. For seed %s", err, target.Seed)
+			} else {
+				partial.LastAnnounced = models.ValidInt(int64(scrapedLad))
+			}
+		}
+	}
+
+	// Keep the whole table as payment history, the header and malformed rows are skipped
+	for _, row := range rows {
+		event, ok := parseDividendEvent(row.MustText())
+		if ok {
+			partial.DividendEvents = append(partial.DividendEvents, event)
+		}
+	}
+
+	return partial, nil
+}
+
+// parseDividendAmount reads a cash amount of the dividend table rounded to the cent, like the other prices.
+// Sub-cent payouts are common, 0.2685 is stored as 27 rather than cut to 26.
+func parseDividendAmount(amount string) (int, error) {
+	amount = strings.NewReplacer("$", "", ",", "", " ", "", "*", "").Replace(amount)
+	value, err := strconv.ParseFloat(amount, 64)
+	if err != nil {
+		return 0, err
+	}
+	return int(math.Round(value * 100)), nil
+}
+
+// parseDividendEvent reads a row of the dividend table: ex-dividend date, payout date, cash amount and notes
+func parseDividendEvent(row string) (models.DividendEvent, bool) {
+	columns := strings.Split(row, "\t")
+	if len(columns) < 3 {
+		return models.DividendEvent{}, false
+	}
+
+	exDivDate, err := time.Parse("2006-01-02", strings.TrimSpace(columns[0]))
+	if err != nil {
+		return models.DividendEvent{}, false
+	}
+
+	amount, err := parseDividendAmount(strings.TrimSpace(columns[2]))
+	if err != nil {
+		return models.DividendEvent{}, false
+	}
+
+	event := models.DividendEvent{
+		ExDivDate: exDivDate,
+		Amount:    amount,
+		Type:      string(models.ParseDistributionType(strings.Join(columns[3:], " "))),
+	}
+
+	if payoutDate, err := time.Parse("2006-01-02", strings.TrimSpace(columns[1])); err == nil {
+		event.PayoutDate = models.ValidTime(payoutDate)
+	}
+
+	return event, true
+}
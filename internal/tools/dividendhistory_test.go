@@ -0,0 +1,33 @@
+package tools
+
+import "testing"
+
+func TestParseDividendAmount(t *testing.T) {
+	tests := []struct {
+		amount string
+		cents  int
+	}{
+		{"$0.25", 25},
+		{"0.2685", 27},
+		{"$0.2625*", 26},
+		{"$0.004", 0},
+		{"$0.005", 1},
+		{"$1,234.567", 123457},
+		{"0.29", 29},
+	}
+
+	for _, test := range tests {
+		cents, err := parseDividendAmount(test.amount)
+		if err != nil {
+			t.Errorf("%s: %v", test.amount, err)
+			continue
+		}
+		if cents != test.cents {
+			t.Errorf("%s: expected %d cents, got %d", test.amount, test.cents, cents)
+		}
+	}
+
+	if _, err := parseDividendAmount("n/a"); err == nil {
+		t.Error("expected an error for a missing amount")
+	}
+}
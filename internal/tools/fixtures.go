@@ -0,0 +1,297 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/Francesco99975/finexo/internal/models"
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/labstack/gommon/log"
+)
+
+const (
+	FIXTURES_RECORD = "record"
+	FIXTURES_REPLAY = "replay"
+
+	FIXTURE_TARGET_FILE = "target.json"
+	FIXTURE_GOLDEN_FILE = "golden.json"
+)
+
+// FixtureTarget is what a recorded fixture needs to be replayed without a database
+type FixtureTarget struct {
+	Seed     string          `json:"seed"`
+	Ticker   string          `json:"ticker"`
+	Exchange models.Exchange `json:"exchange"`
+	Recorded time.Time       `json:"recorded"`
+}
+
+// FixtureStore saves the pages navigated by the sources (record) or serves them back from disk (replay).
+// Fixtures are laid out as <dir>/<TICKER>.<EXCHANGE>/<source>.html
+type FixtureStore struct {
+	Dir     string
+	Mode    string
+	baseUrl string
+	server  *http.Server
+}
+
+var fixtures *FixtureStore
+
+// EnableFixtureRecording saves every page the sources navigate to under dir, next to the target they scraped
+func EnableFixtureRecording(dir string) error {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return fmt.Errorf("failed to create fixtures directory (%s): %v", dir, err)
+	}
+
+	fixtures = &FixtureStore{Dir: dir, Mode: FIXTURES_RECORD}
+
+	return nil
+}
+
+// EnableFixtureReplay serves the fixtures in dir from a local server and points every source to it
+func EnableFixtureReplay(dir string) (*FixtureStore, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for fixtures server: %v", err)
+	}
+
+	store := &FixtureStore{
+		Dir:     dir,
+		Mode:    FIXTURES_REPLAY,
+		baseUrl: "http://" + listener.Addr().String(),
+		server:  &http.Server{Handler: http.FileServer(http.Dir(dir))},
+	}
+
+	go func() {
+		err := store.server.Serve(listener)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Errorf("fixtures server stopped: %v", err)
+		}
+	}()
+
+	fixtures = store
+
+	return store, nil
+}
+
+// DisableFixtures goes back to live scraping, stopping the replay server if any
+func DisableFixtures() error {
+	store := fixtures
+	fixtures = nil
+
+	if store != nil && store.server != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return store.server.Shutdown(ctx)
+	}
+
+	return nil
+}
+
+func FixtureName(ticker string, exchange string) string {
+	return ticker + "." + exchange
+}
+
+func (fs *FixtureStore) path(target *ScrapeTarget, file string) string {
+	return filepath.Join(fs.Dir, FixtureName(target.Ticker, target.Exchange.Title), file)
+}
+
+func (fs *FixtureStore) url(target *ScrapeTarget, source string) string {
+	return fs.baseUrl + "/" + FixtureName(target.Ticker, target.Exchange.Title) + "/" + source + ".html"
+}
+
+func (fs *FixtureStore) savePage(page *rod.Page, target *ScrapeTarget, source string) error {
+	html, err := page.HTML()
+	if err != nil {
+		return fmt.Errorf("failed to read page html: %v", err)
+	}
+
+	file := fs.path(target, source+".html")
+	err = os.MkdirAll(filepath.Dir(file), 0755)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(file, []byte(html), 0644)
+}
+
+func (fs *FixtureStore) saveTarget(target *ScrapeTarget) error {
+	payload, err := json.MarshalIndent(FixtureTarget{
+		Seed:     target.Seed,
+		Ticker:   target.Ticker,
+		Exchange: *target.Exchange,
+		Recorded: target.now(),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	file := fs.path(target, FIXTURE_TARGET_FILE)
+	err = os.MkdirAll(filepath.Dir(file), 0755)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(file, payload, 0644)
+}
+
+// openSource navigates to the page of a source, or to its fixture when replaying.
+// When recording the loaded page is saved as the fixture of that source.
+func openSource(page *rod.Page, target *ScrapeTarget, source string, url string) error {
+	store := fixtures
+	if store != nil && store.Mode == FIXTURES_REPLAY {
+		// Fixtures are served locally, there is no host to be polite to
+		err := loadPage(page, store.url(target, source))
+		if err != nil {
+			return err
+		}
+		target.recordPage()
+		return nil
+	}
+
+	err := navigate(page, url)
+	if err != nil {
+		return err
+	}
+	target.recordPage()
+
+	if store != nil && store.Mode == FIXTURES_RECORD {
+		err = store.savePage(page, target, source)
+		if err != nil {
+			log.Warnf("failed to record %s fixture for %s: %v", source, target, err)
+		}
+	}
+
+	return nil
+}
+
+// ListFixtures returns the names of the recorded fixtures in dir
+func ListFixtures(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixtures directory (%s): %v", dir, err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		if _, err := os.Stat(filepath.Join(dir, entry.Name(), FIXTURE_TARGET_FILE)); err == nil {
+			names = append(names, entry.Name())
+		}
+	}
+
+	return names, nil
+}
+
+// Replay runs the enabled sources against a recorded fixture, as of the time it was recorded
+func Replay(browser *rod.Browser, name string) (*MergedSecurity, error) {
+	store := fixtures
+	if store == nil || store.Mode != FIXTURES_REPLAY {
+		return nil, fmt.Errorf("fixture replay is not enabled")
+	}
+
+	payload, err := os.ReadFile(filepath.Join(store.Dir, name, FIXTURE_TARGET_FILE))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture target (%s): %v", name, err)
+	}
+
+	var fixture FixtureTarget
+	err = json.Unmarshal(payload, &fixture)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse fixture target (%s): %v", name, err)
+	}
+
+	page, err := browser.Page(proto.TargetCreateTarget{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create page for fixture (%s): %v", name, err)
+	}
+	defer page.Close()
+
+	return collect(context.Background(), &ScrapeTarget{
+		Seed:     fixture.Seed,
+		Ticker:   fixture.Ticker,
+		Exchange: &fixture.Exchange,
+		Page:     page,
+		Now:      fixture.Recorded,
+	})
+}
+
+// WriteGolden stores the merged result of a fixture as the expected outcome of its replay
+func WriteGolden(dir string, merged *MergedSecurity) error {
+	payload, err := json.MarshalIndent(merged, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	file := filepath.Join(dir, FixtureName(merged.Security.Ticker, merged.Security.Exchange), FIXTURE_GOLDEN_FILE)
+
+	return os.WriteFile(file, append(payload, '\n'), 0644)
+}
+
+// VerifyGolden compares the merged result of a replay with the golden file of its fixture.
+// It returns one line per field that differs, an empty slice means the parsing is unchanged.
+func VerifyGolden(dir string, name string, merged *MergedSecurity) ([]string, error) {
+	expectedPayload, err := os.ReadFile(filepath.Join(dir, name, FIXTURE_GOLDEN_FILE))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read golden file (%s): %v", name, err)
+	}
+
+	actualPayload, err := json.Marshal(merged)
+	if err != nil {
+		return nil, err
+	}
+
+	var expected, actual any
+	if err := json.Unmarshal(expectedPayload, &expected); err != nil {
+		return nil, fmt.Errorf("failed to parse golden file (%s): %v", name, err)
+	}
+	if err := json.Unmarshal(actualPayload, &actual); err != nil {
+		return nil, err
+	}
+
+	var diffs []string
+	diffJSON("", expected, actual, &diffs)
+
+	return diffs, nil
+}
+
+func diffJSON(path string, expected any, actual any, diffs *[]string) {
+	expectedObject, expectedIsObject := expected.(map[string]any)
+	actualObject, actualIsObject := actual.(map[string]any)
+
+	if expectedIsObject && actualIsObject {
+		var keys []string
+		for key := range expectedObject {
+			keys = append(keys, key)
+		}
+		for key := range actualObject {
+			if _, ok := expectedObject[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, key := range slices.Compact(keys) {
+			diffJSON(path+"."+key, expectedObject[key], actualObject[key], diffs)
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		*diffs = append(*diffs, fmt.Sprintf("%s: expected %v, got %v", path, expected, actual))
+	}
+}
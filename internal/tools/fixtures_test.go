@@ -0,0 +1,246 @@
+package tools
+
+import (
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/defaults"
+	"github.com/go-rod/rod/lib/launcher"
+)
+
+var update = flag.Bool("update", false, "overwrite the golden files of the fixtures with the replayed values")
+
+const fixturesDir = "../../fixtures"
+
+// replayBrowser starts a headless browser to replay the fixtures with, skipping the test when none is installed.
+// A browser outside of the usual paths is picked with -rod=bin=PATH.
+func replayBrowser(t *testing.T) *rod.Browser {
+	t.Helper()
+
+	bin := defaults.Bin
+	if bin == "" {
+		path, found := launcher.LookPath()
+		if !found {
+			t.Skip("no browser to replay the fixtures with")
+		}
+		bin = path
+	}
+
+	u, err := launcher.New().Bin(bin).NoSandbox(true).Headless(true).Set("disable-dev-shm-usage").Launch()
+	if err != nil {
+		t.Fatalf("failed to launch browser: %v", err)
+	}
+
+	browser := rod.New().ControlURL(u)
+	err = browser.Connect()
+	if err != nil {
+		t.Fatalf("failed to connect to browser: %v", err)
+	}
+	t.Cleanup(func() { _ = browser.Close() })
+
+	return browser
+}
+
+// serveFixtures replays the fixtures of dir from a test server for the rest of the test
+func serveFixtures(t *testing.T, dir string) {
+	t.Helper()
+
+	server := httptest.NewServer(http.FileServer(http.Dir(dir)))
+	fixtures = &FixtureStore{Dir: dir, Mode: FIXTURES_REPLAY, baseUrl: server.URL}
+
+	t.Cleanup(func() {
+		fixtures = nil
+		server.Close()
+	})
+}
+
+// replayFixtures replays every fixture of dir, by name
+func replayFixtures(t *testing.T, dir string) map[string]*MergedSecurity {
+	t.Helper()
+
+	browser := replayBrowser(t)
+	serveFixtures(t, dir)
+
+	names, err := ListFixtures(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) == 0 {
+		t.Fatalf("no fixtures found in %s", dir)
+	}
+
+	replayed := make(map[string]*MergedSecurity, len(names))
+	for _, name := range names {
+		merged, err := Replay(browser, name)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		replayed[name] = merged
+	}
+
+	return replayed
+}
+
+func TestFixturesMatchGolden(t *testing.T) {
+	for name, merged := range replayFixtures(t, fixturesDir) {
+		if *update {
+			err := WriteGolden(fixturesDir, merged)
+			if err != nil {
+				t.Errorf("%s: %v", name, err)
+			}
+			continue
+		}
+
+		diffs, err := VerifyGolden(fixturesDir, name, merged)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		for _, diff := range diffs {
+			t.Errorf("%s: %s", name, diff)
+		}
+	}
+}
+
+func TestFixturesParsedValues(t *testing.T) {
+	replayed := replayFixtures(t, fixturesDir)
+
+	date := func(value string) time.Time {
+		parsed, _ := time.Parse("2006-01-02", value)
+		return parsed
+	}
+
+	tests := []struct {
+		fixture string
+		check   func(t *testing.T, merged *MergedSecurity)
+	}{
+		{
+			fixture: "AAPL.NASDAQ",
+			check: func(t *testing.T, merged *MergedSecurity) {
+				security := merged.Security
+				expectEqual(t, "typology", security.Typology, "STOCK")
+				expectEqual(t, "currency", security.Currency, "USD")
+				expectEqual(t, "full name", security.FullName, "Apple Inc.")
+				expectEqual(t, "price", security.Price, 21349)
+				expectEqual(t, "price change", security.PC, 381)
+				expectEqual(t, "price change percentage", security.PCP, 182)
+				expectEqual(t, "yearly range", [2]int{security.YearLow, security.YearHigh}, [2]int{16408, 26010})
+				expectEqual(t, "daily range", [2]int{security.DayLow, security.DayHigh}, [2]int{20958, 21395})
+				expectEqual(t, "market cap", security.MarketCap.Int64, int64(3207080214528))
+				expectEqual(t, "volume", security.Volume.Int64, int64(60107582))
+				expectEqual(t, "average volume", security.AvgVolume.Int64, int64(53431520))
+				expectEqual(t, "beta", security.Beta.Int64, int64(118))
+				expectEqual(t, "bid", [2]int64{int64(security.Bid), security.BidSize.Int64}, [2]int64{21340, 300})
+				expectEqual(t, "ask", [2]int64{int64(security.Ask), security.AskSize.Int64}, [2]int64{21360, 200})
+				expectEqual(t, "pe", security.PE.Int64, int64(3389))
+				expectEqual(t, "eps", security.EPS.Int64, int64(630))
+				expectEqual(t, "target", security.Target.Int64, int64(25259))
+				expectEqual(t, "sector", security.Sector.String, "Computer and Technology")
+				expectEqual(t, "outstanding", security.Outstanding.Int64, int64(15022070000))
+				expectEqual(t, "market date", security.MarketDate.Time, date("2025-03-14"))
+
+				dividend := security.Dividend
+				if dividend == nil {
+					t.Fatal("expected a dividend")
+				}
+				expectEqual(t, "yield", dividend.Yield, 47)
+				expectEqual(t, "timing", dividend.Timing.String, "fwd")
+				expectEqual(t, "payout ratio", dividend.PayoutRatio.Int64, int64(1587))
+				expectEqual(t, "frequency", dividend.Frequency.String, "quarterly")
+				expectEqual(t, "events", len(dividend.Events), 8)
+				expectEqual(t, "last event", dividend.Events[0].ExDivDate, date("2025-02-10"))
+
+				if merged.ETF != nil || merged.REIT != nil {
+					t.Error("expected neither an ETF nor a REIT")
+				}
+			},
+		},
+		{
+			fixture: "O.NYSE",
+			check: func(t *testing.T, merged *MergedSecurity) {
+				if merged.REIT == nil {
+					t.Fatal("expected a REIT")
+				}
+
+				security := merged.REIT.Security
+				expectEqual(t, "typology", security.Typology, "REIT")
+				expectEqual(t, "full name", security.FullName, "Realty Income Corporation (O)")
+				expectEqual(t, "price", security.Price, 5688)
+				expectEqual(t, "price change percentage", security.PCP, 80)
+				expectEqual(t, "yearly range", [2]int{security.YearLow, security.YearHigh}, [2]int{5065, 6488})
+				expectEqual(t, "volume", security.Volume.Int64, int64(4215331))
+				expectEqual(t, "bid", [2]int64{int64(security.Bid), security.BidSize.Int64}, [2]int64{5685, 1000})
+				expectEqual(t, "pe", security.PE.Int64, int64(5417))
+				expectEqual(t, "eps", security.EPS.Int64, int64(105))
+				expectEqual(t, "sub industry", security.SubIndustry.String, "Retail REITs")
+				expectEqual(t, "market date known", security.MarketDate.Valid, false)
+
+				dividend := security.Dividend
+				if dividend == nil {
+					t.Fatal("expected a dividend")
+				}
+				expectEqual(t, "yield", dividend.Yield, 567)
+				expectEqual(t, "frequency", dividend.Frequency.String, "monthly")
+				expectEqual(t, "last announced", dividend.LastAnnounced.Int64, int64(27))
+				expectEqual(t, "ex-dividend date", dividend.ExDivDate.Time, date("2025-03-31"))
+				expectEqual(t, "payout date", dividend.PayoutDate.Time, date("2025-04-15"))
+				expectEqual(t, "events", len(dividend.Events), 6)
+			},
+		},
+		{
+			fixture: "VFV.TSX",
+			check: func(t *testing.T, merged *MergedSecurity) {
+				if merged.ETF == nil {
+					t.Fatal("expected an ETF")
+				}
+
+				etf := merged.ETF
+				expectEqual(t, "typology", etf.Security.Typology, "ETF")
+				expectEqual(t, "currency", etf.Security.Currency, "CAD")
+				expectEqual(t, "price", etf.Security.Price, 14123)
+				expectEqual(t, "price change", etf.Security.PC, -92)
+				expectEqual(t, "price change percentage", etf.Security.PCP, -65)
+				expectEqual(t, "market date", etf.Security.MarketDate.Time, date("2025-03-14"))
+				expectEqual(t, "family", etf.Family, "Vanguard Investments Canada Inc")
+				expectEqual(t, "aum", etf.AUM.Int64, int64(19512345600))
+				expectEqual(t, "expense ratio", etf.ExpenseRatio.Int64, int64(9))
+				expectEqual(t, "nav", etf.NAV.Int64, int64(14131))
+				expectEqual(t, "inception", etf.InceptionDate.Time, date("2012-11-06"))
+				expectEqual(t, "holdings", len(merged.Holdings), 4)
+				expectEqual(t, "top holding", merged.Holdings[0].Seed, "AAPL")
+				expectEqual(t, "top holding allocation", merged.Holdings[0].Allocation, 703)
+
+				dividend := etf.Security.Dividend
+				if dividend == nil {
+					t.Fatal("expected a dividend")
+				}
+				expectEqual(t, "yield", dividend.Yield, 108)
+				expectEqual(t, "timing", dividend.Timing.String, "ttm")
+				expectEqual(t, "ex-dividend date", dividend.ExDivDate.Time, date("2025-03-31"))
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.fixture, func(t *testing.T) {
+			merged, ok := replayed[test.fixture]
+			if !ok {
+				t.Fatalf("fixture %s was not replayed", test.fixture)
+			}
+			test.check(t, merged)
+		})
+	}
+}
+
+func expectEqual[T comparable](t *testing.T, label string, actual T, expected T) {
+	t.Helper()
+
+	if actual != expected {
+		t.Errorf("%s: expected %v, got %v", label, expected, actual)
+	}
+}
@@ -0,0 +1,117 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Francesco99975/finexo/internal/helpers"
+	"github.com/Francesco99975/finexo/internal/models"
+	"github.com/go-rod/rod"
+	"github.com/labstack/gommon/log"
+)
+
+// MarketBeatSource scrapes sector classification, analyst consensus and shares outstanding
+type MarketBeatSource struct{}
+
+func (s *MarketBeatSource) Name() string {
+	return MARKETBEAT_SOURCE
+}
+
+func (s *MarketBeatSource) URL(target *ScrapeTarget) string {
+	var marketbeatScrapingUrl string
+	if target.Exchange.Prefix.Valid {
+		marketbeatScrapingUrl = BASE_MARKETBEAT_URL + fmt.Sprintf("%s/%s", target.Exchange.Prefix.String, target.Ticker)
+	} else {
+		marketbeatScrapingUrl = BASE_MARKETBEAT_URL + fmt.Sprintf("%s/%s", target.Exchange.Title, target.Ticker)
+	}
+
+	//Adjusting For REITS
+	return strings.ReplaceAll(marketbeatScrapingUrl, "-UN", "")
+}
+
+func (s *MarketBeatSource) Fetch(ctx context.Context, target *ScrapeTarget) (*models.PartialSecurity, error) {
+	partial := models.NewPartialSecurity(s.Name())
+	page := target.Page.Context(ctx)
+
+	scrapingUrl := s.URL(target)
+	log.Debugf("Scraping MarketBeat at url: %s", scrapingUrl)
+	if err := openSource(page, target, s.Name(), scrapingUrl); err != nil {
+		var pause *HostPauseError
+		if errors.As(err, &pause) {
+			return nil, fmt.Errorf("failed to open page on MarketBeat: %w. For seed %s", err, target.Seed)
+		}
+		log.Warnf("failed to open page on MarketBeat: %v. For seed %s", err, target.Seed)
+	}
+
+	log.Debugf("Scraping MarketBeat for %s", target)
+
+	scrapedMarketBeatDataKeys, uperr := findElements(target, page, MB_DATA_KEYS)
+	scrapedMarketBeatDataValues, err := findElements(target, page, MB_DATA_VALUES)
+	if err != nil || uperr != nil || len(scrapedMarketBeatDataKeys) == 0 || len(scrapedMarketBeatDataValues) == 0 {
+		log.Warnf("failed to scrape MarketBeat data: %v. For seed %s", err, target.Seed)
+		return partial, nil
+	}
+
+	scrapedMarketBeatDataKeysArray := helpers.MapSlice(scrapedMarketBeatDataKeys, func(e *rod.Element) string {
+		return e.MustText()
+	})
+
+	scrapedMarketBeatDataValuesArray := helpers.MapSlice(scrapedMarketBeatDataValues, func(e *rod.Element) string {
+		return e.MustText()
+	})
+
+	for i := range min(len(scrapedMarketBeatDataKeysArray), len(scrapedMarketBeatDataValuesArray)) {
+		key := strings.ToLower(scrapedMarketBeatDataKeysArray[i])
+		value := scrapedMarketBeatDataValuesArray[i]
+
+		log.Debugf("Scraped MarketBeat data: %s = %s", key, value)
+
+		if strings.Contains(key, "sector") {
+			partial.Sector = models.ValidString(value)
+		}
+
+		if key == "industry" {
+			partial.Industry = models.ValidString(value)
+		}
+
+		if strings.Contains(key, "sub") {
+			partial.SubIndustry = models.ValidString(value)
+		}
+
+		if strings.Contains(key, "consensus") {
+			partial.Consensus = models.ValidString(value)
+		}
+
+		if strings.Contains(key, "score") {
+			scrapedScore, err := parseCents(value)
+			if err != nil {
+				log.Warnf("failed to parse score: %v. For seed %s", err, target.Seed)
+			} else {
+				partial.Score = models.ValidInt(int64(scrapedScore))
+			}
+		}
+
+		if strings.Contains(key, "coverage") {
+			scrapedCoverage, err := strconv.Atoi(strings.Split(value, " ")[0])
+			if err != nil {
+				log.Warnf("failed to parse coverage: %v. For seed %s", err, target.Seed)
+			} else {
+				partial.Coverage = models.ValidInt(int64(scrapedCoverage))
+			}
+		}
+
+		if strings.Contains(key, "outstanding") {
+			scrapedOutstanding, err := strconv.ParseInt(helpers.NormalizeFloatStrToIntStr(value), 10, 64)
+			if err != nil {
+				log.Warnf("failed to parse outstanding: %v. For seed %s", err, target.Seed)
+			} else {
+				partial.Outstanding = models.ValidInt(scrapedOutstanding)
+			}
+		}
+	}
+
+	return partial, nil
+}
@@ -0,0 +1,260 @@
+package tools
+
+import (
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/Francesco99975/finexo/internal/models"
+)
+
+// DefaultPrecedence is the order in which sources are trusted for any field without a specific rule
+var DefaultPrecedence = []string{YAHOO_SOURCE, MARKETBEAT_SOURCE, DIVIDENDHISTORY_SOURCE}
+
+// FieldPrecedence overrides DefaultPrecedence for the fields a provider is known to be better at.
+// Keys are the json names of the PartialSecurity fields.
+var FieldPrecedence = map[string][]string{
+	"sector":         {MARKETBEAT_SOURCE, YAHOO_SOURCE},
+	"industry":       {MARKETBEAT_SOURCE, YAHOO_SOURCE},
+	"subIndustry":    {MARKETBEAT_SOURCE, YAHOO_SOURCE},
+	"consensus":      {MARKETBEAT_SOURCE, YAHOO_SOURCE},
+	"score":          {MARKETBEAT_SOURCE, YAHOO_SOURCE},
+	"coverage":       {MARKETBEAT_SOURCE, YAHOO_SOURCE},
+	"outstanding":    {MARKETBEAT_SOURCE, YAHOO_SOURCE},
+	"payoutRatio":    {DIVIDENDHISTORY_SOURCE, YAHOO_SOURCE},
+	"lastAnnounced":  {DIVIDENDHISTORY_SOURCE, YAHOO_SOURCE},
+	"frequency":      {DIVIDENDHISTORY_SOURCE, YAHOO_SOURCE},
+	"exDivDate":      {DIVIDENDHISTORY_SOURCE, YAHOO_SOURCE},
+	"payoutDate":     {DIVIDENDHISTORY_SOURCE, YAHOO_SOURCE},
+	"dividendEvents": {DIVIDENDHISTORY_SOURCE},
+}
+
+// MergedSecurity is the outcome of merging every partial scraped for a security.
+// ETF or REIT is set according to the typology, Holdings still have to be resolved against the exchanges.
+type MergedSecurity struct {
+	Security models.Security         `json:"security"`
+	ETF      *models.ETF             `json:"etf,omitempty"`
+	REIT     *models.REIT            `json:"reit,omitempty"`
+	Holdings []models.PartialHolding `json:"holdings,omitempty"`
+}
+
+type merger struct {
+	partials []*models.PartialSecurity
+}
+
+// ordered returns the partials sorted by the precedence of the given field.
+// Sources without a rule keep their fetch order after the ranked ones.
+func (m *merger) ordered(field string) []*models.PartialSecurity {
+	precedence, ok := FieldPrecedence[field]
+	if !ok {
+		precedence = DefaultPrecedence
+	}
+
+	rank := func(p *models.PartialSecurity) int {
+		index := slices.Index(precedence, p.Source)
+		if index == -1 {
+			return len(precedence)
+		}
+		return index
+	}
+
+	ordered := slices.Clone(m.partials)
+	slices.SortStableFunc(ordered, func(a, b *models.PartialSecurity) int {
+		return rank(a) - rank(b)
+	})
+
+	return ordered
+}
+
+func (m *merger) str(field string, get func(*models.PartialSecurity) models.NullableString) models.NullableString {
+	for _, partial := range m.ordered(field) {
+		if value := get(partial); value.Valid {
+			return value
+		}
+	}
+	return models.NullableString{Valid: false}
+}
+
+func (m *merger) num(field string, get func(*models.PartialSecurity) models.NullableInt) models.NullableInt {
+	for _, partial := range m.ordered(field) {
+		if value := get(partial); value.Valid {
+			return value
+		}
+	}
+	return models.NullableInt{Valid: false}
+}
+
+func (m *merger) date(field string, get func(*models.PartialSecurity) models.NullableTime) models.NullableTime {
+	for _, partial := range m.ordered(field) {
+		if value := get(partial); value.Valid {
+			return value
+		}
+	}
+	return models.NullableTime{Valid: false}
+}
+
+// MergePartials combines the partial results of every source into a security, field by field
+func MergePartials(ticker string, exchange string, partials []*models.PartialSecurity) (*MergedSecurity, error) {
+	m := &merger{partials: compactPartials(partials)}
+	target := ticker + ":" + exchange
+
+	required := func(field string, value bool) error {
+		if !value {
+			return fmt.Errorf("no source provided %s - target: %s", field, target)
+		}
+		return nil
+	}
+
+	typology := m.str("typology", func(p *models.PartialSecurity) models.NullableString { return p.Typology })
+	currency := m.str("currency", func(p *models.PartialSecurity) models.NullableString { return p.Currency })
+	fullName := m.str("fullName", func(p *models.PartialSecurity) models.NullableString { return p.FullName })
+	price := m.num("price", func(p *models.PartialSecurity) models.NullableInt { return p.Price })
+	pc := m.num("pc", func(p *models.PartialSecurity) models.NullableInt { return p.PC })
+	pcp := m.num("pcp", func(p *models.PartialSecurity) models.NullableInt { return p.PCP })
+	yrl := m.num("yearLow", func(p *models.PartialSecurity) models.NullableInt { return p.YearLow })
+	yrh := m.num("yearHigh", func(p *models.PartialSecurity) models.NullableInt { return p.YearHigh })
+	drl := m.num("dayLow", func(p *models.PartialSecurity) models.NullableInt { return p.DayLow })
+	drh := m.num("dayHigh", func(p *models.PartialSecurity) models.NullableInt { return p.DayHigh })
+	pclose := m.num("previousClose", func(p *models.PartialSecurity) models.NullableInt { return p.PClose })
+	copen := m.num("currentOpen", func(p *models.PartialSecurity) models.NullableInt { return p.COpen })
+
+	for _, check := range []struct {
+		field string
+		valid bool
+	}{
+		{"typology", typology.Valid}, {"currency", currency.Valid}, {"full name", fullName.Valid},
+		{"price", price.Valid}, {"price change", pc.Valid}, {"price change percentage", pcp.Valid},
+		{"yearly range low", yrl.Valid}, {"yearly range high", yrh.Valid},
+		{"daily range low", drl.Valid}, {"daily range high", drh.Valid},
+		{"previous close", pclose.Valid}, {"open", copen.Valid},
+	} {
+		if err := required(check.field, check.valid); err != nil {
+			return nil, err
+		}
+	}
+
+	security := models.Security{
+		Ticker:      ticker,
+		Exchange:    exchange,
+		Typology:    typology.String,
+		Currency:    currency.String,
+		FullName:    fullName.String,
+		Sector:      m.str("sector", func(p *models.PartialSecurity) models.NullableString { return p.Sector }),
+		Industry:    m.str("industry", func(p *models.PartialSecurity) models.NullableString { return p.Industry }),
+		SubIndustry: m.str("subIndustry", func(p *models.PartialSecurity) models.NullableString { return p.SubIndustry }),
+		Price:       int(price.Int64),
+		PC:          int(pc.Int64),
+		PCP:         int(pcp.Int64),
+		YearLow:     int(yrl.Int64),
+		YearHigh:    int(yrh.Int64),
+		DayLow:      int(drl.Int64),
+		DayHigh:     int(drh.Int64),
+		Consensus:   m.str("consensus", func(p *models.PartialSecurity) models.NullableString { return p.Consensus }),
+		Score:       m.num("score", func(p *models.PartialSecurity) models.NullableInt { return p.Score }),
+		Coverage:    m.num("coverage", func(p *models.PartialSecurity) models.NullableInt { return p.Coverage }),
+		MarketCap:   m.num("marketCap", func(p *models.PartialSecurity) models.NullableInt { return p.MarketCap }),
+		Volume:      m.num("volume", func(p *models.PartialSecurity) models.NullableInt { return p.Volume }),
+		AvgVolume:   m.num("avgVolume", func(p *models.PartialSecurity) models.NullableInt { return p.AvgVolume }),
+		Outstanding: m.num("outstanding", func(p *models.PartialSecurity) models.NullableInt { return p.Outstanding }),
+		Beta:        m.num("beta", func(p *models.PartialSecurity) models.NullableInt { return p.Beta }),
+		PClose:      int(pclose.Int64),
+		COpen:       int(copen.Int64),
+		Bid:         int(price.Int64),
+		BidSize:     m.num("bidSize", func(p *models.PartialSecurity) models.NullableInt { return p.BidSize }),
+		Ask:         int(price.Int64),
+		AskSize:     m.num("askSize", func(p *models.PartialSecurity) models.NullableInt { return p.AskSize }),
+		EPS:         m.num("eps", func(p *models.PartialSecurity) models.NullableInt { return p.EPS }),
+		PE:          m.num("pe", func(p *models.PartialSecurity) models.NullableInt { return p.PE }),
+		Target:      m.num("target", func(p *models.PartialSecurity) models.NullableInt { return p.Target }),
+		STM:         m.str("stm", func(p *models.PartialSecurity) models.NullableString { return p.STM }),
+		MarketDate:  m.date("marketDate", func(p *models.PartialSecurity) models.NullableTime { return p.MarketDate }),
+	}
+
+	// Empty quotes fall back to the price
+	if bid := m.num("bid", func(p *models.PartialSecurity) models.NullableInt { return p.Bid }); bid.Valid {
+		security.Bid = int(bid.Int64)
+	}
+
+	if ask := m.num("ask", func(p *models.PartialSecurity) models.NullableInt { return p.Ask }); ask.Valid {
+		security.Ask = int(ask.Int64)
+	}
+
+	security.Dividend = m.dividend(&security)
+
+	merged := &MergedSecurity{Security: security}
+
+	switch security.Typology {
+	case "STOCK":
+	case "ETF":
+		merged.ETF = &models.ETF{
+			Security:      security,
+			Family:        m.str("family", func(p *models.PartialSecurity) models.NullableString { return p.Family }).String,
+			AUM:           m.num("aum", func(p *models.PartialSecurity) models.NullableInt { return p.AUM }),
+			ExpenseRatio:  m.num("expenseRatio", func(p *models.PartialSecurity) models.NullableInt { return p.ExpenseRatio }),
+			NAV:           m.num("nav", func(p *models.PartialSecurity) models.NullableInt { return p.NAV }),
+			InceptionDate: m.date("inception", func(p *models.PartialSecurity) models.NullableTime { return p.InceptionDate }),
+		}
+
+		for _, partial := range m.ordered("holdings") {
+			if len(partial.Holdings) > 0 {
+				merged.Holdings = partial.Holdings
+				break
+			}
+		}
+	case "REIT":
+		merged.REIT = &models.REIT{Security: security}
+	default:
+		return nil, fmt.Errorf("invalid typology: %s - target: %s", security.Typology, target)
+	}
+
+	return merged, nil
+}
+
+// dividend builds the dividend of the security, only when some source found a yield
+func (m *merger) dividend(security *models.Security) *models.Dividend {
+	yield := m.num("yield", func(p *models.PartialSecurity) models.NullableInt { return p.Yield })
+	if !yield.Valid {
+		return nil
+	}
+
+	dividend := &models.Dividend{
+		Ticker:        security.Ticker,
+		Exchange:      security.Exchange,
+		Yield:         int(yield.Int64),
+		Timing:        m.str("dividendTiming", func(p *models.PartialSecurity) models.NullableString { return p.DividendTiming }),
+		PayoutRatio:   m.num("payoutRatio", func(p *models.PartialSecurity) models.NullableInt { return p.PayoutRatio }),
+		LastAnnounced: m.num("lastAnnounced", func(p *models.PartialSecurity) models.NullableInt { return p.LastAnnounced }),
+		Frequency:     m.str("frequency", func(p *models.PartialSecurity) models.NullableString { return p.Frequency }),
+		ExDivDate:     m.date("exDivDate", func(p *models.PartialSecurity) models.NullableTime { return p.ExDivDate }),
+		PayoutDate:    m.date("payoutDate", func(p *models.PartialSecurity) models.NullableTime { return p.PayoutDate }),
+	}
+
+	if dividend.Frequency.Valid && dividend.LastAnnounced.Valid {
+		if dividend.Frequency.String != string(models.FrequencyUnknown) {
+			dividend.AnnualPayout = models.ValidInt(dividend.LastAnnounced.Int64 * int64(operandByFrequency(&dividend.Frequency.String)))
+		} else {
+			dividend.AnnualPayout = models.ValidInt(int64(math.Floor(float64(security.Price) * (float64(dividend.Yield) / 100) / 100)))
+		}
+	}
+
+	for _, partial := range m.ordered("dividendEvents") {
+		if len(partial.DividendEvents) > 0 {
+			dividend.Events = slices.Clone(partial.DividendEvents)
+			break
+		}
+	}
+
+	for i := range dividend.Events {
+		dividend.Events[i].Ticker = security.Ticker
+		dividend.Events[i].Exchange = security.Exchange
+	}
+
+	return dividend
+}
+
+// compactPartials drops the sources that returned nothing
+func compactPartials(partials []*models.PartialSecurity) []*models.PartialSecurity {
+	return slices.DeleteFunc(slices.Clone(partials), func(p *models.PartialSecurity) bool {
+		return p == nil
+	})
+}
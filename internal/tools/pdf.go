@@ -2,8 +2,6 @@ package tools
 
 import (
 	"fmt"
-	"strconv"
-	"strings"
 	"time"
 
 	"github.com/Francesco99975/finexo/internal/helpers"
@@ -22,30 +20,11 @@ import (
 )
 
 // GeneratePDF generates a PDF file with investment calculation results
-func GeneratePDF(results helpers.CalculationResults) (string, error) {
+func GeneratePDF(results helpers.CalculationResults, f helpers.Formatter) (string, error) {
 	// Generate filename with timestamp
 	timestamp := time.Now().Unix()
 	filename := fmt.Sprintf("%s-%d.pdf", results.SID, timestamp)
 
-	contributionsMonthOneStr := helpers.NormalizeFloatStrToIntStr(strings.ReplaceAll(results.YearResults[0].MonthsResults[0].Contributions[3:], " ", ""))
-
-	contributionsMonthOneInt, err := strconv.Atoi(contributionsMonthOneStr)
-	if err != nil {
-		return "", err
-	}
-
-	PrincipalStr := helpers.NormalizeFloatStrToIntStr(strings.ReplaceAll(results.Principal[3:], " ", ""))
-	PrincipalInt, err := strconv.Atoi(PrincipalStr)
-	if err != nil {
-		return "", err
-	}
-
-	contrib := float64((contributionsMonthOneInt - PrincipalInt) / 100)
-	contribStr, err := helpers.FormatPrice(contrib, results.Currency)
-	if err != nil {
-		return "", err
-	}
-
 	// Configure PDF settings (A4, horizontal orientation)
 	cfg := config.NewBuilder().
 		WithPageSize(pagesize.A4).
@@ -62,8 +41,12 @@ func GeneratePDF(results helpers.CalculationResults) (string, error) {
 	black := &props.Color{Red: 0, Green: 0, Blue: 0}           // Black for borders
 
 	var title string
-	if results.Price != "" {
-		title = fmt.Sprintf("Investment Summary for %s with a starting price of %s", results.SID, results.Price)
+	if results.Backtest != nil {
+		title = fmt.Sprintf("Backtest of %s from %s", results.SID, f.Period(*results.Backtest))
+	} else if results.Price != nil {
+		title = fmt.Sprintf("Investment Summary for %s with a starting price of %s", results.SID, f.Amount(*results.Price))
+	} else if len(results.Holdings) > 0 {
+		title = "Portfolio Summary"
 	} else {
 		title = "HISA Calculation"
 	}
@@ -82,11 +65,17 @@ func GeneratePDF(results helpers.CalculationResults) (string, error) {
 		),
 	)
 
+	// The withdrawal years follow the years of compounding
+	compoundingYears := len(results.YearResults)
+	if results.Withdrawal != nil {
+		compoundingYears -= results.Withdrawal.Years
+	}
+
 	// Add Subtitle
 	m.AddRows(
 		row.New(15).Add(
 			col.New(12).Add(
-				text.New(fmt.Sprintf("Calculated compounding growth for %d years with a principal of %s @ a starting rate of %s", len(results.YearResults), results.Principal, results.Rate), props.Text{
+				text.New(fmt.Sprintf("Calculated compounding growth for %d years with a principal of %s @ a starting rate of %s", compoundingYears, f.Amount(results.Principal), f.Percent(results.Rate)), props.Text{
 					Size:  12,
 					Align: align.Center,
 					Style: fontstyle.Italic,
@@ -96,6 +85,22 @@ func GeneratePDF(results helpers.CalculationResults) (string, error) {
 		),
 	)
 
+	// Basis of the amounts
+	if results.RealValues {
+		m.AddRows(
+			row.New(10).Add(
+				col.New(12).Add(
+					text.New(fmt.Sprintf("Amounts are real values, in today's money at %s inflation", f.Percent(*results.Inflation)), props.Text{
+						Size:  10,
+						Align: align.Center,
+						Style: fontstyle.Italic,
+						Color: gray,
+					}),
+				),
+			),
+		)
+	}
+
 	// Overview Section Header
 	m.AddRows(
 		row.New(15).Add(
@@ -112,21 +117,110 @@ func GeneratePDF(results helpers.CalculationResults) (string, error) {
 	// Overview Data
 	overviewData := [][]string{
 		{"SID", results.SID},
-		{"Principal", results.Principal},
-		{"Rate", results.Rate},
+		{"Principal", f.Amount(results.Principal)},
+		{"Rate", f.Percent(results.Rate)},
 		{"Rate Frequency", results.RateFreq},
 		{"Currency", results.Currency},
-		{"Profit", results.Profit},
-		{"Total Contributions", results.TotalContributions},
+		{"Profit", f.Amount(results.Profit)},
+		{"Total Contributions", f.Amount(results.TotalContributions)},
 		{"Contribution Frequency", results.ContribFreq},
-		{"Final Balance", results.FinalBalance},
-		{"Contribution", contribStr},
+		{"Final Balance", f.Amount(results.FinalBalance)},
+		{"Contribution", f.Amount(results.Contribution)},
+	}
+	if results.AfterTaxBalance != nil {
+		overviewData = append(overviewData,
+			[]string{"Account Type", results.AccountType},
+			[]string{"Total Tax Drag", f.MaybeAmount(results.TotalTaxDrag)},
+			[]string{"After-Tax Balance", f.Amount(*results.AfterTaxBalance)},
+		)
+	}
+	if results.Backtest != nil {
+		overviewData = append(overviewData,
+			[]string{"CAGR", f.Percent(results.Backtest.CAGR)},
+			[]string{"Max Drawdown", f.Percent(-results.Backtest.MaxDrawdown)},
+			[]string{"Total Dividends", f.Amount(results.Backtest.TotalDividends)},
+			[]string{"Yield on Cost", f.Percent(results.Backtest.YieldOnCost)},
+		)
+		if !results.Backtest.DRIP {
+			overviewData = append(overviewData, []string{"Dividends in Cash", f.Amount(results.Backtest.Cash)})
+		}
+	}
+	if results.Goal != nil {
+		overviewData = append(overviewData,
+			[]string{"Goal", fmt.Sprintf("%s of %s", f.Goal(*results.Goal), f.Amount(results.Goal.Target))},
+			[]string{"Solved " + f.Solve(*results.Goal), f.Solution(*results.Goal)},
+			[]string{"Goal Reached", f.Amount(results.Goal.Reached)},
+		)
 	}
 	m.AddRows(buildOverviewTable(overviewData, lightGray, lightBlue, black)...)
 
 	// Add spacing
 	m.AddRows(row.New(15))
 
+	// Withdrawal Phase
+	if results.Withdrawal != nil {
+		m.AddRows(
+			row.New(15).Add(
+				col.New(12).Add(
+					text.New(fmt.Sprintf("Withdrawal Phase (%s, indexed to %s inflation)", f.WithdrawalStrategy(*results.Withdrawal), f.Percent(results.Withdrawal.Inflation)), props.Text{
+						Size:  14,
+						Style: fontstyle.Bold,
+						Color: lightBlue,
+					}),
+				),
+			),
+		)
+		m.AddRows(buildKeyValueTable([][]string{
+			{"Starting Balance", f.Amount(results.Withdrawal.StartBalance)},
+			{"First Year Income", f.Amount(results.Withdrawal.FirstYearIncome)},
+			{"Sustainable Monthly Income (Today's Money)", f.Amount(results.Withdrawal.SustainableIncome)},
+			{"Total Withdrawn", f.Amount(results.Withdrawal.TotalWithdrawn)},
+			{"Depletion Year", f.DepletionYear(*results.Withdrawal)},
+			{"Ending Balance", f.Amount(results.Withdrawal.EndBalance)},
+		}, 10, lightGray, black)...)
+		m.AddRows(row.New(15))
+	}
+
+	// Portfolio Holdings
+	if len(results.Holdings) > 0 {
+		m.AddRows(
+			row.New(15).Add(
+				col.New(12).Add(
+					text.New(fmt.Sprintf("Holdings (rebalancing: %s)", results.Rebalance), props.Text{
+						Size:  14,
+						Style: fontstyle.Bold,
+						Color: lightBlue,
+					}),
+				),
+			),
+		)
+		m.AddRows(buildHoldingsTable(results.Holdings, f, white, lightGray, darkBlue, black)...)
+		m.AddRows(row.New(15))
+	}
+
+	// Monte Carlo Spread
+	if results.Simulation != nil {
+		m.AddRows(
+			row.New(15).Add(
+				col.New(12).Add(
+					text.New(fmt.Sprintf("Simulated Balances (%d paths, %s annual volatility)", results.Simulation.Paths, f.Percent(results.Simulation.Volatility)), props.Text{
+						Size:  14,
+						Style: fontstyle.Bold,
+						Color: lightBlue,
+					}),
+				),
+			),
+		)
+		if results.Simulation.Target != nil {
+			m.AddRows(buildKeyValueTable([][]string{
+				{"Target", f.Amount(*results.Simulation.Target)},
+				{"Target Probability", f.Percent(*results.Simulation.TargetProbability)},
+			}, 10, lightGray, black)...)
+		}
+		m.AddRows(buildSimulationTable(results.Simulation.YearResults, f, white, lightGray, darkBlue, black)...)
+		m.AddRows(row.New(15))
+	}
+
 	// Yearly Breakdown
 	for _, year := range results.YearResults {
 		m.AddRows(row.New(0)) // Page break
@@ -141,14 +235,21 @@ func GeneratePDF(results helpers.CalculationResults) (string, error) {
 				),
 			),
 		)
-		m.AddRows(buildKeyValueTable([][]string{
-			{"Share Amount", year.ShareAmount},
-			{"Total Year Gains", year.TotalYearGains},
-			{"Cumulative Gain", year.CumGain},
-			{"YoY Growth", year.YoyGrowth},
-			{"Total Growth", year.TotalGrowth},
-			{"Balance", year.Balance},
-		}, 10, lightGray, black)...)
+		yearData := [][]string{
+			{"Share Amount", f.Shares(year.ShareAmount)},
+			{"Total Year Gains", f.Amount(year.TotalYearGains)},
+			{"Cumulative Gain", f.Amount(year.CumGain)},
+			{"YoY Growth", f.Percent(year.YoyGrowth)},
+			{"Total Growth", f.Percent(year.TotalGrowth)},
+			{"Balance", f.Amount(year.Balance)},
+		}
+		if year.TaxDrag != nil {
+			yearData = append(yearData, []string{"Tax Drag", f.Amount(*year.TaxDrag)})
+		}
+		if year.Withdrawn != nil {
+			yearData = append(yearData, []string{"Withdrawn", f.Amount(*year.Withdrawn)})
+		}
+		m.AddRows(buildKeyValueTable(yearData, 10, lightGray, black)...)
 		m.AddRows(
 			row.New(8).Add(
 				col.New(12).Add(
@@ -160,7 +261,7 @@ func GeneratePDF(results helpers.CalculationResults) (string, error) {
 				),
 			),
 		)
-		m.AddRows(buildMonthTable(year.MonthsResults, white, lightGray, darkBlue, black)...)
+		m.AddRows(buildMonthTable(year.MonthsResults, f, white, lightGray, darkBlue, black)...)
 	}
 
 	// Generate and save the PDF
@@ -184,7 +285,7 @@ func buildOverviewTable(data [][]string, labelBgColor *props.Color, highlightCol
 		key1 := data[i][0]
 		value1 := data[i][1]
 		valueProps := props.Text{Size: 10, Align: align.Center}
-		if key1 == "Final Balance" || key1 == "Profit" {
+		if key1 == "Final Balance" || key1 == "Profit" || key1 == "After-Tax Balance" {
 			valueProps.Style = fontstyle.Bold
 			valueProps.Color = highlightColor
 		}
@@ -214,7 +315,7 @@ func buildOverviewTable(data [][]string, labelBgColor *props.Color, highlightCol
 			key2 := data[i+1][0]
 			value2 := data[i+1][1]
 			valueProps2 := props.Text{Size: 10, Align: align.Center}
-			if key2 == "Final Balance" || key2 == "Profit" {
+			if key2 == "Final Balance" || key2 == "Profit" || key2 == "After-Tax Balance" {
 				valueProps2.Style = fontstyle.Bold
 				valueProps2.Color = highlightColor
 			}
@@ -295,7 +396,7 @@ func buildKeyValueTable(data [][]string, rowHeight float64, labelBgColor *props.
 }
 
 // buildMonthTable creates a table for monthly results with borders
-func buildMonthTable(months []helpers.MonthCalcResults, white *props.Color, lightGray *props.Color, darkBlue *props.Color, borderColor *props.Color) []core.Row {
+func buildMonthTable(months []helpers.MonthCalcResults, f helpers.Formatter, white *props.Color, lightGray *props.Color, darkBlue *props.Color, borderColor *props.Color) []core.Row {
 	// Define headers
 	headers := []string{"Month", "Shares", "Contrib.", "Price Gain", "Div. Gain", "Monthly Gain", "Cum. Gain", "Balance", "Return", "DRIP"}
 	headerRow := row.New(12).Add(
@@ -320,16 +421,64 @@ func buildMonthTable(months []helpers.MonthCalcResults, white *props.Color, ligh
 		}
 		rows = append(rows, row.New(9).Add(
 			col.New(2).Add(text.New(month.MonthName, props.Text{Size: 9, Align: align.Center})).WithStyle(&props.Cell{BackgroundColor: bgColor, BorderType: border.Full, BorderThickness: 0.2, BorderColor: borderColor}),
-			col.New(1).Add(text.New(month.ShareAmount, props.Text{Size: 9, Align: align.Center})).WithStyle(&props.Cell{BackgroundColor: bgColor, BorderType: border.Full, BorderThickness: 0.2, BorderColor: borderColor}),
-			col.New(1).Add(text.New(month.Contributions, props.Text{Size: 9, Align: align.Center})).WithStyle(&props.Cell{BackgroundColor: bgColor, BorderType: border.Full, BorderThickness: 0.2, BorderColor: borderColor}),
-			col.New(1).Add(text.New(month.MonthlyGainedFromPriceInc, props.Text{Size: 9, Align: align.Center})).WithStyle(&props.Cell{BackgroundColor: bgColor, BorderType: border.Full, BorderThickness: 0.2, BorderColor: borderColor}),
-			col.New(1).Add(text.New(month.MonthlyGainedFromDividends, props.Text{Size: 9, Align: align.Center})).WithStyle(&props.Cell{BackgroundColor: bgColor, BorderType: border.Full, BorderThickness: 0.2, BorderColor: borderColor}),
-			col.New(1).Add(text.New(month.MonthlyGain, props.Text{Size: 9, Align: align.Center})).WithStyle(&props.Cell{BackgroundColor: bgColor, BorderType: border.Full, BorderThickness: 0.2, BorderColor: borderColor}),
-			col.New(1).Add(text.New(month.CumGain, props.Text{Size: 9, Align: align.Center})).WithStyle(&props.Cell{BackgroundColor: bgColor, BorderType: border.Full, BorderThickness: 0.2, BorderColor: borderColor}),
-			col.New(2).Add(text.New(month.Balance, props.Text{Size: 9, Align: align.Center})).WithStyle(&props.Cell{BackgroundColor: bgColor, BorderType: border.Full, BorderThickness: 0.2, BorderColor: borderColor}),
-			col.New(1).Add(text.New(month.Return, props.Text{Size: 9, Align: align.Center})).WithStyle(&props.Cell{BackgroundColor: bgColor, BorderType: border.Full, BorderThickness: 0.2, BorderColor: borderColor}),
-			col.New(1).Add(text.New(month.DRIP, props.Text{Size: 9, Align: align.Center})).WithStyle(&props.Cell{BackgroundColor: bgColor, BorderType: border.Full, BorderThickness: 0.2, BorderColor: borderColor}),
+			col.New(1).Add(text.New(f.Shares(month.ShareAmount), props.Text{Size: 9, Align: align.Center})).WithStyle(&props.Cell{BackgroundColor: bgColor, BorderType: border.Full, BorderThickness: 0.2, BorderColor: borderColor}),
+			col.New(1).Add(text.New(f.Amount(month.Contributions), props.Text{Size: 9, Align: align.Center})).WithStyle(&props.Cell{BackgroundColor: bgColor, BorderType: border.Full, BorderThickness: 0.2, BorderColor: borderColor}),
+			col.New(1).Add(text.New(f.Amount(month.MonthlyGainedFromPriceInc), props.Text{Size: 9, Align: align.Center})).WithStyle(&props.Cell{BackgroundColor: bgColor, BorderType: border.Full, BorderThickness: 0.2, BorderColor: borderColor}),
+			col.New(1).Add(text.New(f.MaybeAmount(month.MonthlyGainedFromDividends), props.Text{Size: 9, Align: align.Center})).WithStyle(&props.Cell{BackgroundColor: bgColor, BorderType: border.Full, BorderThickness: 0.2, BorderColor: borderColor}),
+			col.New(1).Add(text.New(f.Amount(month.MonthlyGain), props.Text{Size: 9, Align: align.Center})).WithStyle(&props.Cell{BackgroundColor: bgColor, BorderType: border.Full, BorderThickness: 0.2, BorderColor: borderColor}),
+			col.New(1).Add(text.New(f.Amount(month.CumGain), props.Text{Size: 9, Align: align.Center})).WithStyle(&props.Cell{BackgroundColor: bgColor, BorderType: border.Full, BorderThickness: 0.2, BorderColor: borderColor}),
+			col.New(2).Add(text.New(f.Amount(month.Balance), props.Text{Size: 9, Align: align.Center})).WithStyle(&props.Cell{BackgroundColor: bgColor, BorderType: border.Full, BorderThickness: 0.2, BorderColor: borderColor}),
+			col.New(1).Add(text.New(f.Percent(month.Return), props.Text{Size: 9, Align: align.Center})).WithStyle(&props.Cell{BackgroundColor: bgColor, BorderType: border.Full, BorderThickness: 0.2, BorderColor: borderColor}),
+			col.New(1).Add(text.New(f.DRIP(month.DRIPShares), props.Text{Size: 9, Align: align.Center})).WithStyle(&props.Cell{BackgroundColor: bgColor, BorderType: border.Full, BorderThickness: 0.2, BorderColor: borderColor}),
 		))
 	}
 	return rows
 }
+
+// buildSimulationTable creates a table for the simulated percentiles with borders
+func buildSimulationTable(years []helpers.SimulationYearResults, f helpers.Formatter, white *props.Color, lightGray *props.Color, darkBlue *props.Color, borderColor *props.Color) []core.Row {
+	headerRow := row.New(12)
+	for _, header := range []string{"Year", "10th Percentile", "Median", "90th Percentile"} {
+		headerRow.Add(col.New(3).Add(text.New(header, props.Text{Size: 9, Align: align.Center, Style: fontstyle.Bold, Color: white})).WithStyle(&props.Cell{BackgroundColor: darkBlue, BorderType: border.Full, BorderThickness: 0.2, BorderColor: borderColor}))
+	}
+	rows := []core.Row{headerRow}
+
+	// Add data rows with alternating background colors
+	for i, year := range years {
+		bgColor := white
+		if i%2 == 1 {
+			bgColor = lightGray
+		}
+		r := row.New(9)
+		for _, value := range []string{year.YearName, f.Amount(year.P10), f.Amount(year.P50), f.Amount(year.P90)} {
+			r.Add(col.New(3).Add(text.New(value, props.Text{Size: 9, Align: align.Center})).WithStyle(&props.Cell{BackgroundColor: bgColor, BorderType: border.Full, BorderThickness: 0.2, BorderColor: borderColor}))
+		}
+		rows = append(rows, r)
+	}
+	return rows
+}
+
+// buildHoldingsTable creates a table for the holdings of a portfolio with borders
+func buildHoldingsTable(holdings []helpers.HoldingResults, f helpers.Formatter, white *props.Color, lightGray *props.Color, darkBlue *props.Color, borderColor *props.Color) []core.Row {
+	widths := []int{4, 2, 2, 2, 2}
+
+	headerRow := row.New(12)
+	for i, header := range []string{"Holding", "Weight", "Income", "Income Share", "Final Balance"} {
+		headerRow.Add(col.New(widths[i]).Add(text.New(header, props.Text{Size: 9, Align: align.Center, Style: fontstyle.Bold, Color: white})).WithStyle(&props.Cell{BackgroundColor: darkBlue, BorderType: border.Full, BorderThickness: 0.2, BorderColor: borderColor}))
+	}
+	rows := []core.Row{headerRow}
+
+	// Add data rows with alternating background colors
+	for i, holding := range holdings {
+		bgColor := white
+		if i%2 == 1 {
+			bgColor = lightGray
+		}
+		r := row.New(9)
+		for j, value := range []string{holding.SID, f.Percent(holding.Weight), f.Amount(holding.Income), f.Percent(holding.IncomeShare), f.Amount(holding.FinalBalance)} {
+			r.Add(col.New(widths[j]).Add(text.New(value, props.Text{Size: 9, Align: align.Center})).WithStyle(&props.Cell{BackgroundColor: bgColor, BorderType: border.Full, BorderThickness: 0.2, BorderColor: borderColor}))
+		}
+		rows = append(rows, r)
+	}
+	return rows
+}
@@ -0,0 +1,123 @@
+package tools
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/Francesco99975/finexo/internal/models"
+)
+
+// Share of a refresh run kept for seeds that are not stored yet
+const discoveryShare = 0.1
+
+// RefreshPlan lists the seeds of a refresh run in the order they are to be scraped
+type RefreshPlan struct {
+	Seeds []string
+	// Due counts the stored securities that would go past the maximum age before the next run,
+	// more than the plan holds when the budget is too small to honor it
+	Due       int
+	Known     int // Stored securities in the plan
+	Discovery int // Seeds not stored yet in the plan
+}
+
+// PlanRefresh picks up to size seeds to refresh. Stored securities that would go past maxAge before the
+// next run, interval from now, come first, oldest first. A share of the rest goes to seeds not stored yet,
+// in the order given, the remaining stored securities being ranked by age, volume and popularity.
+func PlanRefresh(candidates []models.RefreshCandidate, seeds []string, size int, maxAge, interval time.Duration) RefreshPlan {
+	plan := RefreshPlan{}
+	if size <= 0 {
+		return plan
+	}
+
+	horizon := max(maxAge-interval, 0)
+
+	known := make(map[string]bool, len(candidates))
+	due := []models.RefreshCandidate{}
+	rest := []models.RefreshCandidate{}
+	for _, candidate := range candidates {
+		known[candidate.Seed] = true
+		if candidate.Age >= horizon {
+			due = append(due, candidate)
+		} else {
+			rest = append(rest, candidate)
+		}
+	}
+	plan.Due = len(due)
+
+	unknown := []string{}
+	for _, seed := range seeds {
+		if !known[seed] {
+			unknown = append(unknown, seed)
+		}
+	}
+
+	slices.SortFunc(due, func(a, b models.RefreshCandidate) int { return cmp.Compare(b.Age, a.Age) })
+
+	scores := refreshScores(rest, maxAge)
+	slices.SortStableFunc(rest, func(a, b models.RefreshCandidate) int { return cmp.Compare(scores[b.Seed], scores[a.Seed]) })
+
+	add := func(seed string, stored bool) {
+		plan.Seeds = append(plan.Seeds, seed)
+		if stored {
+			plan.Known++
+		} else {
+			plan.Discovery++
+		}
+	}
+
+	for _, candidate := range due {
+		if len(plan.Seeds) == size {
+			return plan
+		}
+		add(candidate.Seed, true)
+	}
+
+	// Room is kept for new seeds, the stored securities getting back what they leave unused
+	discovery := min(int(math.Ceil(float64(size)*discoveryShare)), len(unknown), size-len(plan.Seeds))
+	ranked := min(size-len(plan.Seeds)-discovery, len(rest))
+
+	for _, candidate := range rest[:ranked] {
+		add(candidate.Seed, true)
+	}
+	for _, seed := range unknown {
+		if len(plan.Seeds) == size {
+			break
+		}
+		add(seed, false)
+	}
+
+	return plan
+}
+
+// refreshScores ranks the stored securities by age, boosted by their trading volume and popularity
+// relatively to the most traded and most viewed ones
+func refreshScores(candidates []models.RefreshCandidate, maxAge time.Duration) map[string]float64 {
+	maxVolume := 0.0
+	maxHits := 0.0
+	for _, candidate := range candidates {
+		maxVolume = math.Max(maxVolume, math.Log1p(float64(candidate.Volume)))
+		maxHits = math.Max(maxHits, math.Log1p(float64(candidate.Hits)))
+	}
+
+	scores := make(map[string]float64, len(candidates))
+	for _, candidate := range candidates {
+		staleness := 1.0
+		if maxAge > 0 {
+			staleness = candidate.Age.Seconds() / maxAge.Seconds()
+		}
+
+		boost := 1.0
+		if maxVolume > 0 {
+			boost += math.Log1p(float64(candidate.Volume)) / maxVolume
+		}
+		if maxHits > 0 {
+			boost += math.Log1p(float64(candidate.Hits)) / maxHits
+		}
+
+		scores[candidate.Seed] = staleness * boost
+	}
+
+	return scores
+}
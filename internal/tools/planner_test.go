@@ -0,0 +1,80 @@
+package tools
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Francesco99975/finexo/internal/models"
+)
+
+func TestPlanRefresh(t *testing.T) {
+	// Due before the next run past 18 hours of age
+	maxAge, interval := 24*time.Hour, 6*time.Hour
+
+	candidates := []models.RefreshCandidate{
+		{Seed: "MID", Age: 10 * time.Hour, Volume: 10},
+		{Seed: "DUE", Age: 20 * time.Hour, Volume: 10},
+		{Seed: "FRESH", Age: 2 * time.Hour, Volume: 1000000},
+		{Seed: "OLDEST", Age: 30 * time.Hour, Volume: 10},
+		{Seed: "TRADED", Age: 10 * time.Hour, Volume: 1000000},
+	}
+	seeds := []string{"OLDEST", "NEW1", "NEW2", "NEW3"}
+
+	tests := []struct {
+		name       string
+		candidates []models.RefreshCandidate
+		seeds      []string
+		size       int
+		plan       []string
+		due        int
+		known      int
+		discovery  int
+	}{
+		{name: "no budget", candidates: candidates, seeds: seeds, size: 0, plan: nil},
+		{name: "due first, oldest first", candidates: candidates, seeds: seeds, size: 1, plan: []string{"OLDEST"}, due: 2, known: 1},
+		{
+			name: "room kept for new seeds", candidates: candidates, seeds: seeds, size: 4,
+			plan: []string{"OLDEST", "DUE", "TRADED", "NEW1"}, due: 2, known: 3, discovery: 1,
+		},
+		{
+			name: "stored securities ranked by age and volume", candidates: candidates, size: 4,
+			plan: []string{"OLDEST", "DUE", "TRADED", "MID"}, due: 2, known: 4,
+		},
+		{
+			name: "new seeds take what the stored ones leave", candidates: candidates, seeds: seeds, size: 10,
+			plan: []string{"OLDEST", "DUE", "TRADED", "MID", "FRESH", "NEW1", "NEW2", "NEW3"}, due: 2, known: 5, discovery: 3,
+		},
+		{name: "nothing stored", seeds: seeds, size: 2, plan: []string{"OLDEST", "NEW1"}, discovery: 2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			plan := PlanRefresh(test.candidates, test.seeds, test.size, maxAge, interval)
+
+			if !slices.Equal(plan.Seeds, test.plan) {
+				t.Errorf("expected %v, got %v", test.plan, plan.Seeds)
+			}
+			expectEqual(t, "due", plan.Due, test.due)
+			expectEqual(t, "known", plan.Known, test.known)
+			expectEqual(t, "discovery", plan.Discovery, test.discovery)
+		})
+	}
+}
+
+func TestRefreshScoresBoostPopularity(t *testing.T) {
+	candidates := []models.RefreshCandidate{
+		{Seed: "QUIET", Age: 12 * time.Hour, Volume: 1000},
+		{Seed: "VIEWED", Age: 12 * time.Hour, Volume: 1000, Hits: 500},
+		{Seed: "STALE", Age: 23 * time.Hour, Volume: 1000},
+	}
+
+	scores := refreshScores(candidates, 24*time.Hour)
+
+	if scores["VIEWED"] <= scores["QUIET"] {
+		t.Errorf("expected the viewed security to rank above the quiet one, got %f and %f", scores["VIEWED"], scores["QUIET"])
+	}
+	if scores["STALE"] <= scores["QUIET"] {
+		t.Errorf("expected the staler security to rank above the quiet one, got %f and %f", scores["STALE"], scores["QUIET"])
+	}
+}
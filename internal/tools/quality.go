@@ -0,0 +1,69 @@
+package tools
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Francesco99975/finexo/internal/helpers"
+	"github.com/Francesco99975/finexo/internal/models"
+)
+
+// SelectorDriftThreshold is how much of its previous hit ratio a selector can lose before raising an alert
+var SelectorDriftThreshold = 0.5
+
+// MinSelectorLookups is the number of lookups a selector needs in both runs to be compared
+const MinSelectorLookups = 10
+
+// recordPage counts a page loaded for the target against the budget of its run
+func (t *ScrapeTarget) recordPage() {
+	if t != nil && t.Run != nil {
+		t.Run.RecordPage()
+	}
+}
+
+func (t *ScrapeTarget) recordSelector(field string, hit bool) {
+	helpers.RecordSelectorLookup(field, hit)
+
+	if t != nil && t.Run != nil {
+		t.Run.RecordSelector(field, hit)
+	}
+}
+
+// FinishScrapeRun closes the run and compares each selector with the previous run of the same suffix,
+// raising a drift alert for every selector whose hit ratio fell by more than SelectorDriftThreshold
+func FinishScrapeRun(run *models.ScrapeRun, previous *models.ScrapeRun) {
+	run.Finished = time.Now()
+
+	current := run.Snapshot()
+
+	selectors := make([]string, 0, len(current))
+	for selector := range current {
+		selectors = append(selectors, selector)
+	}
+	sort.Strings(selectors)
+
+	for _, selector := range selectors {
+		stats := current[selector]
+		helpers.RecordSelectorHitRatio(selector, run.Suffix, stats.Ratio())
+
+		if previous == nil {
+			continue
+		}
+
+		previousStats, ok := previous.Selectors[selector]
+		if !ok || previousStats.Lookups() < MinSelectorLookups || stats.Lookups() < MinSelectorLookups {
+			continue
+		}
+
+		if stats.Ratio() < previousStats.Ratio()*(1-SelectorDriftThreshold) {
+			run.Alerts = append(run.Alerts, models.DriftAlert{
+				Selector: selector,
+				Current:  stats.Ratio(),
+				Previous: previousStats.Ratio(),
+				Message:  fmt.Sprintf("%s matched %.0f%% of pages versus %.0f%% last run", selector, stats.Ratio()*100, previousStats.Ratio()*100),
+			})
+			helpers.RecordSelectorDrift(selector, run.Suffix)
+		}
+	}
+}
@@ -64,9 +64,9 @@ func UpdateJob(id string, schedule string, task func()) error {
 			ID:      id,
 			EntryID: entryID,
 		}
-		log.Infof("Updated job with ID: %d\n", id)
+		log.Infof("Updated job with ID: %s\n", id)
 	} else {
-		log.Errorf("Job with ID: %d not found\n", id)
+		log.Errorf("Job with ID: %s not found\n", id)
 	}
 
 	return nil
@@ -80,8 +80,8 @@ func RemoveJob(id string) {
 	if job, ok := jobs[id]; ok {
 		cronScheduler.Remove(job.EntryID)
 		delete(jobs, id)
-		log.Infof("Removed job with ID: %d\n", id)
+		log.Infof("Removed job with ID: %s\n", id)
 	} else {
-		log.Errorf("Job with ID: %d not found\n", id)
+		log.Errorf("Job with ID: %s not found\n", id)
 	}
 }
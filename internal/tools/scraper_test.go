@@ -0,0 +1,93 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Francesco99975/finexo/internal/models"
+)
+
+// fakeSource serves a fixed partial, or fails with err
+type fakeSource struct {
+	name    string
+	partial *models.PartialSecurity
+	err     error
+}
+
+func (s *fakeSource) Name() string {
+	return s.name
+}
+
+func (s *fakeSource) Fetch(ctx context.Context, target *ScrapeTarget) (*models.PartialSecurity, error) {
+	return s.partial, s.err
+}
+
+// withSources registers only the given sources for the duration of the test
+func withSources(t *testing.T, sources ...Source) {
+	t.Helper()
+
+	sourcesMutex.Lock()
+	registered, disabled := registeredSources, disabledSources
+	registeredSources, disabledSources = sources, make(map[string]bool)
+	sourcesMutex.Unlock()
+
+	t.Cleanup(func() {
+		sourcesMutex.Lock()
+		registeredSources, disabledSources = registered, disabled
+		sourcesMutex.Unlock()
+	})
+}
+
+// quote is a partial with every field a security needs to be merged
+func quote() *models.PartialSecurity {
+	partial := models.NewPartialSecurity(YAHOO_SOURCE)
+	partial.Typology = models.ValidString("STOCK")
+	partial.Currency = models.ValidString("USD")
+	partial.FullName = models.ValidString("Apple Inc.")
+	for _, field := range []*models.NullableInt{
+		&partial.Price, &partial.PC, &partial.PCP, &partial.YearLow, &partial.YearHigh,
+		&partial.DayLow, &partial.DayHigh, &partial.PClose, &partial.COpen,
+	} {
+		*field = models.ValidInt(100)
+	}
+	return partial
+}
+
+func TestCollectPostponesOnPausedHost(t *testing.T) {
+	until := time.Now().Add(time.Hour)
+	pause := &HostPauseError{Host: "www.marketbeat.com", Until: until, Reason: "rate limited", cause: ErrHostBlocked}
+
+	tests := []struct {
+		name   string
+		err    error
+		paused bool
+	}{
+		{name: "failed source", err: errors.New("page did not load")},
+		{name: "paused source", err: pause, paused: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			withSources(t,
+				&fakeSource{name: MARKETBEAT_SOURCE, err: test.err},
+				&fakeSource{name: YAHOO_SOURCE, partial: quote()},
+			)
+
+			target := &ScrapeTarget{Seed: "AAPL", Ticker: "AAPL", Exchange: &models.Exchange{Title: "NASDAQ"}}
+			merged, err := collect(context.Background(), target)
+
+			pausedUntil, paused := HostPausedUntil(err)
+			if paused != test.paused {
+				t.Fatalf("expected paused %v, got %v (%v)", test.paused, paused, err)
+			}
+			if paused && !pausedUntil.Equal(until) {
+				t.Errorf("expected the host paused until %v, got %v", until, pausedUntil)
+			}
+			if !paused && (err != nil || merged == nil) {
+				t.Errorf("expected the other sources to be merged, got %v", err)
+			}
+		})
+	}
+}
@@ -1,34 +1,258 @@
 package tools
 
-const MB_DATA_KEYS = ".price-data-area dt"
-const MB_DATA_VALUES = ".price-data-area strong"
-
-const YH_EXCHANGE_SELECTOR = "span.exchange span"
-const YH_DISCOVER_SEEDS_SELECTOR = ".carousel-top a.card-link"
-const YH_CURRENCY_SELECTOR = "span.exchange span:nth-child(3)"
-const YH_FULLNAME_SELECTOR = "section.container.paddingRight"
-const YH_REIT_HINT_SELECTOR = "h2.yf-1d08kze"
-const YH_ETF_HINT_SELECTOR = "h3.yf-1ja4ll8"
-const YH_PRICE_SELECTOR = "span[data-testid='qsp-price']"
-const YH_PCHANGE_SELECTOR = "span[data-testid='qsp-price-change']"
-const YH_PRICE_PERCENTAGE_CHANGE_SELECTOR = "span[data-testid='qsp-price-change-percent']"
-const YH_YEARLY_RANGE_SELECTOR = "[data-field='fiftyTwoWeekRange']"
-const YH_DAILY_RANGE_SELECTOR = "[data-field='regularMarketDayRange']"
-const YH_MARKET_CAP_SELECTOR = "[data-field='marketCap']"
-const YH_VOLUME_SELECTOR = "[data-field='regularMarketVolume']"
-const YH_AVG_VOLUME_SELECTOR = "[data-field='averageVolume']"
-const YH_BETA_SELECTOR = "span[title='Beta (5Y Monthly)'] ~ span"
-const YH_PCLOSE_SELECTOR = "[data-field='regularMarketPreviousClose']"
-const YH_COPEN_SELECTOR = "[data-field='regularMarketOpen']"
-const YH_TARGET_SELECTOR = "[data-field='targetMeanPrice']"
-const YH_BID_SELECTOR = "span[title='Bid'] ~ span"
-const YH_ASK_SELECTOR = "span[title='Ask'] ~ span"
-const YH_STOCK_DATA_SELECTOR = "[data-field='trailingPE']"
-const YH_AUM_SELECTOR = "span[title='Net Assets'] ~ span"
-const YH_ER_SELECTOR = "span[title='Expense Ratio (net)'] ~ span"
-const YH_NAV_SELECTOR = "span[title='NAV'] ~ span"
-const YH_ETF_DATA_SELECTOR = "section[data-testid='company-overview-card'] p[title]"
-const YH_HOLDINGS_TICKERS_SELECTOR = "section[data-testid='top-holdings'] a[data-testid='ticker-container']"
-const YH_HOLDING_ALLOCATIONS_SELECTOR = "section[data-testid='top-holdings'] span.data"
-const YH_YIELD_SELECTOR = "span[title='Yield'] ~ span"
-const YH_FWD_YIELD_SELECTOR = "span[title='Forward Dividend & Yield'] ~ span"
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Francesco99975/finexo/internal/helpers"
+	"github.com/labstack/gommon/log"
+	"gopkg.in/yaml.v2"
+)
+
+// Fields the scraper looks up in the pages of its sources, resolved to CSS selectors by the active selector set.
+// They are also the names the lookups are reported with in the scrape quality metrics.
+const (
+	MB_DATA_KEYS   = "MB_DATA_KEYS"
+	MB_DATA_VALUES = "MB_DATA_VALUES"
+
+	YH_EXCHANGE_SELECTOR                = "YH_EXCHANGE_SELECTOR"
+	YH_DISCOVER_SEEDS_SELECTOR          = "YH_DISCOVER_SEEDS_SELECTOR"
+	YH_CURRENCY_SELECTOR                = "YH_CURRENCY_SELECTOR"
+	YH_FULLNAME_SELECTOR                = "YH_FULLNAME_SELECTOR"
+	YH_REIT_HINT_SELECTOR               = "YH_REIT_HINT_SELECTOR"
+	YH_ETF_HINT_SELECTOR                = "YH_ETF_HINT_SELECTOR"
+	YH_PRICE_SELECTOR                   = "YH_PRICE_SELECTOR"
+	YH_PCHANGE_SELECTOR                 = "YH_PCHANGE_SELECTOR"
+	YH_PRICE_PERCENTAGE_CHANGE_SELECTOR = "YH_PRICE_PERCENTAGE_CHANGE_SELECTOR"
+	YH_YEARLY_RANGE_SELECTOR            = "YH_YEARLY_RANGE_SELECTOR"
+	YH_DAILY_RANGE_SELECTOR             = "YH_DAILY_RANGE_SELECTOR"
+	YH_MARKET_CAP_SELECTOR              = "YH_MARKET_CAP_SELECTOR"
+	YH_VOLUME_SELECTOR                  = "YH_VOLUME_SELECTOR"
+	YH_AVG_VOLUME_SELECTOR              = "YH_AVG_VOLUME_SELECTOR"
+	YH_BETA_SELECTOR                    = "YH_BETA_SELECTOR"
+	YH_PCLOSE_SELECTOR                  = "YH_PCLOSE_SELECTOR"
+	YH_COPEN_SELECTOR                   = "YH_COPEN_SELECTOR"
+	YH_TARGET_SELECTOR                  = "YH_TARGET_SELECTOR"
+	YH_BID_SELECTOR                     = "YH_BID_SELECTOR"
+	YH_ASK_SELECTOR                     = "YH_ASK_SELECTOR"
+	YH_STOCK_DATA_SELECTOR              = "YH_STOCK_DATA_SELECTOR"
+	YH_AUM_SELECTOR                     = "YH_AUM_SELECTOR"
+	YH_ER_SELECTOR                      = "YH_ER_SELECTOR"
+	YH_NAV_SELECTOR                     = "YH_NAV_SELECTOR"
+	YH_ETF_DATA_SELECTOR                = "YH_ETF_DATA_SELECTOR"
+	YH_HOLDINGS_TICKERS_SELECTOR        = "YH_HOLDINGS_TICKERS_SELECTOR"
+	YH_HOLDING_ALLOCATIONS_SELECTOR     = "YH_HOLDING_ALLOCATIONS_SELECTOR"
+	YH_YIELD_SELECTOR                   = "YH_YIELD_SELECTOR"
+	YH_FWD_YIELD_SELECTOR               = "YH_FWD_YIELD_SELECTOR"
+
+	DH_PARAGRAPHS_SELECTOR   = "DH_PARAGRAPHS_SELECTOR"
+	DH_PAYOUT_DATES_SELECTOR = "DH_PAYOUT_DATES_SELECTOR"
+	DH_TABLE_ROWS_SELECTOR   = "DH_TABLE_ROWS_SELECTOR"
+)
+
+// SelectorSet maps every field to the CSS selectors it is looked up with, tried in order until one matches
+type SelectorSet struct {
+	Version string              `json:"version" yaml:"version"`
+	Fields  map[string][]string `json:"fields" yaml:"fields"`
+}
+
+// DefaultSelectors is the selector set built in the scraper, active until a selector file is loaded
+var DefaultSelectors = SelectorSet{
+	Version: "builtin",
+	Fields: map[string][]string{
+		MB_DATA_KEYS:   {".price-data-area dt"},
+		MB_DATA_VALUES: {".price-data-area strong"},
+
+		YH_EXCHANGE_SELECTOR:                {"span.exchange span"},
+		YH_DISCOVER_SEEDS_SELECTOR:          {".carousel-top a.card-link"},
+		YH_CURRENCY_SELECTOR:                {"span.exchange span:nth-child(3)"},
+		YH_FULLNAME_SELECTOR:                {"section.container.paddingRight"},
+		YH_REIT_HINT_SELECTOR:               {"h2.yf-1d08kze"},
+		YH_ETF_HINT_SELECTOR:                {"h3.yf-1ja4ll8"},
+		YH_PRICE_SELECTOR:                   {"span[data-testid='qsp-price']"},
+		YH_PCHANGE_SELECTOR:                 {"span[data-testid='qsp-price-change']"},
+		YH_PRICE_PERCENTAGE_CHANGE_SELECTOR: {"span[data-testid='qsp-price-change-percent']"},
+		YH_YEARLY_RANGE_SELECTOR:            {"[data-field='fiftyTwoWeekRange']"},
+		YH_DAILY_RANGE_SELECTOR:             {"[data-field='regularMarketDayRange']"},
+		YH_MARKET_CAP_SELECTOR:              {"[data-field='marketCap']"},
+		YH_VOLUME_SELECTOR:                  {"[data-field='regularMarketVolume']"},
+		YH_AVG_VOLUME_SELECTOR:              {"[data-field='averageVolume']"},
+		YH_BETA_SELECTOR:                    {"span[title='Beta (5Y Monthly)'] ~ span"},
+		YH_PCLOSE_SELECTOR:                  {"[data-field='regularMarketPreviousClose']"},
+		YH_COPEN_SELECTOR:                   {"[data-field='regularMarketOpen']"},
+		YH_TARGET_SELECTOR:                  {"[data-field='targetMeanPrice']"},
+		YH_BID_SELECTOR:                     {"span[title='Bid'] ~ span"},
+		YH_ASK_SELECTOR:                     {"span[title='Ask'] ~ span"},
+		YH_STOCK_DATA_SELECTOR:              {"[data-field='trailingPE']"},
+		YH_AUM_SELECTOR:                     {"span[title='Net Assets'] ~ span"},
+		YH_ER_SELECTOR:                      {"span[title='Expense Ratio (net)'] ~ span"},
+		YH_NAV_SELECTOR:                     {"span[title='NAV'] ~ span"},
+		YH_ETF_DATA_SELECTOR:                {"section[data-testid='company-overview-card'] p[title]"},
+		YH_HOLDINGS_TICKERS_SELECTOR:        {"section[data-testid='top-holdings'] a[data-testid='ticker-container']"},
+		YH_HOLDING_ALLOCATIONS_SELECTOR:     {"section[data-testid='top-holdings'] span.data"},
+		YH_YIELD_SELECTOR:                   {"span[title='Yield'] ~ span"},
+		YH_FWD_YIELD_SELECTOR:               {"span[title='Forward Dividend & Yield'] ~ span"},
+
+		DH_PARAGRAPHS_SELECTOR:   {"p"},
+		DH_PAYOUT_DATES_SELECTOR: {"#dividend_table tr td:nth-child(2)"},
+		DH_TABLE_ROWS_SELECTOR:   {"table#dividend_table tr"},
+	},
+}
+
+var (
+	selectorsMutex    sync.RWMutex
+	activeSelectors   = DefaultSelectors
+	selectorsFile     string
+	selectorsModified time.Time
+)
+
+// LoadSelectorSet reads a selector file, YAML when its extension is .yaml or .yml and JSON otherwise,
+// and validates it
+func LoadSelectorSet(path string) (SelectorSet, error) {
+	payload, err := os.ReadFile(path)
+	if err != nil {
+		return SelectorSet{}, fmt.Errorf("failed to read selector file (%s): %v", path, err)
+	}
+
+	var set SelectorSet
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.UnmarshalStrict(payload, &set)
+	default:
+		decoder := json.NewDecoder(bytes.NewReader(payload))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&set)
+	}
+	if err != nil {
+		return SelectorSet{}, fmt.Errorf("failed to parse selector file (%s): %v", path, err)
+	}
+
+	err = set.Validate()
+	if err != nil {
+		return SelectorSet{}, fmt.Errorf("invalid selector file (%s): %v", path, err)
+	}
+
+	return set, nil
+}
+
+// Validate checks the set has a version and selectors for every field the scraper looks up, and for no other
+func (s SelectorSet) Validate() error {
+	if strings.TrimSpace(s.Version) == "" {
+		return fmt.Errorf("missing version")
+	}
+
+	var problems []string
+	for field := range DefaultSelectors.Fields {
+		selectors := s.Fields[field]
+		if len(selectors) == 0 {
+			problems = append(problems, fmt.Sprintf("no selectors for %s", field))
+		}
+		for _, selector := range selectors {
+			if strings.TrimSpace(selector) == "" {
+				problems = append(problems, fmt.Sprintf("empty selector for %s", field))
+			}
+		}
+	}
+	for field := range s.Fields {
+		if _, ok := DefaultSelectors.Fields[field]; !ok {
+			problems = append(problems, fmt.Sprintf("unknown field %s", field))
+		}
+	}
+
+	if len(problems) > 0 {
+		slices.Sort(problems)
+		return fmt.Errorf("%s", strings.Join(problems, ", "))
+	}
+
+	return nil
+}
+
+// ActivateSelectors makes the scraper look fields up with the set from now on
+func ActivateSelectors(set SelectorSet) {
+	selectorsMutex.Lock()
+	defer selectorsMutex.Unlock()
+
+	activeSelectors = set
+	helpers.RecordSelectorsVersion(set.Version)
+}
+
+// ActiveSelectors returns the selector set the scraper looks fields up with
+func ActiveSelectors() SelectorSet {
+	selectorsMutex.RLock()
+	defer selectorsMutex.RUnlock()
+
+	return activeSelectors
+}
+
+// selectorsFor returns the selectors of a field in the order they are to be tried
+func selectorsFor(field string) []string {
+	selectorsMutex.RLock()
+	defer selectorsMutex.RUnlock()
+
+	return activeSelectors.Fields[field]
+}
+
+// WatchSelectorFile activates the selectors of the file, which ReloadSelectors loads again once it changes
+func WatchSelectorFile(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to read selector file (%s): %w", path, err)
+	}
+
+	set, err := LoadSelectorSet(path)
+	if err != nil {
+		return err
+	}
+
+	selectorsMutex.Lock()
+	selectorsFile = path
+	selectorsModified = info.ModTime()
+	selectorsMutex.Unlock()
+
+	ActivateSelectors(set)
+	log.Infof("Selectors %s loaded from %s", set.Version, path)
+
+	return nil
+}
+
+// ReloadSelectors activates the watched selector file again when it changed since it was loaded.
+// A file that fails to load is reported and the selectors active so far are kept.
+func ReloadSelectors() {
+	selectorsMutex.RLock()
+	path := selectorsFile
+	modified := selectorsModified
+	selectorsMutex.RUnlock()
+
+	if path == "" {
+		return
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		log.Errorf("failed to check selector file (%s): %v", path, err)
+		return
+	}
+	if info.ModTime().Equal(modified) {
+		return
+	}
+
+	selectorsMutex.Lock()
+	selectorsModified = info.ModTime()
+	selectorsMutex.Unlock()
+
+	set, err := LoadSelectorSet(path)
+	if err != nil {
+		log.Errorf("Keeping selectors %s: %v", ActiveSelectors().Version, err)
+		return
+	}
+
+	ActivateSelectors(set)
+	log.Infof("Selectors %s reloaded from %s", set.Version, path)
+}
@@ -0,0 +1,72 @@
+package tools
+
+import (
+	"maps"
+	"testing"
+)
+
+const selectorFile = "../../selectors.yaml"
+
+// withSelectors activates the set for the rest of the test, going back to the built-in selectors after it
+func withSelectors(t *testing.T, set SelectorSet) {
+	t.Helper()
+
+	err := set.Validate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ActivateSelectors(set)
+	t.Cleanup(func() { ActivateSelectors(DefaultSelectors) })
+}
+
+// selectorFailures replays the fixtures with the active selectors, returning what differs from the golden files
+func selectorFailures(t *testing.T) []string {
+	t.Helper()
+
+	var failures []string
+	for name, merged := range replayFixtures(t, fixturesDir) {
+		diffs, err := VerifyGolden(fixturesDir, name, merged)
+		if err != nil {
+			failures = append(failures, name+": "+err.Error())
+			continue
+		}
+		for _, diff := range diffs {
+			failures = append(failures, name+": "+diff)
+		}
+	}
+
+	return failures
+}
+
+func TestSelectorFilePassesFixtures(t *testing.T) {
+	set, err := LoadSelectorSet(selectorFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	withSelectors(t, set)
+
+	for _, failure := range selectorFailures(t) {
+		t.Error(failure)
+	}
+}
+
+func TestSelectorFallbacksPassFixtures(t *testing.T) {
+	set := SelectorSet{Version: "fallback", Fields: maps.Clone(DefaultSelectors.Fields)}
+	set.Fields[YH_PRICE_SELECTOR] = append([]string{"span[data-testid='qsp-price-renamed']"}, DefaultSelectors.Fields[YH_PRICE_SELECTOR]...)
+	withSelectors(t, set)
+
+	for _, failure := range selectorFailures(t) {
+		t.Error(failure)
+	}
+}
+
+func TestBrokenSelectorFailsFixtures(t *testing.T) {
+	set := SelectorSet{Version: "broken", Fields: maps.Clone(DefaultSelectors.Fields)}
+	set.Fields[YH_FWD_YIELD_SELECTOR] = []string{"span[title='Forward Yield'] ~ span"}
+	withSelectors(t, set)
+
+	if len(selectorFailures(t)) == 0 {
+		t.Error("expected the fixtures to catch the broken selector")
+	}
+}
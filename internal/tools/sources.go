@@ -0,0 +1,191 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Francesco99975/finexo/internal/helpers"
+	"github.com/Francesco99975/finexo/internal/models"
+	"github.com/go-rod/rod"
+)
+
+const (
+	MARKETBEAT_SOURCE      = "marketbeat"
+	DIVIDENDHISTORY_SOURCE = "dividendhistory"
+	YAHOO_SOURCE           = "yahoo"
+)
+
+// ScrapeTarget describes the security being scraped and the page every source navigates with
+type ScrapeTarget struct {
+	Seed       string
+	Ticker     string
+	Exchange   *models.Exchange
+	Page       *rod.Page
+	Discoverer *Discoverer
+	// Now is the reference time of the scrape, replayed fixtures use the time they were recorded at
+	Now time.Time
+	// Run collects the selector hits and misses of the SeedDatabase run the scrape belongs to, if any
+	Run *models.ScrapeRun
+}
+
+func (t *ScrapeTarget) String() string {
+	return t.Ticker + ":" + t.Exchange.Title
+}
+
+func (t *ScrapeTarget) now() time.Time {
+	if t.Now.IsZero() {
+		return time.Now()
+	}
+	return t.Now
+}
+
+// Source is a single data provider. Fetch only reports what the provider knows about the target,
+// combining the partial results into a security is the merger's job.
+type Source interface {
+	Name() string
+	Fetch(ctx context.Context, target *ScrapeTarget) (*models.PartialSecurity, error)
+}
+
+var sourcesMutex sync.RWMutex
+var registeredSources = []Source{
+	&MarketBeatSource{},
+	&DividendHistorySource{},
+	&YahooSource{},
+}
+var disabledSources = make(map[string]bool)
+
+// RegisterSource adds a provider to the scraping pipeline, replacing any provider with the same name
+func RegisterSource(source Source) {
+	sourcesMutex.Lock()
+	defer sourcesMutex.Unlock()
+
+	for i, registered := range registeredSources {
+		if registered.Name() == source.Name() {
+			registeredSources[i] = source
+			return
+		}
+	}
+
+	registeredSources = append(registeredSources, source)
+}
+
+// DisableSource removes a provider from the pipeline without unregistering it
+func DisableSource(name string) error {
+	sourcesMutex.Lock()
+	defer sourcesMutex.Unlock()
+
+	for _, registered := range registeredSources {
+		if registered.Name() == name {
+			disabledSources[name] = true
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown source: %s", name)
+}
+
+func EnableSource(name string) {
+	sourcesMutex.Lock()
+	defer sourcesMutex.Unlock()
+
+	delete(disabledSources, name)
+}
+
+// EnabledSources returns the active providers in the order they should be fetched
+func EnabledSources() []Source {
+	sourcesMutex.RLock()
+	defer sourcesMutex.RUnlock()
+
+	return helpers.FilteredSlice(registeredSources, func(source Source) bool {
+		return !disabledSources[source.Name()]
+	})
+}
+
+// navigate opens the url on the page and waits for it to load, at the pace the politeness policy sets for its host.
+// A host answering with a rate limit, a denial or a captcha page is paused for its cool-down.
+func navigate(page *rod.Page, url string) error {
+	limiter := limiterFor(url)
+	if limiter != nil {
+		err := limiter.wait(page.GetContext())
+		if err != nil {
+			return err
+		}
+	}
+
+	err := loadPage(page, url)
+	if err != nil {
+		if limiter != nil {
+			helpers.RecordHostRequest(limiter.host, "error")
+		}
+		return err
+	}
+
+	if limiter != nil {
+		if reason := detectBlock(page); reason != "" {
+			return limiter.block(reason)
+		}
+		limiter.succeed()
+	}
+
+	return nil
+}
+
+// loadPage opens the url on the page and waits for it to load
+func loadPage(page *rod.Page, url string) error {
+	err := page.Navigate(url)
+	if err != nil {
+		return err
+	}
+
+	return page.Timeout(20 * time.Second).WaitLoad()
+}
+
+// findElement returns the first element matching the selectors of the field, trying them in order
+func findElement(target *ScrapeTarget, page *rod.Page, field string) (*rod.Element, error) {
+	err := fmt.Errorf("no selectors for %s", field)
+	for _, selector := range selectorsFor(field) {
+		var elem *rod.Element
+		elem, err = page.Timeout(5 * time.Second).Element(selector)
+		if err == nil {
+			target.recordSelector(field, true)
+			return elem, nil
+		}
+	}
+
+	target.recordSelector(field, false)
+	return nil, err
+}
+
+// findElements returns the elements matching the first selector of the field that matches any
+func findElements(target *ScrapeTarget, page *rod.Page, field string) (rod.Elements, error) {
+	var elems rod.Elements
+	err := fmt.Errorf("no selectors for %s", field)
+	for _, selector := range selectorsFor(field) {
+		elems, err = page.Timeout(5 * time.Second).Elements(selector)
+		if err == nil && len(elems) > 0 {
+			target.recordSelector(field, true)
+			return elems, nil
+		}
+	}
+
+	target.recordSelector(field, false)
+	return elems, err
+}
+
+// findText returns the visible text of the first element matching the selectors of the field
+func findText(target *ScrapeTarget, page *rod.Page, field string) (string, error) {
+	elem, err := findElement(target, page, field)
+	if err != nil {
+		return "", err
+	}
+
+	return elem.Text()
+}
+
+// parseCents parses a scraped decimal like "1,234.56" into its integer representation (123456)
+func parseCents(value string) (int, error) {
+	return strconv.Atoi(helpers.NormalizeFloatStrToIntStr(value))
+}
@@ -0,0 +1,232 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Francesco99975/finexo/internal/helpers"
+	"github.com/go-rod/rod"
+)
+
+// ErrHostCoolingDown is returned for a page of a host paused after it blocked the scraper
+var ErrHostCoolingDown = errors.New("host is cooling down")
+
+// ErrHostBlocked is returned when a host answers with a rate limit, a denial or a captcha page
+var ErrHostBlocked = errors.New("host blocked the scraper")
+
+// HostPauseError is returned for a page not loaded because its host is paused, telling until when.
+// It matches ErrHostCoolingDown or ErrHostBlocked.
+type HostPauseError struct {
+	Host   string
+	Until  time.Time
+	Reason string
+	cause  error
+}
+
+func (e *HostPauseError) Error() string {
+	return fmt.Sprintf("%v: %s %s, paused until %s", e.cause, e.Host, e.Reason, e.Until.Format(time.RFC3339))
+}
+
+func (e *HostPauseError) Unwrap() error {
+	return e.cause
+}
+
+// HostPausedUntil returns until when the host of a page that failed with err is paused, if it failed for that
+func HostPausedUntil(err error) (time.Time, bool) {
+	var pause *HostPauseError
+	if errors.As(err, &pause) {
+		return pause.Until, true
+	}
+	return time.Time{}, false
+}
+
+// A host blocking the scraper again right after a cool-down is paused for twice as long, up to this many times the cool-down
+const maxCoolDownFactor = 8
+
+// Markers of the captcha and bot challenge pages, looked for in the title and the elements of a loaded page
+var captchaTitles = []string{"captcha", "are you a robot", "unusual traffic", "just a moment", "access denied", "verify you are human"}
+
+const captchaSelector = `iframe[src*="captcha"], .g-recaptcha, .h-captcha, #captcha, #challenge-form, #px-captcha`
+
+// HostPolicy is how politely a single host is scraped
+type HostPolicy struct {
+	RequestsPerMinute float64       // Pages loaded per minute on average
+	Jitter            time.Duration // Random delay added before every page, up to this long
+	CoolDown          time.Duration // Pause of the host after it blocked the scraper
+}
+
+// Politeness is the policy of every host, hosts missing from Hosts getting the default one
+type Politeness struct {
+	Default HostPolicy
+	// Hosts overrides the requests per minute by host, a host matching its subdomains too (yahoo.com for finance.yahoo.com)
+	Hosts map[string]float64
+}
+
+func (p Politeness) policy(host string) HostPolicy {
+	policy := p.Default
+
+	match := ""
+	for name, rpm := range p.Hosts {
+		if (host == name || strings.HasSuffix(host, "."+name)) && len(name) > len(match) {
+			match = name
+			policy.RequestsPerMinute = rpm
+		}
+	}
+
+	return policy
+}
+
+var DefaultPoliteness = Politeness{
+	Default: HostPolicy{
+		RequestsPerMinute: 30,
+		Jitter:            2 * time.Second,
+		CoolDown:          10 * time.Minute,
+	},
+}
+
+// hostLimiter is a token bucket holding a single token, so that the pages of a host are spread evenly
+type hostLimiter struct {
+	lock        sync.Mutex
+	host        string
+	policy      HostPolicy
+	next        time.Time // When the next page may be loaded
+	pausedUntil time.Time
+	strikes     int // Blocks in a row, doubling the cool-down
+}
+
+var (
+	limitersMutex sync.Mutex
+	limiters      = make(map[string]*hostLimiter)
+	politeness    = DefaultPoliteness
+)
+
+// SetPoliteness replaces the policy of every host, the hosts scraped so far starting over
+func SetPoliteness(p Politeness) {
+	limitersMutex.Lock()
+	defer limitersMutex.Unlock()
+
+	politeness = p
+	limiters = make(map[string]*hostLimiter)
+}
+
+// limiterFor returns the limiter of the host of the url, nil for pages that are not fetched over the network (fixtures)
+func limiterFor(rawURL string) *hostLimiter {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return nil
+	}
+	host := strings.ToLower(parsed.Hostname())
+
+	limitersMutex.Lock()
+	defer limitersMutex.Unlock()
+
+	limiter, ok := limiters[host]
+	if !ok {
+		limiter = &hostLimiter{host: host, policy: politeness.policy(host)}
+		limiters[host] = limiter
+	}
+
+	return limiter
+}
+
+// wait blocks until the host may be loaded again, failing at once when it is cooling down
+func (l *hostLimiter) wait(ctx context.Context) error {
+	l.lock.Lock()
+	now := time.Now()
+	if now.Before(l.pausedUntil) {
+		until := l.pausedUntil
+		l.lock.Unlock()
+		helpers.RecordHostRequest(l.host, "cooling")
+		return &HostPauseError{Host: l.host, Until: until, Reason: "cooling down", cause: ErrHostCoolingDown}
+	}
+
+	// Pages are booked in turn, each one waiting for the one before it
+	start := now
+	if l.next.After(start) {
+		start = l.next
+	}
+	if l.policy.RequestsPerMinute > 0 {
+		l.next = start.Add(time.Duration(float64(time.Minute) / l.policy.RequestsPerMinute))
+	}
+	l.lock.Unlock()
+
+	delay := start.Sub(now)
+	if l.policy.Jitter > 0 {
+		delay += rand.N(l.policy.Jitter)
+	}
+	helpers.RecordHostWait(l.host, delay)
+
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// block pauses the host for its cool-down, longer for every block in a row
+func (l *hostLimiter) block(reason string) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.strikes++
+	factor := math.Min(math.Pow(2, float64(l.strikes-1)), maxCoolDownFactor)
+	l.pausedUntil = time.Now().Add(time.Duration(float64(l.policy.CoolDown) * factor))
+
+	helpers.RecordHostRequest(l.host, "blocked")
+	helpers.RecordHostPause(l.host, l.pausedUntil)
+
+	return &HostPauseError{Host: l.host, Until: l.pausedUntil, Reason: reason, cause: ErrHostBlocked}
+}
+
+func (l *hostLimiter) succeed() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.strikes = 0
+	helpers.RecordHostRequest(l.host, "ok")
+}
+
+// detectBlock looks for a rate limit or denial status and for a captcha page, returning why the page is blocked or ""
+func detectBlock(page *rod.Page) string {
+	status, err := page.Eval(`() => {
+		const navigation = performance.getEntriesByType("navigation")[0];
+		return navigation && navigation.responseStatus ? navigation.responseStatus : 0;
+	}`)
+	if err == nil {
+		switch code := status.Value.Int(); code {
+		case 429, 403:
+			return fmt.Sprintf("answered %d", code)
+		}
+	}
+
+	info, err := page.Info()
+	if err == nil {
+		title := strings.ToLower(info.Title)
+		for _, marker := range captchaTitles {
+			if strings.Contains(title, marker) {
+				return fmt.Sprintf("served a captcha page (%s)", info.Title)
+			}
+		}
+	}
+
+	if found, _, err := page.Has(captchaSelector); err == nil && found {
+		return "served a captcha page"
+	}
+
+	return ""
+}
@@ -0,0 +1,157 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPolitenessPolicy(t *testing.T) {
+	p := Politeness{
+		Default: HostPolicy{RequestsPerMinute: 30, CoolDown: time.Minute},
+		Hosts:   map[string]float64{"yahoo.com": 10, "finance.yahoo.com": 5, "marketbeat.com": 20},
+	}
+
+	tests := []struct {
+		host string
+		rpm  float64
+	}{
+		{host: "www.marketbeat.com", rpm: 20},
+		{host: "marketbeat.com", rpm: 20},
+		{host: "finance.yahoo.com", rpm: 5},
+		{host: "uk.yahoo.com", rpm: 10},
+		{host: "notyahoo.com", rpm: 30},
+		{host: "dividendhistory.org", rpm: 30},
+	}
+
+	for _, test := range tests {
+		t.Run(test.host, func(t *testing.T) {
+			policy := p.policy(test.host)
+			expectEqual(t, "requests per minute", policy.RequestsPerMinute, test.rpm)
+			expectEqual(t, "cool-down", policy.CoolDown, time.Minute)
+		})
+	}
+}
+
+func TestLimiterFor(t *testing.T) {
+	SetPoliteness(DefaultPoliteness)
+	t.Cleanup(func() { SetPoliteness(DefaultPoliteness) })
+
+	if limiter := limiterFor("file:///fixtures/AAPL/yahoo.html"); limiter != nil {
+		t.Errorf("expected no limiter for a local page, got %s", limiter.host)
+	}
+
+	first := limiterFor("https://www.MarketBeat.com/stocks/NASDAQ/AAPL/")
+	second := limiterFor("https://www.marketbeat.com/stocks/NYSE/KO/")
+	if first == nil || first != second {
+		t.Error("expected the pages of a host to share its limiter")
+	}
+	expectEqual(t, "host", first.host, "www.marketbeat.com")
+}
+
+func TestHostLimiterSpacesPages(t *testing.T) {
+	interval := 100 * time.Millisecond
+	limiter := &hostLimiter{host: "example.com", policy: HostPolicy{RequestsPerMinute: float64(time.Minute / interval)}}
+
+	start := time.Now()
+	for range 3 {
+		if err := limiter.wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The first page goes at once, the next ones an interval apart
+	if elapsed := time.Since(start); elapsed < 2*interval-10*time.Millisecond || elapsed > 3*interval {
+		t.Errorf("expected three pages to take two intervals, took %v", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := limiter.wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected a canceled wait to give up, got %v", err)
+	}
+}
+
+func TestHostLimiterCoolDown(t *testing.T) {
+	coolDown := time.Minute
+	limiter := &hostLimiter{host: "example.com", policy: HostPolicy{CoolDown: coolDown}}
+
+	for strike, factor := range []time.Duration{1, 2, 4, 8, 8} {
+		before := time.Now()
+		err := limiter.block("answered 429")
+
+		until, paused := HostPausedUntil(err)
+		if !paused || !errors.Is(err, ErrHostBlocked) {
+			t.Fatalf("expected the block to pause the host, got %v", err)
+		}
+		if pause := until.Sub(before); pause < factor*coolDown || pause > factor*coolDown+time.Second {
+			t.Errorf("strike %d: expected a pause of %v, got %v", strike+1, factor*coolDown, pause)
+		}
+	}
+
+	err := limiter.wait(context.Background())
+	if _, paused := HostPausedUntil(err); !paused || !errors.Is(err, ErrHostCoolingDown) {
+		t.Errorf("expected the paused host to be refused at once, got %v", err)
+	}
+
+	limiter.succeed()
+	limiter.pausedUntil = time.Time{}
+	before := time.Now()
+	until, _ := HostPausedUntil(limiter.block("answered 403"))
+	if pause := until.Sub(before); pause > coolDown+time.Second {
+		t.Errorf("expected a success to reset the cool-down, got a pause of %v", pause)
+	}
+}
+
+func TestDetectBlock(t *testing.T) {
+	browser := replayBrowser(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<html><head><title>Apple Inc. (AAPL)</title></head><body><p>Quote</p></body></html>`)
+	})
+	mux.HandleFunc("/limited", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprint(w, `<html><head><title>Slow down</title></head><body></body></html>`)
+	})
+	mux.HandleFunc("/denied", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `<html><head><title>Forbidden</title></head><body></body></html>`)
+	})
+	mux.HandleFunc("/title", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<html><head><title>Just a moment...</title></head><body></body></html>`)
+	})
+	mux.HandleFunc("/widget", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<html><head><title>Quote</title></head><body><div class="g-recaptcha"></div></body></html>`)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	tests := []struct {
+		path   string
+		reason string
+	}{
+		{path: "/ok", reason: ""},
+		{path: "/limited", reason: "answered 429"},
+		{path: "/denied", reason: "answered 403"},
+		{path: "/title", reason: "served a captcha page (Just a moment...)"},
+		{path: "/widget", reason: "served a captcha page"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.path[1:], func(t *testing.T) {
+			page := browser.MustPage().Timeout(10 * time.Second)
+			t.Cleanup(func() { _ = page.Close() })
+
+			if err := loadPage(page, server.URL+test.path); err != nil {
+				t.Fatal(err)
+			}
+
+			expectEqual(t, "reason", detectBlock(page), test.reason)
+		})
+	}
+}
@@ -0,0 +1,741 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Francesco99975/finexo/internal/helpers"
+	"github.com/Francesco99975/finexo/internal/models"
+	"github.com/go-rod/rod"
+	"github.com/labstack/gommon/log"
+)
+
+// YahooSource scrapes quotes, ranges and dividend yield from Yahoo Finance, plus fund data for ETFs.
+// It is the only provider that knows the typology of a security, so missing quote data is an error.
+type YahooSource struct{}
+
+func (s *YahooSource) Name() string {
+	return YAHOO_SOURCE
+}
+
+func (s *YahooSource) URL(target *ScrapeTarget) string {
+	if target.Exchange.Suffix.Valid {
+		return BASE_YAHOO_URL + fmt.Sprintf("%s.%s", target.Ticker, target.Exchange.Suffix.String)
+	}
+
+	return BASE_YAHOO_URL + target.Ticker
+}
+
+func (s *YahooSource) Fetch(ctx context.Context, target *ScrapeTarget) (*models.PartialSecurity, error) {
+	partial := models.NewPartialSecurity(s.Name())
+	page := target.Page.Context(ctx)
+
+	if err := openSource(page, target, s.Name(), s.URL(target)); err != nil {
+		return nil, fmt.Errorf("failed to open page on Yahoo: %w. For seed %s", err, target.Seed)
+	}
+
+	if target.Discoverer != nil {
+		scrapedDiscoveredSeeds, err := findElements(target, page, YH_DISCOVER_SEEDS_SELECTOR)
+		if err != nil {
+			log.Warnf("failed to scrape discovered seeds: %v. For seed %s", err, target.Seed)
+		}
+
+		log.Debugf("Scraping Yahoo reccomanded seeds for %s -- Found %d seeds", target, len(scrapedDiscoveredSeeds))
+
+		for _, discoveredSeed := range scrapedDiscoveredSeeds {
+			seed, err := discoveredSeed.Attribute("title")
+			if err != nil || seed == nil {
+				log.Warnf("failed to scrape discovered seed url: %v. For seed %s", err, target.Seed)
+				continue
+			}
+
+			err = target.Discoverer.Collect(*seed)
+			if err != nil {
+				log.Warnf("failed to collect discovered seed: %v. For seed %s", err, *seed)
+			}
+		}
+		log.Debugf("Collected Yahoo reccomanded seeds for %s", target)
+	}
+
+	payload, err := readYahooPayload(page)
+	if err != nil {
+		log.Warnf("embedded data not found, scraping the page text: %v - target: %s", err, target)
+	}
+
+	r := &yahooReader{page: page, target: target, payload: payload, partial: partial}
+
+	partial.Currency, err = r.requiredText("currency", YH_CURRENCY_SELECTOR, "currency")
+	if err != nil {
+		return nil, err
+	}
+	log.Debugf("Scraped currency: %s", partial.Currency.String)
+
+	partial.FullName, err = r.requiredText("fullName", YH_FULLNAME_SELECTOR, "full name")
+	if err != nil {
+		return nil, err
+	}
+	log.Debug("Scraped full name")
+
+	partial.Typology, err = r.typology()
+	if err != nil {
+		return nil, err
+	}
+	log.Debugf("Scraped typology: %s", partial.Typology.String)
+
+	partial.Price, err = r.requiredCents("price", YH_PRICE_SELECTOR, "price", true)
+	if err != nil {
+		return nil, err
+	}
+
+	partial.PC, err = r.requiredCents("pc", YH_PCHANGE_SELECTOR, "price change", false)
+	if err != nil {
+		return nil, err
+	}
+
+	partial.PCP, err = r.requiredCents("pcp", YH_PRICE_PERCENTAGE_CHANGE_SELECTOR, "price change percentage", false)
+	if err != nil {
+		return nil, err
+	}
+
+	partial.YearLow, partial.YearHigh, err = r.requiredRange("yearLow", "yearHigh", YH_YEARLY_RANGE_SELECTOR, "yearly range")
+	if err != nil {
+		return nil, err
+	}
+
+	partial.DayLow, partial.DayHigh, err = r.requiredRange("dayLow", "dayHigh", YH_DAILY_RANGE_SELECTOR, "daily range")
+	if err != nil {
+		return nil, err
+	}
+
+	partial.MarketCap = r.optional("marketCap", payload.Count("marketCap"), func() models.NullableInt {
+		return optionalNumber(page, YH_MARKET_CAP_SELECTOR, "market cap", target)
+	})
+	partial.Volume = r.optional("volume", payload.Count("volume"), func() models.NullableInt {
+		return optionalCount(page, YH_VOLUME_SELECTOR, "volume", target)
+	})
+	partial.AvgVolume = r.optional("avgVolume", payload.Count("avgVolume"), func() models.NullableInt {
+		return optionalCount(page, YH_AVG_VOLUME_SELECTOR, "average volume", target)
+	})
+	partial.Beta = r.optional("beta", payload.Cents("beta"), func() models.NullableInt {
+		return optionalCents(page, YH_BETA_SELECTOR, "beta", false, target)
+	})
+
+	partial.PClose, err = r.requiredCents("previousClose", YH_PCLOSE_SELECTOR, "previous close", true)
+	if err != nil {
+		return nil, err
+	}
+
+	partial.Target = r.optional("target", positiveInt(payload.Cents("target")), func() models.NullableInt {
+		return optionalCents(page, YH_TARGET_SELECTOR, "target", true, target)
+	})
+
+	partial.COpen, err = r.requiredCents("currentOpen", YH_COPEN_SELECTOR, "open", true)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only the embedded data tells which session the quote belongs to
+	partial.MarketDate = payload.MarketDate()
+	r.found("marketDate", partial.MarketDate.Valid)
+
+	partial.Bid, partial.BidSize, err = r.requiredQuote("bid", "bidSize", YH_BID_SELECTOR)
+	if err != nil {
+		return nil, err
+	}
+
+	partial.Ask, partial.AskSize, err = r.requiredQuote("ask", "askSize", YH_ASK_SELECTOR)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.stockData()
+	if err != nil {
+		return nil, err
+	}
+
+	partial.STM = models.ValidString(string(models.TimingTTM))
+
+	r.yield()
+	log.Debug("Scraped dividend")
+
+	if partial.Typology.String == "ETF" {
+		r.fund()
+	}
+
+	return partial, nil
+}
+
+// yahooReader reads every value of a Yahoo page from the data embedded in the page, scraping the text of the
+// page only for the values the embedded data misses, and tags each value with the method that produced it
+type yahooReader struct {
+	page    *rod.Page
+	target  *ScrapeTarget
+	payload *YahooPayload
+	partial *models.PartialSecurity
+}
+
+func (r *yahooReader) extracted(field string, method models.Extraction) {
+	r.partial.Extracted(field, method)
+	helpers.RecordExtraction(YAHOO_SOURCE, field, string(method))
+}
+
+// found tags the field as read from the embedded data when the value is there
+func (r *yahooReader) found(field string, valid bool) bool {
+	if valid {
+		r.extracted(field, models.ExtractionJSON)
+	}
+	return valid
+}
+
+// requiredText reads a mandatory text value, failing the whole scrape when it is missing
+func (r *yahooReader) requiredText(field string, selector string, label string) (models.NullableString, error) {
+	if value := r.payload.String(field); r.found(field, value.Valid) {
+		return value, nil
+	}
+
+	scraped, err := findText(r.target, r.page, selector)
+	if err != nil {
+		return models.NullableString{Valid: false}, fmt.Errorf("%s not found in page - target: %s", label, r.target)
+	}
+
+	scraped = strings.TrimSpace(scraped)
+	if isAnEmptyString(scraped) {
+		return models.NullableString{Valid: false}, fmt.Errorf("empty %s: %s - target: %s", label, scraped, r.target)
+	}
+	r.extracted(field, models.ExtractionDOM)
+
+	return models.ValidString(scraped), nil
+}
+
+// typology reads the quote type of the embedded data, or the hints of the page telling REITs and funds apart
+func (r *yahooReader) typology() (models.NullableString, error) {
+	if typology := r.payload.Typology(); r.found("typology", typology.Valid) {
+		return typology, nil
+	}
+
+	reitHint, err := findText(r.target, r.page, YH_REIT_HINT_SELECTOR)
+	if err != nil {
+		return models.NullableString{Valid: false}, fmt.Errorf("typology hint not found in page - target: %s", r.target)
+	}
+
+	etfHint, err := findText(r.target, r.page, YH_ETF_HINT_SELECTOR)
+	if err != nil {
+		return models.NullableString{Valid: false}, fmt.Errorf("typology ETF hint not found in page - target: %s", r.target)
+	}
+
+	typology := "STOCK"
+	if strings.Contains(strings.ToLower(reitHint), "reit") {
+		typology = "REIT"
+	}
+
+	if strings.Contains(strings.ToLower(etfHint), "fund family") {
+		typology = "ETF"
+	}
+	r.extracted("typology", models.ExtractionDOM)
+
+	return models.ValidString(typology), nil
+}
+
+// requiredCents reads a mandatory decimal value, failing the whole scrape when it is missing
+func (r *yahooReader) requiredCents(field string, selector string, label string, positive bool) (models.NullableInt, error) {
+	if value := r.payload.Cents(field); r.found(field, value.Valid && (!positive || value.Int64 > 0)) {
+		return value, nil
+	}
+
+	scraped, err := requiredCents(r.page, selector, label, positive, r.target)
+	if err != nil {
+		return models.NullableInt{Valid: false}, err
+	}
+	r.extracted(field, models.ExtractionDOM)
+
+	return models.ValidInt(int64(scraped)), nil
+}
+
+// requiredRange reads a mandatory range, both bounds coming from the same method
+func (r *yahooReader) requiredRange(lowField string, highField string, selector string, label string) (models.NullableInt, models.NullableInt, error) {
+	low, high := r.payload.Cents(lowField), r.payload.Cents(highField)
+	if low.Valid && high.Valid && low.Int64 > 0 && high.Int64 >= low.Int64 {
+		r.extracted(lowField, models.ExtractionJSON)
+		r.extracted(highField, models.ExtractionJSON)
+		return low, high, nil
+	}
+
+	scrapedLow, scrapedHigh, err := requiredRange(r.page, selector, label, r.target)
+	if err != nil {
+		return models.NullableInt{Valid: false}, models.NullableInt{Valid: false}, err
+	}
+	r.extracted(lowField, models.ExtractionDOM)
+	r.extracted(highField, models.ExtractionDOM)
+
+	return models.ValidInt(int64(scrapedLow)), models.ValidInt(int64(scrapedHigh)), nil
+}
+
+// requiredQuote reads a mandatory quote and its size. Yahoo sends a zero quote out of market hours,
+// which is left to the text of the page.
+func (r *yahooReader) requiredQuote(field string, sizeField string, selector string) (models.NullableInt, models.NullableInt, error) {
+	if quote := r.payload.Cents(field); r.found(field, quote.Valid && quote.Int64 > 0) {
+		size := r.payload.Count(sizeField)
+		if size.Valid && size.Int64 < 0 {
+			size = models.NullableInt{Valid: false}
+		}
+		r.found(sizeField, size.Valid)
+		return quote, size, nil
+	}
+
+	quote, size, err := requiredQuote(r.page, selector, field, r.target)
+	if err != nil {
+		return quote, size, err
+	}
+	if quote.Valid {
+		r.extracted(field, models.ExtractionDOM)
+	}
+	if size.Valid {
+		r.extracted(sizeField, models.ExtractionDOM)
+	}
+
+	return quote, size, nil
+}
+
+// optional reads a value that may be missing from the page, scraping the text of the page when it is not embedded
+func (r *yahooReader) optional(field string, value models.NullableInt, scrape func() models.NullableInt) models.NullableInt {
+	if r.found(field, value.Valid) {
+		return value
+	}
+
+	value = scrape()
+	if value.Valid {
+		r.extracted(field, models.ExtractionDOM)
+	}
+
+	return value
+}
+
+// stockData reads the trailing PE and EPS, a page missing both of them from its embedded data and its text failing the scrape
+func (r *yahooReader) stockData() error {
+	r.partial.PE = positiveInt(r.payload.Cents("pe"))
+	r.partial.EPS = r.payload.Cents("eps")
+	r.found("pe", r.partial.PE.Valid)
+	r.found("eps", r.partial.EPS.Valid)
+	if r.partial.PE.Valid && r.partial.EPS.Valid {
+		return nil
+	}
+
+	pe, eps, err := scrapeStockData(r.page, r.target)
+	if err != nil {
+		if r.partial.PE.Valid || r.partial.EPS.Valid {
+			log.Warnf("%v", err)
+			return nil
+		}
+		return err
+	}
+
+	if !r.partial.PE.Valid && pe.Valid {
+		r.partial.PE = pe
+		r.extracted("pe", models.ExtractionDOM)
+	}
+	if !r.partial.EPS.Valid && eps.Valid {
+		r.partial.EPS = eps
+		r.extracted("eps", models.ExtractionDOM)
+	}
+
+	return nil
+}
+
+// yield reads the trailing yield of a fund or the forward yield of anything else
+func (r *yahooReader) yield() {
+	field, timing := "forwardYield", models.TimingFWD
+	if r.partial.Typology.String == "ETF" {
+		field, timing = "yield", models.TimingTTM
+	}
+
+	if value := positiveInt(r.payload.Cents(field)); value.Valid {
+		r.partial.Yield = value
+		r.partial.DividendTiming = models.ValidString(string(timing))
+		r.extracted("yield", models.ExtractionJSON)
+		return
+	}
+
+	scrapeYield(r.page, r.partial, r.target)
+	if r.partial.Yield.Valid {
+		r.extracted("yield", models.ExtractionDOM)
+	}
+}
+
+// fund reads the fund specific data and the raw top holdings of an ETF
+func (r *yahooReader) fund() {
+	page, target, partial := r.page, r.target, r.partial
+
+	partial.AUM = r.optional("aum", positiveInt(r.payload.Count("aum")), func() models.NullableInt {
+		return optionalNumber(page, YH_AUM_SELECTOR, "AUM", target)
+	})
+	log.Debug("Scraped AUM")
+
+	partial.ExpenseRatio = r.optional("expenseRatio", positiveInt(r.payload.Cents("expenseRatio")), func() models.NullableInt {
+		return optionalCents(page, YH_ER_SELECTOR, "expense ratio", true, target)
+	})
+	partial.NAV = r.optional("nav", positiveInt(r.payload.Cents("nav")), func() models.NullableInt {
+		return optionalCents(page, YH_NAV_SELECTOR, "NAV", true, target)
+	})
+
+	partial.Family = r.payload.String("family")
+	partial.InceptionDate = r.payload.Date("inception")
+	r.found("family", partial.Family.Valid)
+	r.found("inception", partial.InceptionDate.Valid)
+	if !partial.Family.Valid || !partial.InceptionDate.Valid {
+		family, inception := scrapeFundData(page, target)
+		if !partial.Family.Valid && family.Valid {
+			partial.Family = family
+			r.extracted("family", models.ExtractionDOM)
+		}
+		if !partial.InceptionDate.Valid && inception.Valid {
+			partial.InceptionDate = inception
+			r.extracted("inception", models.ExtractionDOM)
+		}
+	}
+	log.Debug("Scraped inception date")
+
+	partial.Holdings = r.payload.Holdings()
+	if r.found("holdings", len(partial.Holdings) > 0) {
+		return
+	}
+
+	partial.Holdings = scrapeHoldings(page, target)
+	if len(partial.Holdings) > 0 {
+		r.extracted("holdings", models.ExtractionDOM)
+	}
+}
+
+// positiveInt drops the values that are not strictly positive
+func positiveInt(value models.NullableInt) models.NullableInt {
+	if value.Valid && value.Int64 <= 0 {
+		return models.NullableInt{Valid: false}
+	}
+	return value
+}
+
+// scrapeStockData reads the trailing PE and EPS from the text of the page
+func scrapeStockData(page *rod.Page, target *ScrapeTarget) (models.NullableInt, models.NullableInt, error) {
+	var pe, eps models.NullableInt
+
+	stockDataElements, err := findElements(target, page, YH_STOCK_DATA_SELECTOR)
+	if err != nil {
+		return pe, eps, fmt.Errorf("trailing PE not found in page - target: %s", target)
+	}
+
+	if len(stockDataElements) == 0 {
+		log.Warnf("empty trailing PE - target: %s", target)
+	}
+
+	if len(stockDataElements) == 1 || len(stockDataElements) == 2 {
+		peStr := helpers.NormalizeFloatStrToIntStr(stockDataElements[0].MustText())
+		if peStr == "" {
+			return pe, eps, fmt.Errorf("empty trailing PE: %s - target: %s", peStr, target)
+		}
+
+		scrapedPe, err := strconv.Atoi(peStr)
+		if err != nil || scrapedPe <= 0 {
+			log.Warnf("invalid trailing PE: %s - target: %s", peStr, target)
+		} else {
+			pe = models.ValidInt(int64(scrapedPe))
+		}
+	}
+	log.Debug("Scraped trailing PE")
+
+	if len(stockDataElements) == 2 {
+		epsStr := helpers.NormalizeFloatStrToIntStr(stockDataElements[1].MustText())
+		if epsStr == "" {
+			return pe, eps, fmt.Errorf("empty EPS: %s - target: %s", epsStr, target)
+		}
+
+		scrapedEps, err := strconv.Atoi(epsStr)
+		if err != nil {
+			log.Warnf("invalid EPS: %s - target: %s", epsStr, target)
+		} else {
+			eps = models.ValidInt(int64(scrapedEps))
+		}
+	}
+	log.Debug("Scraped EPS")
+
+	return pe, eps, nil
+}
+
+// scrapeYield reads the trailing yield of a fund or the forward yield of anything else from the text of the page
+func scrapeYield(page *rod.Page, partial *models.PartialSecurity, target *ScrapeTarget) {
+	var yieldStr string
+	var timing models.Timing
+	if partial.Typology.String == "ETF" {
+		scrapedYield, err := findText(target, page, YH_YIELD_SELECTOR)
+		if err != nil {
+			log.Warnf("yield not found in page - target: %s", target)
+			return
+		}
+		yieldStr = scrapedYield
+		timing = models.TimingTTM
+	} else {
+		scrapedYield, err := findText(target, page, YH_FWD_YIELD_SELECTOR)
+		if err != nil {
+			log.Warnf("forward dividend & yield not found in page - target: %s", target)
+			return
+		}
+		yieldStr = extractPercentage(scrapedYield)
+		timing = models.TimingFWD
+	}
+	log.Debugf("Scraped yield: %s", yieldStr)
+
+	yieldStr = helpers.NormalizeFloatStrToIntStr(yieldStr)
+	if isAnEmptyString(yieldStr) {
+		log.Warnf("empty yield: %s - target: %s", yieldStr, target)
+		return
+	}
+
+	scrapedYield, err := strconv.Atoi(yieldStr)
+	if err != nil || scrapedYield <= 0 {
+		log.Warnf("invalid yield: %s - target: %s", yieldStr, target)
+		return
+	}
+
+	partial.Yield = models.ValidInt(int64(scrapedYield))
+	partial.DividendTiming = models.ValidString(string(timing))
+}
+
+// scrapeFundData reads the family and the inception date of a fund from the text of the page
+func scrapeFundData(page *rod.Page, target *ScrapeTarget) (models.NullableString, models.NullableTime) {
+	var family models.NullableString
+	var inception models.NullableTime
+
+	etfDataElems, err := findElements(target, page, YH_ETF_DATA_SELECTOR)
+	if err != nil || len(etfDataElems) < 4 {
+		log.Warnf("inception date not found in page - target: %s", target)
+		return family, inception
+	}
+
+	scrapedFamily := etfDataElems[0].MustText()
+	log.Debugf("Scraped family: %s", scrapedFamily)
+	family = models.ValidString(scrapedFamily)
+
+	inceptionDateStr := etfDataElems[3].MustText()
+	log.Debugf("Scraped inception date: %s", inceptionDateStr)
+	scrapedInceptionDate, err := time.Parse("2006-01-02", inceptionDateStr)
+	if err != nil {
+		log.Warnf("invalid inception date: %s - target: %s", inceptionDateStr, target)
+	} else {
+		inception = models.ValidTime(scrapedInceptionDate)
+	}
+
+	return family, inception
+}
+
+// scrapeHoldings reads the raw top holdings of a fund from the text of the page
+func scrapeHoldings(page *rod.Page, target *ScrapeTarget) []models.PartialHolding {
+	var holdings []models.PartialHolding
+
+	relationsElementsTickers, err := findElements(target, page, YH_HOLDINGS_TICKERS_SELECTOR)
+	if err != nil {
+		log.Warnf("top holdings not found in page - target: %s", target)
+	}
+
+	relationsElementsAllocations, err := findElements(target, page, YH_HOLDING_ALLOCATIONS_SELECTOR)
+	if err != nil {
+		log.Warnf("top holdings allocations not found in page - target: %s", target)
+	}
+
+	for i := range min(len(relationsElementsTickers), len(relationsElementsAllocations)) {
+		seed := strings.TrimSpace(relationsElementsTickers[i].MustText())
+		if isAnEmptyString(seed) {
+			log.Warnf("empty top holding: %s - target: %s", seed, target)
+			continue
+		}
+
+		allocationStr := relationsElementsAllocations[i].MustText()
+		log.Debugf("Scraped top holding: %s -> %s", seed, allocationStr)
+		scrapedAllocation, err := parseCents(allocationStr)
+		if err != nil || scrapedAllocation <= 0 {
+			log.Warnf("invalid allocation: %s - target: %s", allocationStr, target)
+			continue
+		}
+
+		holdings = append(holdings, models.PartialHolding{Seed: seed, Allocation: scrapedAllocation})
+	}
+
+	return holdings
+}
+
+// requiredCents scrapes a mandatory decimal value, failing the whole scrape when it is missing
+func requiredCents(page *rod.Page, selector string, label string, positive bool, target *ScrapeTarget) (int, error) {
+	valueStr, err := findText(target, page, selector)
+	if err != nil {
+		return 0, fmt.Errorf("%s not found in page - target: %s", label, target)
+	}
+	log.Debugf("Scraped %s: %s", label, valueStr)
+
+	valueStr = helpers.NormalizeFloatStrToIntStr(valueStr)
+	if isAnEmptyString(valueStr) {
+		return 0, fmt.Errorf("empty %s: %s - target: %s", label, valueStr, target)
+	}
+
+	value, err := strconv.Atoi(valueStr)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %s - target: %s", label, valueStr, target)
+	}
+
+	if positive && value <= 0 {
+		return 0, fmt.Errorf("invalid negative %s: %d - target: %s", label, value, target)
+	}
+
+	return value, nil
+}
+
+// requiredRange scrapes a mandatory "low - high" range
+func requiredRange(page *rod.Page, selector string, label string, target *ScrapeTarget) (int, int, error) {
+	rangeStr, err := findText(target, page, selector)
+	if err != nil {
+		return 0, 0, fmt.Errorf("%s not found in page - target: %s", label, target)
+	}
+	log.Debugf("Scraped %s: %s", label, rangeStr)
+
+	rangeStr = strings.ReplaceAll(rangeStr, " ", "")
+	rangeArr := strings.Split(rangeStr, "-")
+	if len(rangeArr) != 2 {
+		return 0, 0, fmt.Errorf("invalid %s: %s - target: %s", label, rangeStr, target)
+	}
+
+	bounds := make([]int, 2)
+	for i, boundStr := range rangeArr {
+		boundStr = helpers.NormalizeFloatStrToIntStr(boundStr)
+		if boundStr == "" {
+			return 0, 0, fmt.Errorf("empty %s bound: %s - target: %s", label, rangeStr, target)
+		}
+
+		bound, err := strconv.Atoi(boundStr)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid %s bound: %s - target: %s", label, boundStr, target)
+		}
+
+		if bound <= 0 {
+			return 0, 0, fmt.Errorf("invalid negative %s bound: %d - target: %s", label, bound, target)
+		}
+
+		bounds[i] = bound
+	}
+
+	if bounds[1] < bounds[0] {
+		return 0, 0, fmt.Errorf("invalid %s high < low: %d < %d - target: %s", label, bounds[1], bounds[0], target)
+	}
+
+	return bounds[0], bounds[1], nil
+}
+
+// requiredQuote scrapes a mandatory "price x size" quote. An empty quote is not an error: the merger falls back to the price.
+func requiredQuote(page *rod.Page, selector string, label string, target *ScrapeTarget) (models.NullableInt, models.NullableInt, error) {
+	var quote, size models.NullableInt
+
+	payloadStr, err := findText(target, page, selector)
+	if err != nil {
+		return quote, size, fmt.Errorf("%s not found in page - target: %s", label, target)
+	}
+	log.Debugf("Scraped %s: %s", label, payloadStr)
+
+	payloadStr = strings.ReplaceAll(payloadStr, " ", "")
+	payloadArr := strings.Split(payloadStr, "x")
+	if isAnEmptyString(payloadStr) || len(payloadArr) != 2 {
+		return quote, size, nil
+	}
+
+	quoteStr := helpers.NormalizeFloatStrToIntStr(payloadArr[0])
+	if isAnEmptyString(quoteStr) {
+		return quote, size, fmt.Errorf("empty %s: %s - target: %s", label, quoteStr, target)
+	}
+
+	scrapedQuote, err := strconv.Atoi(quoteStr)
+	if err != nil {
+		return quote, size, fmt.Errorf("invalid %s: %s - target: %s", label, quoteStr, target)
+	}
+
+	if scrapedQuote <= 0 {
+		return quote, size, fmt.Errorf("invalid negative %s: %d - target: %s", label, scrapedQuote, target)
+	}
+	quote = models.ValidInt(int64(scrapedQuote))
+
+	sizeStr := payloadArr[1]
+	if isAnEmptyString(sizeStr) {
+		log.Warnf("empty %s size: %s - target: %s", label, sizeStr, target)
+		return quote, size, nil
+	}
+
+	scrapedSize, err := strconv.Atoi(sizeStr)
+	if err != nil || scrapedSize < 0 {
+		log.Warnf("invalid %s size: %s - target: %s", label, sizeStr, target)
+		return quote, size, nil
+	}
+
+	return quote, models.ValidInt(int64(scrapedSize)), nil
+}
+
+// optionalCents scrapes a decimal value that may be missing from the page
+func optionalCents(page *rod.Page, selector string, label string, positive bool, target *ScrapeTarget) models.NullableInt {
+	valueStr, err := findText(target, page, selector)
+	if err != nil {
+		log.Warnf("%s not found in page - target: %s", label, target)
+		return models.NullableInt{Valid: false}
+	}
+	log.Debugf("Scraped %s: %s", label, valueStr)
+
+	valueStr = helpers.NormalizeFloatStrToIntStr(valueStr)
+	if isAnEmptyString(valueStr) {
+		log.Warnf("empty %s: %s - target: %s", label, valueStr, target)
+		return models.NullableInt{Valid: false}
+	}
+
+	value, err := strconv.Atoi(valueStr)
+	if err != nil || (positive && value <= 0) {
+		log.Warnf("invalid %s: %s - target: %s", label, valueStr, target)
+		return models.NullableInt{Valid: false}
+	}
+
+	return models.ValidInt(int64(value))
+}
+
+// optionalNumber scrapes an abbreviated whole number like "1.23T" that may be missing from the page
+func optionalNumber(page *rod.Page, selector string, label string, target *ScrapeTarget) models.NullableInt {
+	valueStr, err := findText(target, page, selector)
+	if err != nil || isAnEmptyString(valueStr) {
+		log.Warnf("%s not found in page - target: %s", label, target)
+		return models.NullableInt{Valid: false}
+	}
+	log.Debugf("Scraped %s: %s", label, valueStr)
+
+	value, err := helpers.ParseNumberString(strings.TrimSpace(valueStr))
+	if err != nil || value <= 0 {
+		log.Warnf("invalid %s: %s - target: %s", label, valueStr, target)
+		return models.NullableInt{Valid: false}
+	}
+
+	return models.ValidInt(value)
+}
+
+// optionalCount scrapes a whole number like "1,234,567" that may be missing from the page
+func optionalCount(page *rod.Page, selector string, label string, target *ScrapeTarget) models.NullableInt {
+	valueStr, err := findText(target, page, selector)
+	if err != nil {
+		log.Warnf("%s not found in page - target: %s", label, target)
+		return models.NullableInt{Valid: false}
+	}
+	log.Debugf("Scraped %s: %s", label, valueStr)
+
+	valueStr = strings.ReplaceAll(valueStr, ",", "")
+	if isAnEmptyString(valueStr) {
+		log.Warnf("empty %s: %s - target: %s", label, valueStr, target)
+		return models.NullableInt{Valid: false}
+	}
+
+	value, err := strconv.ParseInt(valueStr, 10, 64)
+	if err != nil || value <= 0 {
+		log.Warnf("invalid %s: %s - target: %s", label, valueStr, target)
+		return models.NullableInt{Valid: false}
+	}
+
+	return models.ValidInt(value)
+}
@@ -0,0 +1,377 @@
+package tools
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/Francesco99975/finexo/internal/models"
+	"github.com/go-rod/rod"
+)
+
+// Yahoo quote pages embed the API responses they were rendered from as JSON script tags, the url they were
+// fetched from in data-url and the response body, itself JSON, as a string in the body of the payload.
+const yahooPayloadScript = `() => JSON.stringify(Array.from(
+	document.querySelectorAll('script[type="application/json"][data-url]'),
+	(script) => ({ url: script.dataset.url, text: script.textContent }),
+))`
+
+// Module the quote endpoint response is stored under, next to the quoteSummary modules
+const yahooQuoteModule = "quote"
+
+// YahooScript is a JSON payload embedded in a Yahoo page
+type YahooScript struct {
+	URL  string `json:"url"`
+	Text string `json:"text"`
+}
+
+// YahooPayload holds the quoteSummary modules (price, summaryDetail, defaultKeyStatistics, ...) and the quote
+// embedded in a Yahoo page. Numbers are decoded as json.Number from the raw values, rather than parsed back from
+// their formatted text.
+type YahooPayload struct {
+	modules map[string]map[string]any
+}
+
+// yahooKey locates a value by module and dotted path within the module
+type yahooKey struct {
+	Module string
+	Path   string
+	// Fraction values are ratios (0.0123) of what is stored as a percentage (1.23%)
+	Fraction bool
+}
+
+// yahooKeys lists where each value is found in the payload, by json name of its PartialSecurity field, in order
+// of preference. The quote endpoint sends plain numbers and percentages where quoteSummary sends fractions.
+// Funds show their trailing yield, anything else its forward yield.
+var yahooKeys = map[string][]yahooKey{
+	"currency":      {{Module: "price", Path: "currency"}, {Module: yahooQuoteModule, Path: "currency"}, {Module: "summaryDetail", Path: "currency"}},
+	"fullName":      {{Module: "price", Path: "longName"}, {Module: "price", Path: "shortName"}, {Module: yahooQuoteModule, Path: "longName"}, {Module: yahooQuoteModule, Path: "shortName"}},
+	"price":         {{Module: "price", Path: "regularMarketPrice"}, {Module: yahooQuoteModule, Path: "regularMarketPrice"}},
+	"pc":            {{Module: "price", Path: "regularMarketChange"}, {Module: yahooQuoteModule, Path: "regularMarketChange"}},
+	"pcp":           {{Module: "price", Path: "regularMarketChangePercent", Fraction: true}, {Module: yahooQuoteModule, Path: "regularMarketChangePercent"}},
+	"yearLow":       {{Module: "summaryDetail", Path: "fiftyTwoWeekLow"}, {Module: yahooQuoteModule, Path: "fiftyTwoWeekLow"}},
+	"yearHigh":      {{Module: "summaryDetail", Path: "fiftyTwoWeekHigh"}, {Module: yahooQuoteModule, Path: "fiftyTwoWeekHigh"}},
+	"dayLow":        {{Module: "price", Path: "regularMarketDayLow"}, {Module: "summaryDetail", Path: "dayLow"}, {Module: yahooQuoteModule, Path: "regularMarketDayLow"}},
+	"dayHigh":       {{Module: "price", Path: "regularMarketDayHigh"}, {Module: "summaryDetail", Path: "dayHigh"}, {Module: yahooQuoteModule, Path: "regularMarketDayHigh"}},
+	"marketCap":     {{Module: "price", Path: "marketCap"}, {Module: "summaryDetail", Path: "marketCap"}, {Module: yahooQuoteModule, Path: "marketCap"}},
+	"volume":        {{Module: "price", Path: "regularMarketVolume"}, {Module: "summaryDetail", Path: "volume"}, {Module: yahooQuoteModule, Path: "regularMarketVolume"}},
+	"avgVolume":     {{Module: "summaryDetail", Path: "averageVolume"}, {Module: "price", Path: "averageDailyVolume3Month"}, {Module: yahooQuoteModule, Path: "averageDailyVolume3Month"}},
+	"beta":          {{Module: "summaryDetail", Path: "beta"}, {Module: "defaultKeyStatistics", Path: "beta"}},
+	"previousClose": {{Module: "price", Path: "regularMarketPreviousClose"}, {Module: "summaryDetail", Path: "previousClose"}, {Module: yahooQuoteModule, Path: "regularMarketPreviousClose"}},
+	"currentOpen":   {{Module: "price", Path: "regularMarketOpen"}, {Module: "summaryDetail", Path: "open"}, {Module: yahooQuoteModule, Path: "regularMarketOpen"}},
+	"bid":           {{Module: "summaryDetail", Path: "bid"}, {Module: yahooQuoteModule, Path: "bid"}},
+	"bidSize":       {{Module: "summaryDetail", Path: "bidSize"}, {Module: yahooQuoteModule, Path: "bidSize"}},
+	"ask":           {{Module: "summaryDetail", Path: "ask"}, {Module: yahooQuoteModule, Path: "ask"}},
+	"askSize":       {{Module: "summaryDetail", Path: "askSize"}, {Module: yahooQuoteModule, Path: "askSize"}},
+	"pe":            {{Module: "summaryDetail", Path: "trailingPE"}, {Module: yahooQuoteModule, Path: "trailingPE"}},
+	"eps":           {{Module: "defaultKeyStatistics", Path: "trailingEps"}, {Module: yahooQuoteModule, Path: "epsTrailingTwelveMonths"}},
+	"target":        {{Module: "financialData", Path: "targetMeanPrice"}},
+	"yield":         {{Module: "summaryDetail", Path: "yield", Fraction: true}},
+	"forwardYield":  {{Module: "summaryDetail", Path: "dividendYield", Fraction: true}, {Module: yahooQuoteModule, Path: "dividendYield"}},
+	"family":        {{Module: "fundProfile", Path: "family"}, {Module: "defaultKeyStatistics", Path: "fundFamily"}},
+	"aum":           {{Module: "summaryDetail", Path: "totalAssets"}, {Module: "defaultKeyStatistics", Path: "totalAssets"}},
+	"expenseRatio":  {{Module: "fundProfile", Path: "feesExpensesInvestment.annualReportExpenseRatio", Fraction: true}, {Module: "defaultKeyStatistics", Path: "annualReportExpenseRatio", Fraction: true}},
+	"nav":           {{Module: "summaryDetail", Path: "navPrice"}},
+	"inception":     {{Module: "defaultKeyStatistics", Path: "fundInceptionDate"}},
+	"marketTime":    {{Module: "price", Path: "regularMarketTime"}, {Module: yahooQuoteModule, Path: "regularMarketTime"}},
+}
+
+// readYahooPayload collects the JSON payloads embedded in the loaded page
+func readYahooPayload(page *rod.Page) (*YahooPayload, error) {
+	result, err := page.Eval(yahooPayloadScript)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embedded payloads: %v", err)
+	}
+
+	var scripts []YahooScript
+	err = json.Unmarshal([]byte(result.Value.Str()), &scripts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode embedded payloads: %v", err)
+	}
+
+	return ParseYahooPayload(scripts)
+}
+
+// ParseYahooPayload merges the quoteSummary modules and the quote found in the scripts of a Yahoo page,
+// the first script providing a value winning. Scripts of other endpoints are ignored.
+func ParseYahooPayload(scripts []YahooScript) (*YahooPayload, error) {
+	payload := &YahooPayload{modules: make(map[string]map[string]any)}
+
+	for _, script := range scripts {
+		response, err := decodeYahooResponse(script.Text)
+		if err != nil {
+			continue
+		}
+
+		if summary, ok := firstResult(response, "quoteSummary"); ok {
+			for name, module := range summary {
+				if fields, ok := module.(map[string]any); ok {
+					payload.merge(name, fields)
+				}
+			}
+		}
+
+		if quote, ok := firstResult(response, "quoteResponse"); ok {
+			payload.merge(yahooQuoteModule, quote)
+		}
+	}
+
+	if len(payload.modules) == 0 {
+		return nil, fmt.Errorf("no quote data among %d embedded payloads", len(scripts))
+	}
+
+	return payload, nil
+}
+
+// decodeYahooResponse unwraps the response body of an embedded payload, which is either a JSON string or
+// an object, falling back to the payload itself when it is not wrapped
+func decodeYahooResponse(text string) (map[string]any, error) {
+	var wrapper map[string]any
+	if err := decodeNumbers(text, &wrapper); err != nil {
+		return nil, err
+	}
+
+	switch body := wrapper["body"].(type) {
+	case string:
+		var response map[string]any
+		if err := decodeNumbers(body, &response); err != nil {
+			return nil, err
+		}
+		return response, nil
+	case map[string]any:
+		return body, nil
+	default:
+		return wrapper, nil
+	}
+}
+
+func decodeNumbers(text string, v any) error {
+	decoder := json.NewDecoder(bytes.NewReader([]byte(text)))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+// firstResult returns the first result of an API response like {"quoteSummary": {"result": [...]}}
+func firstResult(response map[string]any, name string) (map[string]any, bool) {
+	envelope, ok := response[name].(map[string]any)
+	if !ok {
+		return nil, false
+	}
+
+	results, ok := envelope["result"].([]any)
+	if !ok || len(results) == 0 {
+		return nil, false
+	}
+
+	result, ok := results[0].(map[string]any)
+	return result, ok
+}
+
+func (p *YahooPayload) merge(name string, fields map[string]any) {
+	module, ok := p.modules[name]
+	if !ok {
+		module = make(map[string]any, len(fields))
+		p.modules[name] = module
+	}
+
+	for key, value := range fields {
+		if _, ok := module[key]; !ok {
+			module[key] = value
+		}
+	}
+}
+
+// lookup returns the value at the key, unwrapping the {"raw": ..., "fmt": ...} objects of quoteSummary.
+// Empty objects, which Yahoo sends for missing values, are not found.
+func (p *YahooPayload) lookup(key yahooKey) (any, bool) {
+	if p == nil {
+		return nil, false
+	}
+
+	var value any = p.modules[key.Module]
+	for _, part := range strings.Split(key.Path, ".") {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		value, ok = object[part]
+		if !ok {
+			return nil, false
+		}
+	}
+
+	if object, ok := value.(map[string]any); ok {
+		raw, ok := object["raw"]
+		if !ok {
+			return nil, false
+		}
+		value = raw
+	}
+
+	return value, value != nil
+}
+
+// number returns the first of the keys holding a number, percentages being scaled from fractions
+func (p *YahooPayload) number(keys []yahooKey) (float64, bool) {
+	for _, key := range keys {
+		value, ok := p.lookup(key)
+		if !ok {
+			continue
+		}
+
+		number, ok := value.(json.Number)
+		if !ok {
+			continue
+		}
+
+		parsed, err := number.Float64()
+		if err != nil || math.IsNaN(parsed) || math.IsInf(parsed, 0) {
+			continue
+		}
+
+		if key.Fraction {
+			parsed *= 100
+		}
+
+		return parsed, true
+	}
+
+	return 0, false
+}
+
+// Cents returns the decimal value of the field in its integer representation (1.23 -> 123)
+func (p *YahooPayload) Cents(field string) models.NullableInt {
+	return p.cents(yahooKeys[field])
+}
+
+func (p *YahooPayload) cents(keys []yahooKey) models.NullableInt {
+	value, ok := p.number(keys)
+	if !ok {
+		return models.NullableInt{Valid: false}
+	}
+
+	return models.ValidInt(int64(math.Round(value * 100)))
+}
+
+// Count returns the whole number value of the field, like a volume or a market cap
+func (p *YahooPayload) Count(field string) models.NullableInt {
+	value, ok := p.number(yahooKeys[field])
+	if !ok {
+		return models.NullableInt{Valid: false}
+	}
+
+	return models.ValidInt(int64(math.Round(value)))
+}
+
+// String returns the text value of the field, if not empty
+func (p *YahooPayload) String(field string) models.NullableString {
+	return p.str(yahooKeys[field])
+}
+
+func (p *YahooPayload) str(keys []yahooKey) models.NullableString {
+	for _, key := range keys {
+		value, ok := p.lookup(key)
+		if !ok {
+			continue
+		}
+
+		str, ok := value.(string)
+		if ok && !isAnEmptyString(str) {
+			return models.ValidString(strings.TrimSpace(str))
+		}
+	}
+
+	return models.NullableString{Valid: false}
+}
+
+// Date returns the unix time value of the field as the UTC day it falls on
+func (p *YahooPayload) Date(field string) models.NullableTime {
+	value, ok := p.number(yahooKeys[field])
+	if !ok || value <= 0 {
+		return models.NullableTime{Valid: false}
+	}
+
+	date := time.Unix(int64(value), 0).UTC().Truncate(24 * time.Hour)
+
+	return models.ValidTime(date)
+}
+
+// MarketDate is the day of the regular session the quote comes from, in the time zone of the exchange when known
+func (p *YahooPayload) MarketDate() models.NullableTime {
+	value, ok := p.number(yahooKeys["marketTime"])
+	if !ok || value <= 0 {
+		return models.NullableTime{Valid: false}
+	}
+
+	location := time.UTC
+	zone := p.str([]yahooKey{{Module: "price", Path: "exchangeTimezoneName"}, {Module: yahooQuoteModule, Path: "exchangeTimezoneName"}})
+	if zone.Valid {
+		if loaded, err := time.LoadLocation(zone.String); err == nil {
+			location = loaded
+		}
+	}
+
+	session := time.Unix(int64(value), 0).In(location)
+
+	return models.ValidTime(time.Date(session.Year(), session.Month(), session.Day(), 0, 0, 0, 0, time.UTC))
+}
+
+// Typology tells funds from REITs and stocks, unknown when the payload misses the quote type, or the
+// industry of an equity
+func (p *YahooPayload) Typology() models.NullableString {
+	quoteType := p.str([]yahooKey{{Module: "price", Path: "quoteType"}, {Module: yahooQuoteModule, Path: "quoteType"}})
+	if !quoteType.Valid {
+		return quoteType
+	}
+
+	switch strings.ToUpper(quoteType.String) {
+	case "ETF":
+		return models.ValidString("ETF")
+	case "EQUITY":
+		industry := p.str([]yahooKey{{Module: "assetProfile", Path: "industry"}, {Module: "summaryProfile", Path: "industry"}})
+		if !industry.Valid {
+			return models.NullableString{Valid: false}
+		}
+		if strings.Contains(strings.ToLower(industry.String), "reit") {
+			return models.ValidString("REIT")
+		}
+		return models.ValidString("STOCK")
+	default:
+		return models.NullableString{Valid: false}
+	}
+}
+
+// Holdings returns the top holdings of a fund, allocations being percentages in their integer representation
+func (p *YahooPayload) Holdings() []models.PartialHolding {
+	value, ok := p.lookup(yahooKey{Module: "topHoldings", Path: "holdings"})
+	if !ok {
+		return nil
+	}
+
+	entries, ok := value.([]any)
+	if !ok {
+		return nil
+	}
+
+	var holdings []models.PartialHolding
+	for _, entry := range entries {
+		fields, ok := entry.(map[string]any)
+		if !ok {
+			continue
+		}
+		holding := &YahooPayload{modules: map[string]map[string]any{"holding": fields}}
+
+		seed := holding.str([]yahooKey{{Module: "holding", Path: "symbol"}})
+		allocation := holding.cents([]yahooKey{{Module: "holding", Path: "holdingPercent", Fraction: true}})
+		if !seed.Valid || !allocation.Valid || allocation.Int64 <= 0 {
+			continue
+		}
+
+		holdings = append(holdings, models.PartialHolding{Seed: seed.String, Allocation: int(allocation.Int64)})
+	}
+
+	return holdings
+}
@@ -41,6 +41,9 @@ BEGIN
     IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'frequence') THEN
         CREATE TYPE frequence AS ENUM ('unknown', 'weekly', 'biweekly', 'monthly', 'quarterly', 'semi-annual', 'annual');
     END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'distribution') THEN
+        CREATE TYPE distribution AS ENUM ('regular', 'special', 'return-of-capital');
+    END IF;
 END $$;
 
 
@@ -160,7 +163,98 @@ CREATE TABLE IF NOT EXISTS dividends (
 );
 
 
+CREATE TABLE IF NOT EXISTS dividend_events (
+    ticker VARCHAR(20) NOT NULL,
+    exchange VARCHAR(50) NOT NULL,
+    edd DATE NOT NULL,                           -- Ex-Dividend Date
+    pd DATE,                                     -- Payout Date
+    amount INT NOT NULL,                         -- Amount per share in cents
+    type distribution NOT NULL DEFAULT 'regular',
+    PRIMARY KEY (ticker, exchange, edd, type),
+    FOREIGN KEY (ticker, exchange) REFERENCES securities (ticker, exchange) ON DELETE CASCADE
+);
+
+
+CREATE TABLE IF NOT EXISTS dividend_analytics (
+    ticker VARCHAR(20) NOT NULL,
+    exchange VARCHAR(50) NOT NULL,
+    cagr1 INT,                                   -- 1 year dividend growth (e.g., 4.25%)
+    cagr3 INT,                                   -- 3 years compound growth
+    cagr5 INT,                                   -- 5 years compound growth (same as dividends.lgr)
+    cagr10 INT,                                  -- 10 years compound growth
+    yog INT NOT NULL DEFAULT 0,                  -- Consecutive years of increases
+    cuts INT NOT NULL DEFAULT 0,                 -- Full years paying less than the year before
+    lcy INT,                                     -- Last year with a cut
+    computed TIMESTAMP NOT NULL,
+    PRIMARY KEY (ticker, exchange),
+    FOREIGN KEY (ticker, exchange) REFERENCES securities (ticker, exchange) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS prices (
+    ticker VARCHAR(20) NOT NULL,
+    exchange VARCHAR(50) NOT NULL,
+    date DATE NOT NULL,                          -- Trading day the bar refers to
+    open INT NOT NULL,                           -- Open in cents
+    high INT NOT NULL,                           -- Day high in cents
+    low INT NOT NULL,                            -- Day low in cents
+    close INT NOT NULL,                          -- Last scraped price in cents
+    volume BIGINT,
+    PRIMARY KEY (ticker, exchange, date),
+    FOREIGN KEY (ticker, exchange) REFERENCES securities (ticker, exchange) ON DELETE CASCADE
+);
+
 CREATE INDEX IF NOT EXISTS idx_securities_ticker_exchange ON securities(ticker, exchange);
 CREATE INDEX IF NOT EXISTS idx_securities_typology ON securities(typology);
 CREATE INDEX IF NOT EXISTS idx_dividends_yield ON dividends(yield);
+CREATE INDEX IF NOT EXISTS idx_dividends_lgr ON dividends(lgr);
+CREATE INDEX IF NOT EXISTS idx_dividends_yog ON dividends(yog);
 CREATE INDEX IF NOT EXISTS idx_etfs_aum ON etfs(aum);
+CREATE INDEX IF NOT EXISTS idx_prices_date ON prices(date);
+
+CREATE TABLE IF NOT EXISTS scrape_runs (
+    id SERIAL PRIMARY KEY,
+    suffix VARCHAR(10) NOT NULL DEFAULT '',      -- Seed suffix filter of the run ('' for every seed)
+    started TIMESTAMP NOT NULL,
+    finished TIMESTAMP NOT NULL,
+    seeds INT NOT NULL,
+    succeeded INT NOT NULL,
+    failed INT NOT NULL,
+    selectors JSONB NOT NULL DEFAULT '{}',       -- Hits and misses by selector
+    alerts JSONB NOT NULL DEFAULT '[]'           -- Selectors that drifted since the previous run
+);
+
+CREATE INDEX IF NOT EXISTS idx_scrape_runs_suffix_started ON scrape_runs(suffix, started DESC);
+
+CREATE TABLE IF NOT EXISTS scenarios (
+    id VARCHAR(12) PRIMARY KEY,                  -- Short ID shared in the URLs
+    kind VARCHAR(20) NOT NULL,                   -- Calculator of the inputs (compound, portfolio)
+    inputs JSONB NOT NULL,                       -- Inputs the results are calculated again from
+    created TIMESTAMP NOT NULL DEFAULT NOW(),
+    expires TIMESTAMP                            -- NULL for a scenario kept forever
+);
+
+CREATE INDEX IF NOT EXISTS idx_scenarios_expires ON scenarios(expires);
+
+CREATE TABLE IF NOT EXISTS scrape_jobs (
+    id SERIAL PRIMARY KEY,
+    seed VARCHAR(30) NOT NULL,
+    suffix VARCHAR(10) NOT NULL DEFAULT '',      -- Seed suffix filter of the run the job belongs to
+    run_started TIMESTAMP NOT NULL,              -- Start of the run the job belongs to, kept when it is resumed
+    status VARCHAR(10) NOT NULL DEFAULT 'pending', -- pending, running, done, dead after too many failures or skipped past the budget
+    rank INT NOT NULL DEFAULT 0,                 -- Position of the seed in the run, lower ranks leased first
+    attempts INT NOT NULL DEFAULT 0,
+    next_run_at TIMESTAMP NOT NULL DEFAULT NOW(), -- When a pending job may be leased again, later after every failure
+    leased_until TIMESTAMP,                      -- A running job whose lease expired is leased again (crashed worker)
+    last_error TEXT,
+    UNIQUE (suffix, seed)
+);
+
+CREATE INDEX IF NOT EXISTS idx_scrape_jobs_suffix_status ON scrape_jobs(suffix, status, next_run_at, rank);
+
+CREATE TABLE IF NOT EXISTS security_hits (
+    ticker VARCHAR(20) NOT NULL,
+    exchange VARCHAR(50) NOT NULL,
+    day DATE NOT NULL DEFAULT CURRENT_DATE,
+    hits INT NOT NULL DEFAULT 0,                 -- API and page views of the security on the day
+    PRIMARY KEY (ticker, exchange, day)
+);
@@ -0,0 +1,40 @@
+// Code generated by templ - DO NOT EDIT.
+
+// templ: version: v0.3.857
+package components
+
+//lint:file-ignore SA4006 This context is only used if a nested component is present.
+
+import "github.com/a-h/templ"
+import templruntime "github.com/a-h/templ/runtime"
+
+func GoalSeek() templ.Component {
+	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
+			return templ_7745c5c3_CtxErr
+		}
+		templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+		if !templ_7745c5c3_IsBuffer {
+			defer func() {
+				templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err == nil {
+					templ_7745c5c3_Err = templ_7745c5c3_BufErr
+				}
+			}()
+		}
+		ctx = templ.InitializeContext(ctx)
+		templ_7745c5c3_Var1 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var1 == nil {
+			templ_7745c5c3_Var1 = templ.NopComponent
+		}
+		ctx = templ.ClearChildren(ctx)
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 1, "<!-- Goal Seek --><div x-data=\"{ solve: &#39;&#39; }\" class=\"space-y-4\"><div><label for=\"solve\" class=\"block text-sm font-medium text-text-primary mb-1\">Solve For</label> <select id=\"solve\" name=\"solve\" class=\"block w-full p-2 border border-std rounded-md focus:ring-accent focus:border-accent\" x-model=\"solve\"><option value=\"\">Nothing (Project Inputs)</option> <option value=\"contribution\">Required Contribution</option> <option value=\"principal\">Required Initial Investment</option> <option value=\"years\">Required Years</option></select></div><div x-show=\"solve !== &#39;&#39;\" class=\"space-y-4\"><div><label for=\"goal\" class=\"block text-sm font-medium text-text-primary mb-1\">Goal</label> <select id=\"goal\" name=\"goal\" class=\"block w-full p-2 border border-std rounded-md focus:ring-accent focus:border-accent\"><option value=\"balance\">Final Balance</option> <option value=\"income\">Yearly Income</option></select></div><div><label for=\"goalamount\" class=\"block text-sm font-medium text-text-primary mb-1\">Goal Amount</label><div class=\"relative\"><div class=\"absolute inset-y-0 left-0 flex items-center pl-3 pointer-events-none\"><span class=\"text-text-secondary\">$</span></div><input type=\"number\" id=\"goalamount\" name=\"goalamount\" class=\"block w-full pl-8 p-2 border border-std rounded-md focus:ring-accent focus:border-accent\" value=\"24000\" min=\"0\"></div></div></div></div>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		return nil
+	})
+}
+
+var _ = templruntime.GeneratedTemplate
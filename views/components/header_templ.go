@@ -39,7 +39,7 @@ func Header() templ.Component {
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, "<h1 class=\"ml-2 text-xl font-bold text-white\">Finexo</h1></div><!-- Desktop Navigation --><div class=\"hidden md:flex items-center space-x-6\"><nav class=\"flex items-center space-x-4\"><a href=\"/\" class=\"text-white hover:text-white/80 font-medium\">Home</a> <a href=\"/req\" class=\"text-white hover:text-white/80 font-medium\">Request</a> <a href=\"/about\" class=\"text-white hover:text-white/80 font-medium\">About</a></nav><!-- Dark Mode Toggle --><button @click=\"darkMode = !darkMode\" class=\"p-2 rounded-full hover:bg-white/10 transition-colors focus:outline-none focus:ring-2 focus:ring-white/50\" aria-label=\"Toggle dark mode\">")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, "<h1 class=\"ml-2 text-xl font-bold text-white\">Finexo</h1></div><!-- Desktop Navigation --><div class=\"hidden md:flex items-center space-x-6\"><nav class=\"flex items-center space-x-4\"><a href=\"/\" class=\"text-white hover:text-white/80 font-medium\">Home</a> <a href=\"/portfolio\" class=\"text-white hover:text-white/80 font-medium\">Portfolio</a> <a href=\"/req\" class=\"text-white hover:text-white/80 font-medium\">Request</a> <a href=\"/about\" class=\"text-white hover:text-white/80 font-medium\">About</a></nav><!-- Dark Mode Toggle --><button @click=\"darkMode = !darkMode\" class=\"p-2 rounded-full hover:bg-white/10 transition-colors focus:outline-none focus:ring-2 focus:ring-white/50\" aria-label=\"Toggle dark mode\">")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -75,7 +75,7 @@ func Header() templ.Component {
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 5, "</button></div><!-- Mobile Menu --><div x-show=\"mobileMenuOpen\" x-transition:enter=\"transition ease-out duration-200\" x-transition:enter-start=\"opacity-0 -translate-y-4\" x-transition:enter-end=\"opacity-100 translate-y-0\" x-transition:leave=\"transition ease-in duration-150\" x-transition:leave-start=\"opacity-100 translate-y-0\" x-transition:leave-end=\"opacity-0 -translate-y-4\" class=\"fixed inset-x-0 top-[60px] bg-primary shadow-lg z-50\" x-cloak><nav class=\"container mx-auto px-4 py-3\"><div class=\"flex flex-col space-y-3\"><a href=\"/\" class=\"text-white hover:bg-white/10 py-2 px-3 rounded-md\">Home</a> <a href=\"/req\" class=\"text-white hover:bg-white/10 py-2 px-3 rounded-md\">Request</a> <a href=\"/about\" class=\"text-white hover:bg-white/10 py-2 px-3 rounded-md\">About</a></div></nav></div></div></div></header>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 5, "</button></div><!-- Mobile Menu --><div x-show=\"mobileMenuOpen\" x-transition:enter=\"transition ease-out duration-200\" x-transition:enter-start=\"opacity-0 -translate-y-4\" x-transition:enter-end=\"opacity-100 translate-y-0\" x-transition:leave=\"transition ease-in duration-150\" x-transition:leave-start=\"opacity-100 translate-y-0\" x-transition:leave-end=\"opacity-0 -translate-y-4\" class=\"fixed inset-x-0 top-[60px] bg-primary shadow-lg z-50\" x-cloak><nav class=\"container mx-auto px-4 py-3\"><div class=\"flex flex-col space-y-3\"><a href=\"/\" class=\"text-white hover:bg-white/10 py-2 px-3 rounded-md\">Home</a> <a href=\"/portfolio\" class=\"text-white hover:bg-white/10 py-2 px-3 rounded-md\">Portfolio</a> <a href=\"/req\" class=\"text-white hover:bg-white/10 py-2 px-3 rounded-md\">Request</a> <a href=\"/about\" class=\"text-white hover:bg-white/10 py-2 px-3 rounded-md\">About</a></div></nav></div></div></div></header>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
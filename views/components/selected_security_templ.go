@@ -9,6 +9,9 @@ import "github.com/a-h/templ"
 import templruntime "github.com/a-h/templ/runtime"
 
 import (
+	"fmt"
+
+	"github.com/Francesco99975/finexo/internal/helpers"
 	"github.com/Francesco99975/finexo/internal/models"
 	"github.com/Francesco99975/finexo/views/icons"
 )
@@ -49,7 +52,7 @@ func SelectedSecurity(selectedSecurity models.SelectedSecurityView, csrf string)
 		var templ_7745c5c3_Var2 string
 		templ_7745c5c3_Var2, templ_7745c5c3_Err = templ.JoinStringErrs(selectedSecurity.Ticker)
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `components/selected_security.templ`, Line: 17, Col: 73}
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `components/selected_security.templ`, Line: 20, Col: 73}
 		}
 		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var2))
 		if templ_7745c5c3_Err != nil {
@@ -62,7 +65,7 @@ func SelectedSecurity(selectedSecurity models.SelectedSecurityView, csrf string)
 		var templ_7745c5c3_Var3 string
 		templ_7745c5c3_Var3, templ_7745c5c3_Err = templ.JoinStringErrs(selectedSecurity.Exchange)
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `components/selected_security.templ`, Line: 18, Col: 101}
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `components/selected_security.templ`, Line: 21, Col: 101}
 		}
 		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var3))
 		if templ_7745c5c3_Err != nil {
@@ -75,7 +78,7 @@ func SelectedSecurity(selectedSecurity models.SelectedSecurityView, csrf string)
 		var templ_7745c5c3_Var4 string
 		templ_7745c5c3_Var4, templ_7745c5c3_Err = templ.JoinStringErrs(selectedSecurity.Typology)
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `components/selected_security.templ`, Line: 19, Col: 101}
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `components/selected_security.templ`, Line: 22, Col: 101}
 		}
 		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var4))
 		if templ_7745c5c3_Err != nil {
@@ -88,7 +91,7 @@ func SelectedSecurity(selectedSecurity models.SelectedSecurityView, csrf string)
 		var templ_7745c5c3_Var5 string
 		templ_7745c5c3_Var5, templ_7745c5c3_Err = templ.JoinStringErrs(selectedSecurity.Currency)
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `components/selected_security.templ`, Line: 20, Col: 95}
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `components/selected_security.templ`, Line: 23, Col: 95}
 		}
 		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var5))
 		if templ_7745c5c3_Err != nil {
@@ -101,7 +104,7 @@ func SelectedSecurity(selectedSecurity models.SelectedSecurityView, csrf string)
 		var templ_7745c5c3_Var6 string
 		templ_7745c5c3_Var6, templ_7745c5c3_Err = templ.JoinStringErrs(selectedSecurity.Fullname)
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `components/selected_security.templ`, Line: 22, Col: 101}
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `components/selected_security.templ`, Line: 25, Col: 101}
 		}
 		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var6))
 		if templ_7745c5c3_Err != nil {
@@ -114,7 +117,7 @@ func SelectedSecurity(selectedSecurity models.SelectedSecurityView, csrf string)
 		var templ_7745c5c3_Var7 string
 		templ_7745c5c3_Var7, templ_7745c5c3_Err = templ.JoinStringErrs(selectedSecurity.Price)
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `components/selected_security.templ`, Line: 25, Col: 77}
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `components/selected_security.templ`, Line: 28, Col: 77}
 		}
 		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var7))
 		if templ_7745c5c3_Err != nil {
@@ -127,7 +130,7 @@ func SelectedSecurity(selectedSecurity models.SelectedSecurityView, csrf string)
 		var templ_7745c5c3_Var8 string
 		templ_7745c5c3_Var8, templ_7745c5c3_Err = templ.JoinStringErrs(selectedSecurity.Target)
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `components/selected_security.templ`, Line: 27, Col: 67}
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `components/selected_security.templ`, Line: 30, Col: 67}
 		}
 		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var8))
 		if templ_7745c5c3_Err != nil {
@@ -150,7 +153,7 @@ func SelectedSecurity(selectedSecurity models.SelectedSecurityView, csrf string)
 				var templ_7745c5c3_Var9 string
 				templ_7745c5c3_Var9, templ_7745c5c3_Err = templ.JoinStringErrs(selectedSecurity.Yield)
 				if templ_7745c5c3_Err != nil {
-					return templ.Error{Err: templ_7745c5c3_Err, FileName: `components/selected_security.templ`, Line: 41, Col: 76}
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `components/selected_security.templ`, Line: 44, Col: 76}
 				}
 				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var9))
 				if templ_7745c5c3_Err != nil {
@@ -163,7 +166,7 @@ func SelectedSecurity(selectedSecurity models.SelectedSecurityView, csrf string)
 				var templ_7745c5c3_Var10 string
 				templ_7745c5c3_Var10, templ_7745c5c3_Err = templ.JoinStringErrs(selectedSecurity.Frequency)
 				if templ_7745c5c3_Err != nil {
-					return templ.Error{Err: templ_7745c5c3_Err, FileName: `components/selected_security.templ`, Line: 45, Col: 99}
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `components/selected_security.templ`, Line: 48, Col: 99}
 				}
 				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var10))
 				if templ_7745c5c3_Err != nil {
@@ -182,7 +185,7 @@ func SelectedSecurity(selectedSecurity models.SelectedSecurityView, csrf string)
 				var templ_7745c5c3_Var11 string
 				templ_7745c5c3_Var11, templ_7745c5c3_Err = templ.JoinStringErrs(selectedSecurity.Family)
 				if templ_7745c5c3_Err != nil {
-					return templ.Error{Err: templ_7745c5c3_Err, FileName: `components/selected_security.templ`, Line: 57, Col: 77}
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `components/selected_security.templ`, Line: 60, Col: 77}
 				}
 				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var11))
 				if templ_7745c5c3_Err != nil {
@@ -195,7 +198,7 @@ func SelectedSecurity(selectedSecurity models.SelectedSecurityView, csrf string)
 				var templ_7745c5c3_Var12 string
 				templ_7745c5c3_Var12, templ_7745c5c3_Err = templ.JoinStringErrs(selectedSecurity.ExpenseRatio)
 				if templ_7745c5c3_Err != nil {
-					return templ.Error{Err: templ_7745c5c3_Err, FileName: `components/selected_security.templ`, Line: 61, Col: 83}
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `components/selected_security.templ`, Line: 64, Col: 83}
 				}
 				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var12))
 				if templ_7745c5c3_Err != nil {
@@ -211,14 +214,14 @@ func SelectedSecurity(selectedSecurity models.SelectedSecurityView, csrf string)
 				return templ_7745c5c3_Err
 			}
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 18, "</div><!-- Calculator Form --><div class=\"bg-bg-std rounded-lg shadow-md p-6 border-l-4 border-l-accent\" x-data=\"{ contributionFrequency: &#39;monthly&#39; }\"><h2 class=\"text-lg font-semibold text-text-primary mb-4\">Compound Calculator</h2><input type=\"hidden\" name=\"_csrf\" id=\"_csrf\" value=\"")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 18, "</div><!-- Calculator Form --><div class=\"bg-bg-std rounded-lg shadow-md p-6 border-l-4 border-l-accent\" x-data=\"{ contributionFrequency: &#39;monthly&#39;, simulate: false, account: &#39;&#39; }\"><h2 class=\"text-lg font-semibold text-text-primary mb-4\">Compound Calculator</h2><input type=\"hidden\" name=\"_csrf\" id=\"_csrf\" value=\"")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
 		var templ_7745c5c3_Var13 string
 		templ_7745c5c3_Var13, templ_7745c5c3_Err = templ.JoinStringErrs(csrf)
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `components/selected_security.templ`, Line: 73, Col: 59}
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `components/selected_security.templ`, Line: 76, Col: 59}
 		}
 		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var13))
 		if templ_7745c5c3_Err != nil {
@@ -231,7 +234,7 @@ func SelectedSecurity(selectedSecurity models.SelectedSecurityView, csrf string)
 		var templ_7745c5c3_Var14 string
 		templ_7745c5c3_Var14, templ_7745c5c3_Err = templ.JoinStringErrs(selectedSecurity.Ticker + ":" + selectedSecurity.Exchange)
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `components/selected_security.templ`, Line: 75, Col: 108}
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `components/selected_security.templ`, Line: 78, Col: 108}
 		}
 		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var14))
 		if templ_7745c5c3_Err != nil {
@@ -244,7 +247,7 @@ func SelectedSecurity(selectedSecurity models.SelectedSecurityView, csrf string)
 		var templ_7745c5c3_Var15 string
 		templ_7745c5c3_Var15, templ_7745c5c3_Err = templ.JoinStringErrs(selectedSecurity.ProjectedPriceIncrease)
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `components/selected_security.templ`, Line: 133, Col: 51}
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `components/selected_security.templ`, Line: 136, Col: 51}
 		}
 		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var15))
 		if templ_7745c5c3_Err != nil {
@@ -262,7 +265,7 @@ func SelectedSecurity(selectedSecurity models.SelectedSecurityView, csrf string)
 			var templ_7745c5c3_Var16 string
 			templ_7745c5c3_Var16, templ_7745c5c3_Err = templ.JoinStringErrs(selectedSecurity.ProjectedYieldIncrease)
 			if templ_7745c5c3_Err != nil {
-				return templ.Error{Err: templ_7745c5c3_Err, FileName: `components/selected_security.templ`, Line: 147, Col: 52}
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `components/selected_security.templ`, Line: 150, Col: 52}
 			}
 			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var16))
 			if templ_7745c5c3_Err != nil {
@@ -273,7 +276,27 @@ func SelectedSecurity(selectedSecurity models.SelectedSecurityView, csrf string)
 				return templ_7745c5c3_Err
 			}
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 24, "<!-- Compounding Years --><div><label for=\"years\" class=\"block text-sm font-medium text-text-primary mb-1\">Compounding Years</label> <input type=\"number\" id=\"years\" name=\"years\" class=\"block w-full p-2 border border-std rounded-md focus:ring-accent focus:border-accent\" value=\"10\" min=\"1\" max=\"50\"></div><!-- Submit Button --><div class=\"relative mt-10\"><button type=\"submit\" class=\"w-full bg-accent text-white py-2 px-4 rounded-md hover:bg-accent/90 focus:outline-none focus:ring-2 focus:ring-accent/50 focus:ring-offset-2 transition-colors\">Calculate Results</button><div id=\"calculate-indicator\" class=\"htmx-indicator absolute inset-0 flex items-center justify-center bg-accent bg-opacity-75 rounded-md pointer-events-none\">")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 24, "<!-- Compounding Years --><div><label for=\"years\" class=\"block text-sm font-medium text-text-primary mb-1\">Compounding Years</label> <input type=\"number\" id=\"years\" name=\"years\" class=\"block w-full p-2 border border-std rounded-md focus:ring-accent focus:border-accent\" value=\"10\" min=\"1\" max=\"50\"></div><!-- Tax Profile --><div><label for=\"account\" class=\"block text-sm font-medium text-text-primary mb-1\">Account Type</label> <select id=\"account\" name=\"account\" class=\"block w-full p-2 border border-std rounded-md focus:ring-accent focus:border-accent\" x-model=\"account\"><option value=\"\">Pre-Tax</option> <option value=\"taxable\">Taxable</option> <option value=\"tfsa\">TFSA</option> <option value=\"rrsp\">RRSP</option> <option value=\"401k\">401(k)</option> <option value=\"isa\">ISA</option></select></div><div x-show=\"account !== &#39;&#39;\" class=\"space-y-4\"><div><label for=\"residence\" class=\"block text-sm font-medium text-text-primary mb-1\">Country of Residence</label> <select id=\"residence\" name=\"residence\" class=\"block w-full p-2 border border-std rounded-md focus:ring-accent focus:border-accent\"><option value=\"CA\">Canada</option> <option value=\"US\">United States</option> <option value=\"GB\">United Kingdom</option> <option value=\"DE\">Germany</option> <option value=\"FR\">France</option> <option value=\"IT\">Italy</option> <option value=\"ES\">Spain</option> <option value=\"NL\">Netherlands</option> <option value=\"IE\">Ireland</option> <option value=\"CH\">Switzerland</option> <option value=\"AU\">Australia</option> <option value=\"JP\">Japan</option></select></div><div x-show=\"account === &#39;rrsp&#39; || account === &#39;401k&#39;\"><label for=\"incometax\" class=\"block text-sm font-medium text-text-primary mb-1\">Marginal Income Tax Rate at Withdrawal (%)</label> <input type=\"number\" id=\"incometax\" name=\"incometax\" class=\"block w-full p-2 border border-std rounded-md focus:ring-accent focus:border-accent\" value=\"30\" min=\"0\" max=\"100\" step=\"0.01\"></div><div x-show=\"account === &#39;taxable&#39;\" class=\"space-y-4\"><div><label for=\"dividendtax\" class=\"block text-sm font-medium text-text-primary mb-1\">Marginal Dividend Tax Rate (%)</label> <input type=\"number\" id=\"dividendtax\" name=\"dividendtax\" class=\"block w-full p-2 border border-std rounded-md focus:ring-accent focus:border-accent\" value=\"25\" min=\"0\" max=\"100\" step=\"0.01\"></div><div><label for=\"capitalgainstax\" class=\"block text-sm font-medium text-text-primary mb-1\">Marginal Capital Gains Tax Rate (%)</label> <input type=\"number\" id=\"capitalgainstax\" name=\"capitalgainstax\" class=\"block w-full p-2 border border-std rounded-md focus:ring-accent focus:border-accent\" value=\"15\" min=\"0\" max=\"100\" step=\"0.01\"></div></div></div>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = InflationRate(fmt.Sprintf("%.2f (%s default)", helpers.InflationFor(selectedSecurity.Currency), selectedSecurity.Currency)).Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = WithdrawalPlan().Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = GoalSeek().Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = Backtest().Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 25, "<!-- Monte Carlo Simulation --><div><label for=\"simulate\" class=\"inline-flex items-center text-sm font-medium text-text-primary\"><input type=\"checkbox\" id=\"simulate\" name=\"simulate\" value=\"true\" class=\"mr-2 border border-std rounded focus:ring-accent\" x-model=\"simulate\"> Simulate Price Volatility (Monte Carlo)</label></div><div x-show=\"simulate\" class=\"space-y-4\"><div><label for=\"paths\" class=\"block text-sm font-medium text-text-primary mb-1\">Simulated Paths</label> <input type=\"number\" id=\"paths\" name=\"paths\" class=\"block w-full p-2 border border-std rounded-md focus:ring-accent focus:border-accent\" value=\"1000\" min=\"100\" max=\"10000\"></div><div><label for=\"target\" class=\"block text-sm font-medium text-text-primary mb-1\">Target Balance</label><div class=\"relative\"><div class=\"absolute inset-y-0 left-0 flex items-center pl-3 pointer-events-none\"><span class=\"text-text-secondary\">$</span></div><input type=\"number\" id=\"target\" name=\"target\" class=\"block w-full pl-8 p-2 border border-std rounded-md focus:ring-accent focus:border-accent\" value=\"0\" min=\"0\"></div></div></div><!-- Submit Button --><div class=\"relative mt-10\"><button type=\"submit\" class=\"w-full bg-accent text-white py-2 px-4 rounded-md hover:bg-accent/90 focus:outline-none focus:ring-2 focus:ring-accent/50 focus:ring-offset-2 transition-colors\">Calculate Results</button><div id=\"calculate-indicator\" class=\"htmx-indicator absolute inset-0 flex items-center justify-center bg-accent bg-opacity-75 rounded-md pointer-events-none\">")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -281,7 +304,7 @@ func SelectedSecurity(selectedSecurity models.SelectedSecurityView, csrf string)
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 25, "</div></div></div>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 26, "</div></div></div>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -0,0 +1,40 @@
+// Code generated by templ - DO NOT EDIT.
+
+// templ: version: v0.3.857
+package components
+
+//lint:file-ignore SA4006 This context is only used if a nested component is present.
+
+import "github.com/a-h/templ"
+import templruntime "github.com/a-h/templ/runtime"
+
+func WithdrawalPlan() templ.Component {
+	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
+			return templ_7745c5c3_CtxErr
+		}
+		templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+		if !templ_7745c5c3_IsBuffer {
+			defer func() {
+				templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err == nil {
+					templ_7745c5c3_Err = templ_7745c5c3_BufErr
+				}
+			}()
+		}
+		ctx = templ.InitializeContext(ctx)
+		templ_7745c5c3_Var1 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var1 == nil {
+			templ_7745c5c3_Var1 = templ.NopComponent
+		}
+		ctx = templ.ClearChildren(ctx)
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 1, "<!-- Withdrawal Phase --><div x-data=\"{ withdraw: &#39;&#39; }\" class=\"space-y-4\"><div><label for=\"withdraw\" class=\"block text-sm font-medium text-text-primary mb-1\">Withdrawal Phase</label> <select id=\"withdraw\" name=\"withdraw\" class=\"block w-full p-2 border border-std rounded-md focus:ring-accent focus:border-accent\" x-model=\"withdraw\"><option value=\"\">None</option> <option value=\"fixed\">Fixed Monthly Amount</option> <option value=\"percent\">Percentage of Balance</option> <option value=\"dividends\">Dividends Only</option></select></div><div x-show=\"withdraw === &#39;fixed&#39;\"><label for=\"withdrawalamount\" class=\"block text-sm font-medium text-text-primary mb-1\">Monthly Withdrawal (Today's Money)</label><div class=\"relative\"><div class=\"absolute inset-y-0 left-0 flex items-center pl-3 pointer-events-none\"><span class=\"text-text-secondary\">$</span></div><input type=\"number\" id=\"withdrawalamount\" name=\"withdrawalamount\" class=\"block w-full pl-8 p-2 border border-std rounded-md focus:ring-accent focus:border-accent\" value=\"3000\" min=\"0\"></div></div><div x-show=\"withdraw === &#39;percent&#39;\"><label for=\"withdrawalrate\" class=\"block text-sm font-medium text-text-primary mb-1\">Yearly Withdrawal Rate (%)</label> <input type=\"number\" id=\"withdrawalrate\" name=\"withdrawalrate\" class=\"block w-full p-2 border border-std rounded-md focus:ring-accent focus:border-accent\" value=\"4\" min=\"0\" max=\"100\" step=\"0.01\"></div><div x-show=\"withdraw !== &#39;&#39;\"><label for=\"withdrawalyears\" class=\"block text-sm font-medium text-text-primary mb-1\">Withdrawal Years</label> <input type=\"number\" id=\"withdrawalyears\" name=\"withdrawalyears\" class=\"block w-full p-2 border border-std rounded-md focus:ring-accent focus:border-accent\" value=\"30\" min=\"1\" max=\"60\"></div></div>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		return nil
+	})
+}
+
+var _ = templruntime.GeneratedTemplate
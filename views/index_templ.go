@@ -77,7 +77,23 @@ func Index(site models.Site, csrf, nonce string) templ.Component {
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 4, "</div><!-- Initial state - No security selected --><div class=\"bg-bg-std rounded-lg shadow-md p-6 border-l-4 border-l-primary\"><h2 class=\"text-lg font-semibold text-text-primary mb-4\">No Security Selected</h2><p class=\"text-text-secondary mb-4\">Search and select a security above or enter a custom interest rate below.</p><div class=\"mb-4\"><label for=\"rate\" class=\"block text-sm font-medium text-text-primary mb-1\">HISA Interest Rate (%)</label> <input type=\"number\" id=\"rate\" name=\"rate\" class=\"block w-full p-2 border border-std rounded-md focus:ring-accent focus:border-accent\" value=\"1.5\" min=\"0\" step=\"0.1\"></div><!-- Compounding Frequency --><div><label for=\"compoundingfrequency\" class=\"block text-sm font-medium text-text-primary mb-1\">Compounding Frequency</label> <select id=\"compoundingfrequency\" name=\"compoundingfrequency\" class=\"block w-full p-2 border border-std rounded-md focus:ring-accent focus:border-accent\"><option value=\"daily\">Daily</option> <option value=\"weekly\">Weekly</option> <option value=\"monthly\" selected>Monthly</option> <option value=\"quarterly\">Quarterly</option> <option value=\"semi-annually\">Semi-Annually</option> <option value=\"annually\">Annually</option></select></div><!-- Currency Selection --><div class=\"mb-4\"><label for=\"currency\" class=\"block text-sm font-medium text-text-primary mb-1\">Currency</label> <select id=\"currency\" name=\"currency\" class=\"block w-full p-2 border border-std rounded-md focus:ring-accent focus:border-accent\"><option value=\"CAD\" selected>CAD</option> <option value=\"EUR\">EUR</option> <option value=\"USD\">USD</option></select></div></div><!-- Calculator Form --><div class=\"bg-bg-std rounded-lg shadow-md p-6 border-l-4 border-l-accent\" x-data=\"{ contributionFrequency: &#39;monthly&#39; }\"><h2 class=\"text-lg font-semibold text-text-primary mb-4\">Compound Calculator</h2><input type=\"hidden\" name=\"sid\" id=\"sid\" value=\"default\"><!-- Principal Amount --><div><label for=\"principal\" class=\"block text-sm font-medium text-text-primary mb-1\">Initial Investment</label><div class=\"relative\"><div class=\"absolute inset-y-0 left-0 flex items-center pl-3 pointer-events-none\"><span class=\"text-text-secondary\">$</span></div><input type=\"number\" id=\"principal\" name=\"principal\" class=\"block w-full pl-8 p-2 border border-std rounded-md focus:ring-accent focus:border-accent\" value=\"10000\" min=\"0\"></div></div><!-- Contribution Frequency --><div><label for=\"contribfrequency\" class=\"block text-sm font-medium text-text-primary mb-1\">Contribution Frequency</label> <select id=\"contribfrequency\" name=\"contribfrequency\" class=\"block w-full p-2 border border-std rounded-md focus:ring-accent focus:border-accent\" x-model=\"contributionFrequency\"><option value=\"weekly\">Weekly</option> <option value=\"monthly\">Monthly</option> <option value=\"quarterly\">Quarterly</option> <option value=\"semi-annually\">Semi-Annually</option> <option value=\"annually\">Annually</option></select></div><!-- Contribution Amount --><div><label for=\"contribution\" class=\"block text-sm font-medium text-text-primary mb-1\"><span x-text=\"contributionFrequency.charAt(0).toUpperCase() + contributionFrequency.slice(1)\">Monthly</span> Contribution</label><div class=\"relative\"><div class=\"absolute inset-y-0 left-0 flex items-center pl-3 pointer-events-none\"><span class=\"text-text-secondary\">$</span></div><input type=\"number\" id=\"contribution\" name=\"contribution\" class=\"block w-full pl-8 p-2 border border-std rounded-md focus:ring-accent focus:border-accent\" value=\"1000\" min=\"0\"></div></div><!-- Compounding Years --><div><label for=\"years\" class=\"block text-sm font-medium text-text-primary mb-1\">Compounding Years</label> <input type=\"number\" id=\"years\" name=\"years\" class=\"block w-full p-2 border border-std rounded-md focus:ring-accent focus:border-accent\" value=\"10\" min=\"1\" max=\"50\"></div><!-- Submit Button --><div class=\"relative mt-10\"><button type=\"submit\" class=\"w-full bg-accent text-white py-2 px-4 rounded-md hover:bg-accent/90 focus:outline-none focus:ring-2 focus:ring-accent/50 focus:ring-offset-2 transition-colors\">Calculate Results</button><div id=\"calculate-indicator\" class=\"htmx-indicator absolute inset-0 flex items-center justify-center bg-accent bg-opacity-75 rounded-md pointer-events-none\">")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 4, "</div><!-- Initial state - No security selected --><div class=\"bg-bg-std rounded-lg shadow-md p-6 border-l-4 border-l-primary\"><h2 class=\"text-lg font-semibold text-text-primary mb-4\">No Security Selected</h2><p class=\"text-text-secondary mb-4\">Search and select a security above or enter a custom interest rate below.</p><div class=\"mb-4\"><label for=\"rate\" class=\"block text-sm font-medium text-text-primary mb-1\">HISA Interest Rate (%)</label> <input type=\"number\" id=\"rate\" name=\"rate\" class=\"block w-full p-2 border border-std rounded-md focus:ring-accent focus:border-accent\" value=\"1.5\" min=\"0\" step=\"0.1\"></div><!-- Compounding Frequency --><div><label for=\"compoundingfrequency\" class=\"block text-sm font-medium text-text-primary mb-1\">Compounding Frequency</label> <select id=\"compoundingfrequency\" name=\"compoundingfrequency\" class=\"block w-full p-2 border border-std rounded-md focus:ring-accent focus:border-accent\"><option value=\"daily\">Daily</option> <option value=\"weekly\">Weekly</option> <option value=\"monthly\" selected>Monthly</option> <option value=\"quarterly\">Quarterly</option> <option value=\"semi-annually\">Semi-Annually</option> <option value=\"annually\">Annually</option></select></div><!-- Currency Selection --><div class=\"mb-4\"><label for=\"currency\" class=\"block text-sm font-medium text-text-primary mb-1\">Currency</label> <select id=\"currency\" name=\"currency\" class=\"block w-full p-2 border border-std rounded-md focus:ring-accent focus:border-accent\"><option value=\"CAD\" selected>CAD</option> <option value=\"EUR\">EUR</option> <option value=\"USD\">USD</option></select></div></div><!-- Calculator Form --><div class=\"bg-bg-std rounded-lg shadow-md p-6 border-l-4 border-l-accent\" x-data=\"{ contributionFrequency: &#39;monthly&#39; }\"><h2 class=\"text-lg font-semibold text-text-primary mb-4\">Compound Calculator</h2><input type=\"hidden\" name=\"sid\" id=\"sid\" value=\"default\"><!-- Principal Amount --><div><label for=\"principal\" class=\"block text-sm font-medium text-text-primary mb-1\">Initial Investment</label><div class=\"relative\"><div class=\"absolute inset-y-0 left-0 flex items-center pl-3 pointer-events-none\"><span class=\"text-text-secondary\">$</span></div><input type=\"number\" id=\"principal\" name=\"principal\" class=\"block w-full pl-8 p-2 border border-std rounded-md focus:ring-accent focus:border-accent\" value=\"10000\" min=\"0\"></div></div><!-- Contribution Frequency --><div><label for=\"contribfrequency\" class=\"block text-sm font-medium text-text-primary mb-1\">Contribution Frequency</label> <select id=\"contribfrequency\" name=\"contribfrequency\" class=\"block w-full p-2 border border-std rounded-md focus:ring-accent focus:border-accent\" x-model=\"contributionFrequency\"><option value=\"weekly\">Weekly</option> <option value=\"monthly\">Monthly</option> <option value=\"quarterly\">Quarterly</option> <option value=\"semi-annually\">Semi-Annually</option> <option value=\"annually\">Annually</option></select></div><!-- Contribution Amount --><div><label for=\"contribution\" class=\"block text-sm font-medium text-text-primary mb-1\"><span x-text=\"contributionFrequency.charAt(0).toUpperCase() + contributionFrequency.slice(1)\">Monthly</span> Contribution</label><div class=\"relative\"><div class=\"absolute inset-y-0 left-0 flex items-center pl-3 pointer-events-none\"><span class=\"text-text-secondary\">$</span></div><input type=\"number\" id=\"contribution\" name=\"contribution\" class=\"block w-full pl-8 p-2 border border-std rounded-md focus:ring-accent focus:border-accent\" value=\"1000\" min=\"0\"></div></div><!-- Compounding Years --><div><label for=\"years\" class=\"block text-sm font-medium text-text-primary mb-1\">Compounding Years</label> <input type=\"number\" id=\"years\" name=\"years\" class=\"block w-full p-2 border border-std rounded-md focus:ring-accent focus:border-accent\" value=\"10\" min=\"1\" max=\"50\"></div>")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = components.InflationRate("Currency default").Render(ctx, templ_7745c5c3_Buffer)
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = components.WithdrawalPlan().Render(ctx, templ_7745c5c3_Buffer)
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = components.GoalSeek().Render(ctx, templ_7745c5c3_Buffer)
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 5, "<!-- Submit Button --><div class=\"relative mt-10\"><button type=\"submit\" class=\"w-full bg-accent text-white py-2 px-4 rounded-md hover:bg-accent/90 focus:outline-none focus:ring-2 focus:ring-accent/50 focus:ring-offset-2 transition-colors\">Calculate Results</button><div id=\"calculate-indicator\" class=\"htmx-indicator absolute inset-0 flex items-center justify-center bg-accent bg-opacity-75 rounded-md pointer-events-none\">")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
@@ -85,7 +101,7 @@ func Index(site models.Site, csrf, nonce string) templ.Component {
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 5, "</div></div></div></form><!-- Calculation Results (will be populated by server) --><div id=\"calculation-results\" class=\"mt-8\"><!-- Results will be inserted here by the server --></div></main>")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 6, "</div></div></div></form><!-- Calculation Results (will be populated by server) --><div id=\"calculation-results\" class=\"mt-8\"><!-- Results will be inserted here by the server --></div></main>")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
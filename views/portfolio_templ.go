@@ -0,0 +1,107 @@
+// Code generated by templ - DO NOT EDIT.
+
+// templ: version: v0.3.857
+package views
+
+//lint:file-ignore SA4006 This context is only used if a nested component is present.
+
+import "github.com/a-h/templ"
+import templruntime "github.com/a-h/templ/runtime"
+
+import (
+	"github.com/Francesco99975/finexo/internal/models"
+	"github.com/Francesco99975/finexo/views/components"
+	"github.com/Francesco99975/finexo/views/icons"
+	"github.com/Francesco99975/finexo/views/layouts"
+)
+
+func Portfolio(site models.Site, csrf, nonce string) templ.Component {
+	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
+			return templ_7745c5c3_CtxErr
+		}
+		templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+		if !templ_7745c5c3_IsBuffer {
+			defer func() {
+				templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err == nil {
+					templ_7745c5c3_Err = templ_7745c5c3_BufErr
+				}
+			}()
+		}
+		ctx = templ.InitializeContext(ctx)
+		templ_7745c5c3_Var1 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var1 == nil {
+			templ_7745c5c3_Var1 = templ.NopComponent
+		}
+		ctx = templ.ClearChildren(ctx)
+		templ_7745c5c3_Var2 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+			templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+			templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+			if !templ_7745c5c3_IsBuffer {
+				defer func() {
+					templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+					if templ_7745c5c3_Err == nil {
+						templ_7745c5c3_Err = templ_7745c5c3_BufErr
+					}
+				}()
+			}
+			ctx = templ.InitializeContext(ctx)
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 1, "<main class=\"flex-grow container mx-auto px-4 py-8 max-w-4xl transition-colors\"><h1 class=\"text-3xl font-bold text-text-primary mb-6 text-center\">Portfolio Calculator</h1><form id=\"portfolio-form\" class=\"space-y-4 mb-8 relative\" hx-post=\"/calculate/portfolio\" hx-target=\"#calculation-results\" hx-indicator=\"#calculate-indicator\"><input type=\"hidden\" name=\"_csrf\" id=\"_csrf\" value=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var3 string
+			templ_7745c5c3_Var3, templ_7745c5c3_Err = templ.JoinStringErrs(csrf)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `portfolio.templ`, Line: 21, Col: 61}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var3))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, "\"><!-- Holdings --><div class=\"bg-bg-std rounded-lg shadow-md p-6 border-l-4 border-l-primary\"><h2 class=\"text-lg font-semibold text-text-primary mb-4\">Holdings</h2><p class=\"text-text-secondary mb-4\">One holding per line as ticker:exchange followed by its target weight, weights are scaled to add up to 100%.</p><textarea id=\"holdings\" name=\"holdings\" rows=\"5\" class=\"block w-full p-2 border border-std rounded-md font-mono focus:ring-accent focus:border-accent\" placeholder=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var4 string
+			templ_7745c5c3_Var4, templ_7745c5c3_Err = templ.JoinStringErrs("VFV:TSX 60\nXEI:TSX 40")
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `portfolio.templ`, Line: 31, Col: 44}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var4))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 3, "\"></textarea></div><!-- Calculator Form --><div class=\"bg-bg-std rounded-lg shadow-md p-6 border-l-4 border-l-accent\" x-data=\"{ contributionFrequency: &#39;monthly&#39;, account: &#39;&#39; }\"><h2 class=\"text-lg font-semibold text-text-primary mb-4\">Compound Calculator</h2><!-- Principal Amount --><div><label for=\"principal\" class=\"block text-sm font-medium text-text-primary mb-1\">Initial Investment</label><div class=\"relative\"><div class=\"absolute inset-y-0 left-0 flex items-center pl-3 pointer-events-none\"><span class=\"text-text-secondary\">$</span></div><input type=\"number\" id=\"principal\" name=\"principal\" class=\"block w-full pl-8 p-2 border border-std rounded-md focus:ring-accent focus:border-accent\" value=\"10000\" min=\"0\"></div></div><!-- Contribution Frequency --><div><label for=\"contribfrequency\" class=\"block text-sm font-medium text-text-primary mb-1\">Contribution Frequency</label> <select id=\"contribfrequency\" name=\"contribfrequency\" class=\"block w-full p-2 border border-std rounded-md focus:ring-accent focus:border-accent\" x-model=\"contributionFrequency\"><option value=\"monthly\">Monthly</option> <option value=\"quarterly\">Quarterly</option></select></div><!-- Contribution Amount --><div><label for=\"contribution\" class=\"block text-sm font-medium text-text-primary mb-1\"><span x-text=\"contributionFrequency.charAt(0).toUpperCase() + contributionFrequency.slice(1)\">Monthly</span> Contribution</label><div class=\"relative\"><div class=\"absolute inset-y-0 left-0 flex items-center pl-3 pointer-events-none\"><span class=\"text-text-secondary\">$</span></div><input type=\"number\" id=\"contribution\" name=\"contribution\" class=\"block w-full pl-8 p-2 border border-std rounded-md focus:ring-accent focus:border-accent\" value=\"1000\" min=\"0\"></div></div><!-- Expected Price Increase  --><div><label for=\"pricemod\" class=\"block text-sm font-medium text-text-primary mb-1\">Expected Annual Price Increase (%)</label> <input type=\"number\" id=\"pricemod\" name=\"pricemod\" class=\"block w-full p-2 border border-std rounded-md focus:ring-accent focus:border-accent\" value=\"5\" min=\"0\" step=\"0.01\"></div><!-- Expected Yield Increase  --><div><label for=\"yieldmod\" class=\"block text-sm font-medium text-text-primary mb-1\">Expected Annual Yield Increase (%)</label> <input type=\"number\" id=\"yieldmod\" name=\"yieldmod\" class=\"block w-full p-2 border border-std rounded-md focus:ring-accent focus:border-accent\" value=\"2\" min=\"0\" step=\"0.01\"></div><!-- Compounding Years --><div><label for=\"years\" class=\"block text-sm font-medium text-text-primary mb-1\">Compounding Years</label> <input type=\"number\" id=\"years\" name=\"years\" class=\"block w-full p-2 border border-std rounded-md focus:ring-accent focus:border-accent\" value=\"10\" min=\"1\" max=\"50\"></div>")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = components.InflationRate("Currency default").Render(ctx, templ_7745c5c3_Buffer)
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 4, "<!-- Rebalancing --><div><label for=\"rebalance\" class=\"block text-sm font-medium text-text-primary mb-1\">Rebalancing</label> <select id=\"rebalance\" name=\"rebalance\" class=\"block w-full p-2 border border-std rounded-md focus:ring-accent focus:border-accent\"><option value=\"none\">Never</option> <option value=\"monthly\">Monthly</option> <option value=\"quarterly\">Quarterly</option> <option value=\"semi-annual\">Semi-Annual</option> <option value=\"annual\" selected>Annual</option></select></div><!-- Tax Profile --><div><label for=\"account\" class=\"block text-sm font-medium text-text-primary mb-1\">Account Type</label> <select id=\"account\" name=\"account\" class=\"block w-full p-2 border border-std rounded-md focus:ring-accent focus:border-accent\" x-model=\"account\"><option value=\"\">Pre-Tax</option> <option value=\"taxable\">Taxable</option> <option value=\"tfsa\">TFSA</option> <option value=\"rrsp\">RRSP</option> <option value=\"401k\">401(k)</option> <option value=\"isa\">ISA</option></select></div><div x-show=\"account !== &#39;&#39;\" class=\"space-y-4\"><div><label for=\"residence\" class=\"block text-sm font-medium text-text-primary mb-1\">Country of Residence</label> <select id=\"residence\" name=\"residence\" class=\"block w-full p-2 border border-std rounded-md focus:ring-accent focus:border-accent\"><option value=\"CA\">Canada</option> <option value=\"US\">United States</option> <option value=\"GB\">United Kingdom</option> <option value=\"DE\">Germany</option> <option value=\"FR\">France</option> <option value=\"IT\">Italy</option> <option value=\"ES\">Spain</option> <option value=\"NL\">Netherlands</option> <option value=\"IE\">Ireland</option> <option value=\"CH\">Switzerland</option> <option value=\"AU\">Australia</option> <option value=\"JP\">Japan</option></select></div><div x-show=\"account === &#39;rrsp&#39; || account === &#39;401k&#39;\"><label for=\"incometax\" class=\"block text-sm font-medium text-text-primary mb-1\">Marginal Income Tax Rate at Withdrawal (%)</label> <input type=\"number\" id=\"incometax\" name=\"incometax\" class=\"block w-full p-2 border border-std rounded-md focus:ring-accent focus:border-accent\" value=\"30\" min=\"0\" max=\"100\" step=\"0.01\"></div><div x-show=\"account === &#39;taxable&#39;\" class=\"space-y-4\"><div><label for=\"dividendtax\" class=\"block text-sm font-medium text-text-primary mb-1\">Marginal Dividend Tax Rate (%)</label> <input type=\"number\" id=\"dividendtax\" name=\"dividendtax\" class=\"block w-full p-2 border border-std rounded-md focus:ring-accent focus:border-accent\" value=\"25\" min=\"0\" max=\"100\" step=\"0.01\"></div><div><label for=\"capitalgainstax\" class=\"block text-sm font-medium text-text-primary mb-1\">Marginal Capital Gains Tax Rate (%)</label> <input type=\"number\" id=\"capitalgainstax\" name=\"capitalgainstax\" class=\"block w-full p-2 border border-std rounded-md focus:ring-accent focus:border-accent\" value=\"15\" min=\"0\" max=\"100\" step=\"0.01\"></div></div></div><!-- Submit Button --><div class=\"relative mt-10\"><button type=\"submit\" class=\"w-full bg-accent text-white py-2 px-4 rounded-md hover:bg-accent/90 focus:outline-none focus:ring-2 focus:ring-accent/50 focus:ring-offset-2 transition-colors\">Calculate Results</button><div id=\"calculate-indicator\" class=\"htmx-indicator absolute inset-0 flex items-center justify-center bg-accent bg-opacity-75 rounded-md pointer-events-none\">")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = icons.CalculateLoading().Render(ctx, templ_7745c5c3_Buffer)
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 5, "</div></div></div></form><!-- Calculation Results (will be populated by server) --><div id=\"calculation-results\" class=\"mt-8\"><!-- Results will be inserted here by the server --></div></main>")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			return nil
+		})
+		templ_7745c5c3_Err = layouts.CoreHTML(site, nonce, nil, nil, nil).Render(templ.WithChildren(ctx, templ_7745c5c3_Var2), templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		return nil
+	})
+}
+
+var _ = templruntime.GeneratedTemplate